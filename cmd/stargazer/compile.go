@@ -0,0 +1,149 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/nimbess/stargazer/pkg/config"
+	"github.com/nimbess/stargazer/pkg/controller/handlers/unp"
+	unpv1 "github.com/nimbess/stargazer/pkg/crd/api/unp/v1"
+	"github.com/nimbess/stargazer/pkg/model"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"os"
+
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// compiled is a manifest translated into a Nimbess key, as printed by the compile command.
+type compiled struct {
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// runCompile validates and translates the UNP manifests of the files given as arguments, printing
+// the resulting keys or the errors. Returns the exit code.
+func runCompile(args []string) int {
+	flags := flag.NewFlagSet("compile", flag.ContinueOnError)
+	namespace := flags.String("namespace", "default", "Namespace of the manifests without one")
+	output := flags.String("o", "yaml", "Output format: yaml or json")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: stargazer [flags] compile [-namespace ns] [-o yaml|json] <file>...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 || (*output != "yaml" && *output != "json") {
+		flags.Usage()
+		return 2
+	}
+
+	// Keys are printed under the configured prefix, the configuration is optional.
+	log.SetLevel(log.ErrorLevel)
+	cfg := config.NewConfig()
+	_ = cfg.Parse(cfgPath, cfgName)
+	if err := model.SetPrefix(cfg.EtcdPrefix, cfg.Tenant); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
+	var keys []compiled
+	failed := false
+	for _, file := range flags.Args() {
+		policies, err := readManifests(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			failed = true
+			continue
+		}
+		for _, p := range policies {
+			if p.Namespace == "" {
+				p.Namespace = *namespace
+			}
+			if err := unp.Validate(p); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %s/%s: %v\n", file, p.Namespace, p.Name, err)
+				failed = true
+				continue
+			}
+			kv, err := (&unp.UNP{}).K8sToNimbess(p)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %s/%s: %v\n", file, p.Namespace, p.Name, err)
+				failed = true
+				continue
+			}
+			path, err := model.KeyToDefaultPath(kv.Key)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %s/%s: %v\n", file, p.Namespace, p.Name, err)
+				failed = true
+				continue
+			}
+			keys = append(keys, compiled{Path: path, Value: kv.Value})
+		}
+	}
+
+	if failed {
+		return 1
+	}
+	var data []byte
+	var err error
+	if *output == "json" {
+		data, err = json.MarshalIndent(keys, "", "  ")
+		data = append(data, '\n')
+	} else {
+		data, err = yaml.Marshal(keys)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	os.Stdout.Write(data)
+	return 0
+}
+
+// readManifests reads the UNPs of a YAML or JSON file, which may hold several documents.
+func readManifests(file string) ([]*unpv1.UnifiedNetworkPolicy, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var policies []*unpv1.UnifiedNetworkPolicy
+	decoder := k8syaml.NewYAMLOrJSONDecoder(f, 4096)
+	for i := 0; ; i++ {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err == io.EOF {
+			return policies, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("document %d: %v", i, err)
+		}
+		if len(raw) == 0 || string(raw) == "null" {
+			continue
+		}
+		p := &unpv1.UnifiedNetworkPolicy{}
+		if err := yaml.UnmarshalStrict(raw, p); err != nil {
+			return nil, fmt.Errorf("document %d: %v", i, err)
+		}
+		if p.APIVersion != unpv1.SchemeGroupVersion.String() || p.Kind != "UnifiedNetworkPolicy" {
+			return nil, fmt.Errorf("document %d: expected %s UnifiedNetworkPolicy, got %s %s",
+				i, unpv1.SchemeGroupVersion, p.APIVersion, p.Kind)
+		}
+		policies = append(policies, p)
+	}
+}
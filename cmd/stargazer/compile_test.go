@@ -0,0 +1,169 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const (
+	webPolicy = `apiVersion: nimbess.com/v1
+kind: UnifiedNetworkPolicy
+metadata:
+  name: web
+spec:
+  podSelector:
+    matchLabels:
+      app: web
+`
+	dbPolicy = `apiVersion: nimbess.com/v1
+kind: UnifiedNetworkPolicy
+metadata:
+  name: db
+  namespace: prod
+spec:
+  l7Policies:
+    - default:
+        action: deny
+`
+	configMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+`
+	invalidPolicy = `apiVersion: nimbess.com/v1
+kind: UnifiedNetworkPolicy
+metadata:
+  name: invalid
+spec:
+  l7Policies:
+    - default:
+        action: drop
+`
+)
+
+type compiletest struct {
+	testName string
+	args     []string
+	files    map[string]string
+	code     int
+	expected []string
+}
+
+var compileTests = []compiletest{
+	// pass: documents of a file and of several files, empty documents are skipped
+	{"compile 1", []string{"policies.yaml", "db.yaml"}, map[string]string{
+		"policies.yaml": "---\n" + webPolicy + "---\n---\n" + dbPolicy,
+		"db.yaml":       dbPolicy,
+	}, 0, []string{"default/web", "prod/db"}},
+	// pass: namespace of the manifests without one
+	{"compile 2", []string{"-namespace", "test", "-o", "json", "web.yaml"}, map[string]string{
+		"web.yaml": webPolicy,
+	}, 0, []string{"test/web", `"path":`}},
+	// pass: example manifest
+	{"compile 3", []string{"../../deployments/example-unp.yaml"}, nil, 0, []string{"kube-system/testpolicy"}},
+	// fail: not a UNP
+	{"compile 4", []string{"policies.yaml"}, map[string]string{
+		"policies.yaml": webPolicy + "---\n" + configMap,
+	}, 1, []string{"document 1: expected nimbess.com/v1 UnifiedNetworkPolicy, got v1 ConfigMap"}},
+	// fail: invalid UNP
+	{"compile 5", []string{"policies.yaml"}, map[string]string{
+		"policies.yaml": webPolicy + "---\n" + invalidPolicy,
+	}, 1, []string{"default/invalid: spec.l7Policies[0].default.action"}},
+	// fail: missing file
+	{"compile 6", []string{"missing.yaml"}, nil, 1, []string{"missing.yaml"}},
+	// fail: no file
+	{"compile 7", nil, nil, 2, []string{"Usage"}},
+	// fail: unsupported output format
+	{"compile 8", []string{"-o", "xml", "web.yaml"}, map[string]string{
+		"web.yaml": webPolicy,
+	}, 2, nil},
+}
+
+func TestRunCompile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stargazer-compile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cfgPath = dir
+
+	for _, test := range compileTests {
+		var args []string
+		for _, arg := range test.args {
+			if strings.HasSuffix(arg, ".yaml") && !strings.HasPrefix(arg, "../") {
+				arg = filepath.Join(dir, arg)
+			}
+			args = append(args, arg)
+		}
+		for name, data := range test.files {
+			if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		code, output, errors := compile(t, args)
+		if code != test.code {
+			t.Errorf("%s: expected exit code %d, got %d: %s", test.testName, test.code, code, errors)
+		}
+		if code != 0 {
+			if output != "" {
+				t.Errorf("%s: expected no output, got %s", test.testName, output)
+			}
+			// the errors are expected instead
+			output = errors
+		}
+		for _, s := range test.expected {
+			if !strings.Contains(output, s) {
+				t.Errorf("%s: expected %q in the output, got %s", test.testName, s, output)
+			}
+		}
+	}
+}
+
+// compile runs the compile command, returning its exit code, standard output and standard error.
+func compile(t *testing.T, args []string) (int, string, string) {
+	dir, err := ioutil.TempDir("", "stargazer-output")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var files [2]*os.File
+	for i := range files {
+		if files[i], err = os.Create(filepath.Join(dir, strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+		defer files[i].Close()
+	}
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = files[0], files[1]
+	code := runCompile(args)
+	os.Stdout, os.Stderr = stdout, stderr
+
+	var output [2]string
+	for i, f := range files {
+		data, err := ioutil.ReadFile(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		output[i] = string(data)
+	}
+	return code, output[0], output[1]
+}
@@ -17,18 +17,25 @@ package main
 
 import (
 	"context"
+	_ "expvar"
 	"flag"
 	"fmt"
 	nimbessclientset "github.com/nimbess/stargazer/pkg/client/clientset/versioned"
 	"github.com/nimbess/stargazer/pkg/config"
 	"github.com/nimbess/stargazer/pkg/controller"
 	unpv1 "github.com/nimbess/stargazer/pkg/crd/api/unp/v1"
+	"github.com/nimbess/stargazer/pkg/datastore"
+	"github.com/nimbess/stargazer/pkg/dryrun"
 	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/model"
+	"github.com/nimbess/stargazer/pkg/signals"
 	log "github.com/sirupsen/logrus"
 	extclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
+	"net/http"
 	"os"
 )
 
@@ -38,6 +45,7 @@ var version = false
 var cfgName = "stargazer"
 var cfgPath = "."
 var verbose = false
+var dryRun = false
 
 func init() {
 	log.SetReportCaller(true)
@@ -45,6 +53,8 @@ func init() {
 	flag.StringVar(&cfgPath, "config-path", cfgPath, "Stargazer config file path")
 	flag.StringVar(&cfgName, "config-name", cfgName, "Stargazer config file name")
 	flag.BoolVar(&verbose, "verbose", verbose, "Enable debug logging")
+	flag.BoolVar(&dryRun, "dry-run", dryRun,
+		"Record the datastore changes instead of writing them, served on /dry-run of the metrics address")
 }
 
 func main() {
@@ -53,11 +63,28 @@ func main() {
 		fmt.Println(VERSION)
 		os.Exit(0)
 	}
+	switch flag.Arg(0) {
+	case "compile":
+		os.Exit(runCompile(flag.Args()[1:]))
+	case "validate-config":
+		os.Exit(runValidateConfig(flag.Args()[1:]))
+	}
 
 	cfg := getConfig()
+	if err := model.SetPrefix(cfg.EtcdPrefix, cfg.Tenant); err != nil {
+		log.WithError(err).Fatal("Failed to set etcd key prefix")
+	}
+	if err := model.SetValueEncoding(cfg.EtcdValueCodec, cfg.EtcdCompression); err != nil {
+		log.WithError(err).Fatal("Failed to set etcd value encoding")
+	}
 
-	// Get the context
-	ctx := context.Background()
+	// Get the context, cancelled on exit
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if cfg.MetricsAddress != "" {
+		go serveMetrics(cfg.MetricsAddress)
+	}
 
 	// Get the k8s client
 	k8sClient, err := getK8SClient(cfg.Kubeconfig)
@@ -65,10 +92,16 @@ func main() {
 		log.WithError(err).Fatal("Failed to get k8s client api")
 	}
 
-	// Get the etcd client.
-	etcdClient, err := getEtcdClient(cfg)
+	// Get the k8s core client
+	coreClient, err := getK8sCoreClient(cfg.Kubeconfig)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to get k8s core client api")
+	}
+
+	// Get the k8s dynamic client
+	dynClient, err := getK8sDynamicClient(cfg.Kubeconfig)
 	if err != nil {
-		log.WithError(err).Fatal("Failed to get etcd client")
+		log.WithError(err).Fatal("Failed to get k8s dynamic client api")
 	}
 
 	// Register CRDs
@@ -76,25 +109,73 @@ func main() {
 	if err != nil {
 		log.WithError(err).Fatal("Failed to get k8s extension client api")
 	}
-	if err := unpv1.CreateCRD(extClient); err != nil {
-		log.WithError(err).Fatal("failed to create UNP CRD")
+	if dryRun {
+		log.Info("Dry run, the CRDs must already be registered")
+	} else {
+		if err := unpv1.CreateCRD(extClient); err != nil {
+			log.WithError(err).Fatal("failed to create UNP CRD")
+		}
+		if err := unpv1.CreateUnpConfigCRD(extClient); err != nil {
+			log.WithError(err).Fatal("failed to create UnpConfig CRD")
+		}
+		if err := unpv1.CreateNetworkCRD(extClient); err != nil {
+			log.WithError(err).Fatal("failed to create Network CRD")
+		}
 	}
 
-	// setup the controller control structure
-	stopCh := make(chan struct{})
-	defer close(stopCh)
+	// Get the datastore client.
+	etcdClient, err := getDatastoreClient(cfg, extClient)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to get datastore client")
+	}
+	if dryRun {
+		recorder := dryrun.NewRecorder(etcdClient)
+		http.Handle("/dry-run", recorder)
+		if cfg.MetricsAddress == "" {
+			log.Warn("Dry run without MetricsAddress, the recorded changes are only logged")
+		}
+		etcdClient = recorder
+	}
+	if err := etcdClient.EnsureSchema(ctx, cfg.EtcdMigrate); err != nil {
+		log.WithError(err).Fatal("Failed to ensure datastore key layout")
+	}
 
-	controller.Run(cfg, k8sClient, etcdClient, ctx)
+	newStore := func(conf *config.Config) (etcdv3.Client, error) {
+		if dryRun {
+			return nil, fmt.Errorf("etcd settings can't be changed in a dry run")
+		}
+		store, err := datastore.New(conf)
+		if err != nil {
+			return nil, err
+		}
+		if err := store.EnsureSchema(ctx, conf.EtcdMigrate); err != nil {
+			return nil, err
+		}
+		return store, nil
+	}
+	reloadCh := make(chan *config.Config)
+	go watchConfig(reloadCh)
+	controller.Run(cfg, k8sClient, coreClient, dynClient, etcdClient, ctx, newStore, reloadCh, signals.SetupSignalHandler())
 
 }
 
-// getConfig gets the configuration
+// serveMetrics serves the metrics published with expvar on /debug/vars.
+func serveMetrics(addr string) {
+	log.WithField("address", addr).Info("Serving metrics")
+	if err := http.ListenAndServe(addr, nil); err != nil {
+		log.WithError(err).Error("Failed to serve metrics")
+	}
+}
+
+// getConfig gets the configuration. Startup fails if the configuration file is invalid, the
+// defaults are only used when there is no configuration file.
 func getConfig() *config.Config {
-	// Parse the user supplied config. If there are parsing errors then defaults will be returned.
 	cfg := config.NewConfig()
-	if err := cfg.Parse(cfgPath, cfgName); err != nil {
+	if err := cfg.Parse(cfgPath, cfgName); config.IsNotFound(err) {
 		log.WithField("cfgName", cfgName).WithField("cfgPath", cfgPath).
-			WithError(err).Warn("Failed to parse config, using defaults")
+			WithError(err).Warn("Config file not found, using defaults")
+	} else if err != nil {
+		log.WithError(err).Fatal("Failed to parse config")
 	}
 
 	log.WithField("config", fmt.Sprintf("%+v", *cfg)).Info("Configuration loaded")
@@ -107,6 +188,37 @@ func getConfig() *config.Config {
 	return cfg
 }
 
+// watchConfig parses the configuration again whenever the configuration file changes or SIGHUP
+// is received, and sends it to reloadCh. Configurations failing to parse are not sent.
+func watchConfig(reloadCh chan<- *config.Config) {
+	changed := make(chan struct{}, 1)
+	err := config.Watch(cfgPath, cfgName, func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		log.WithError(err).Warn("Failed to watch config file, reloading on SIGHUP only")
+	}
+	hup := signals.SetupReloadHandler()
+	for {
+		select {
+		case <-changed:
+			log.Info("Config file changed, reloading")
+		case <-hup:
+			log.Info("SIGHUP received, reloading config")
+		}
+		cfg := config.NewConfig()
+		if err := cfg.Parse(cfgPath, cfgName); err != nil {
+			log.WithError(err).Error("Failed to reload config, keeping the current configuration")
+			continue
+		}
+		log.WithField("config", cfg.String()).Debug("Configuration reloaded")
+		reloadCh <- cfg
+	}
+}
+
 // getK8SClient builds and returns a Kubernetes client.
 func getK8SClient(kubeconfig string) (*nimbessclientset.Clientset, error) {
 	// Build the kubeconfig.
@@ -127,6 +239,47 @@ func getK8SClient(kubeconfig string) (*nimbessclientset.Clientset, error) {
 	return k8sClient, nil
 }
 
+// getK8sCoreClient builds and returns a Kubernetes core client.
+func getK8sCoreClient(kubeconfig string) (*kubernetes.Clientset, error) {
+	// Build the kubeconfig.
+	if kubeconfig == "" {
+		log.Info("Using inClusterConfig")
+	}
+	k8sConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kubeconfig: %s", err)
+	}
+
+	// Get Kubernetes client.
+	k8sClient, err := kubernetes.NewForConfig(k8sConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kubernetes client: %s", err)
+	}
+
+	return k8sClient, nil
+}
+
+// getK8sDynamicClient builds and returns a Kubernetes dynamic client, used to watch the resources
+// of other projects.
+func getK8sDynamicClient(kubeconfig string) (dynamic.Interface, error) {
+	// Build the kubeconfig.
+	if kubeconfig == "" {
+		log.Info("Using inClusterConfig")
+	}
+	k8sConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kubeconfig: %s", err)
+	}
+
+	// Get Kubernetes client.
+	dynClient, err := dynamic.NewForConfig(k8sConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kubernetes dynamic client: %s", err)
+	}
+
+	return dynClient, nil
+}
+
 // getK8sExtClient builds and returns a Kubernetes client.
 func getK8sExtClient(kubeconfig string) (*extclientset.Clientset, error) {
 	// Build the kubeconfig.
@@ -175,10 +328,12 @@ func debugClient(k8sClient kubernetes.Interface) {
 	log.Infof("services: %+v", services)
 }
 
-func getEtcdClient(config *config.Config) (etcdv3.Client, error) {
-	c, err := etcdv3.New(config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get etcd client: %s", err)
+// getDatastoreClient returns the client of the datastore selected in the configuration.
+func getDatastoreClient(cfg *config.Config, extClient *extclientset.Clientset) (etcdv3.Client, error) {
+	if cfg.Datastore == config.DatastoreKubernetes && !dryRun {
+		if err := unpv1.CreateStateCRD(extClient); err != nil {
+			return nil, fmt.Errorf("failed to create NimbessState CRD: %s", err)
+		}
 	}
-	return c, nil
+	return datastore.New(cfg)
 }
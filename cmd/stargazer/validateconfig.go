@@ -0,0 +1,54 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"github.com/nimbess/stargazer/pkg/config"
+	log "github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// runValidateConfig checks the configuration file, given as argument or selected by the config
+// flags, and prints the invalid settings. Returns the exit code.
+func runValidateConfig(args []string) int {
+	if len(args) > 1 {
+		fmt.Fprintln(os.Stderr, "Usage: stargazer [flags] validate-config [file]")
+		return 2
+	}
+	path, name := cfgPath, cfgName
+	if len(args) == 1 {
+		path = filepath.Dir(args[0])
+		name = strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0]))
+	}
+
+	log.SetLevel(log.ErrorLevel)
+	cfg := config.NewConfig()
+	err := cfg.Parse(path, name)
+	if invalid, ok := err.(*config.InvalidError); ok {
+		fmt.Fprintf(os.Stderr, "Invalid config %s:\n", invalid.File)
+		for _, p := range invalid.Problems {
+			fmt.Fprintf(os.Stderr, "  %s\n", p)
+		}
+		return 1
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	fmt.Printf("Config %s is valid\n", filepath.Join(path, name))
+	return 0
+}
@@ -0,0 +1,311 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/nimbess/stargazer/pkg/config"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/model"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// kind describes how the keys of a kind are named, listed and shown in tables.
+type kind struct {
+	list    func(namespace string) model.ListInterface
+	key     func(name string) (model.Key, error)
+	columns []string
+	row     func(d *model.KVPair) []string
+}
+
+var unpKind = &kind{
+	list: func(namespace string) model.ListInterface {
+		return model.UNPListOptions{Namespace: namespace}
+	},
+	key: func(name string) (model.Key, error) {
+		// policies derived from Ingresses are named <namespace>/ingress/<name>/<service>
+		if parts := strings.Split(name, "/"); len(parts) == 4 && parts[1] == model.OriginIngress && parts[3] != "" {
+			if _, _, err := splitName(parts[0] + "/" + parts[2]); err != nil {
+				return nil, err
+			}
+			return model.UNPKey{Name: name}, nil
+		}
+		if _, _, err := splitName(name); err != nil {
+			return nil, err
+		}
+		return model.UNPKey{Name: name}, nil
+	},
+	columns: []string{"NAMESPACE", "NAME", "ORIGIN", "NETWORK", "MODE", "L7POLICIES", "REVISION"},
+	row: func(d *model.KVPair) []string {
+		p := d.Value.(*model.Policy)
+		origin := p.GetMetadata().GetOrigin()
+		if origin == "" {
+			origin = "unp"
+		}
+		return []string{p.GetMetadata().GetNamespace(), p.GetMetadata().GetName(), origin, p.GetSpec().GetNetwork(),
+			p.GetSpec().GetEnforcementMode(), strconv.Itoa(len(p.GetSpec().GetL7Policies())), d.Revision}
+	},
+}
+
+var nodeKind = &kind{
+	list: func(namespace string) model.ListInterface {
+		return model.NodeListOptions{}
+	},
+	key: func(name string) (model.Key, error) {
+		return model.NodeKey{Hostname: name}, nil
+	},
+	columns: []string{"NAME", "INTERNAL-IP", "POD-CIDR", "REVISION"},
+	row: func(d *model.KVPair) []string {
+		n := d.Value.(*model.Node)
+		return []string{d.Key.(model.NodeKey).Hostname, n.Internalip, n.PodCIDR, d.Revision}
+	},
+}
+
+var endpointKind = &kind{
+	list: func(namespace string) model.ListInterface {
+		return model.EndpointListOptions{Namespace: namespace}
+	},
+	key: func(name string) (model.Key, error) {
+		namespace, name, err := splitName(name)
+		if err != nil {
+			return nil, err
+		}
+		return model.EndpointKey{Namespace: namespace, Name: name}, nil
+	},
+	columns: []string{"NAMESPACE", "NAME", "ADDRESSES", "PORTS", "REVISION"},
+	row: func(d *model.KVPair) []string {
+		k := d.Key.(model.EndpointKey)
+		e := d.Value.(*model.Endpoint)
+		var addresses, ports []string
+		for _, a := range e.Addresses {
+			addresses = append(addresses, a.Ip)
+		}
+		for _, p := range e.Ports {
+			ports = append(ports, fmt.Sprintf("%d/%s", p.Port, p.Protocol))
+		}
+		return []string{k.Namespace, k.Name, strings.Join(addresses, ","), strings.Join(ports, ","), d.Revision}
+	},
+}
+
+var serviceKind = &kind{
+	list: func(namespace string) model.ListInterface {
+		return model.ServiceListOptions{Namespace: namespace}
+	},
+	key: func(name string) (model.Key, error) {
+		namespace, name, err := splitName(name)
+		if err != nil {
+			return nil, err
+		}
+		return model.ServiceKey{Namespace: namespace, Name: name}, nil
+	},
+	columns: []string{"NAMESPACE", "NAME", "TYPE", "CLUSTER-IP", "PORTS", "REVISION"},
+	row: func(d *model.KVPair) []string {
+		k := d.Key.(model.ServiceKey)
+		s := d.Value.(*model.Service)
+		var ports []string
+		for _, p := range s.Ports {
+			ports = append(ports, fmt.Sprintf("%d/%s", p.Port, p.Protocol))
+		}
+		return []string{k.Namespace, k.Name, s.Type, s.ClusterIP, strings.Join(ports, ","), d.Revision}
+	},
+}
+
+var networkKind = &kind{
+	list: func(namespace string) model.ListInterface {
+		return model.NetworkListOptions{}
+	},
+	key: func(name string) (model.Key, error) {
+		return model.NetworkKey{Name: name}, nil
+	},
+	columns: []string{"NAME", "CIDRS", "VLAN", "VNI", "DATAPLANE", "REVISION"},
+	row: func(d *model.KVPair) []string {
+		n := d.Value.(*model.Network)
+		return []string{n.Name, strings.Join(n.Cidrs, ","), strconv.Itoa(int(n.Vlan)), strconv.Itoa(int(n.Vni)),
+			n.DataPlane, d.Revision}
+	},
+}
+
+var attachmentKind = &kind{
+	list: func(namespace string) model.ListInterface {
+		return model.NetworkAttachmentListOptions{Namespace: namespace}
+	},
+	key: func(name string) (model.Key, error) {
+		namespace, name, err := splitName(name)
+		if err != nil {
+			return nil, err
+		}
+		return model.NetworkAttachmentKey{Namespace: namespace, Name: name}, nil
+	},
+	columns: []string{"NAMESPACE", "NAME", "CIDRS", "VLAN", "DATAPLANE", "REVISION"},
+	row: func(d *model.KVPair) []string {
+		k := d.Key.(model.NetworkAttachmentKey)
+		n := d.Value.(*model.Network)
+		return []string{k.Namespace, k.Name, strings.Join(n.Cidrs, ","), strconv.Itoa(int(n.Vlan)), n.DataPlane,
+			d.Revision}
+	},
+}
+
+var interfaceKind = &kind{
+	list: func(namespace string) model.ListInterface {
+		return model.PodInterfaceListOptions{Namespace: namespace}
+	},
+	key: func(name string) (model.Key, error) {
+		// interfaces are named <namespace>/<pod>/<interface>
+		parts := strings.Split(name, "/")
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("invalid name %q, expected <namespace>/<pod>/<interface>", name)
+		}
+		return model.PodInterfaceKey{Namespace: parts[0], Pod: parts[1], Interface: parts[2]}, nil
+	},
+	columns: []string{"NAMESPACE", "POD", "INTERFACE", "NETWORK", "IPS", "MAC", "REVISION"},
+	row: func(d *model.KVPair) []string {
+		i := d.Value.(*model.PodInterface)
+		return []string{i.Namespace, i.Pod, i.Interface, i.Network, strings.Join(i.Ips, ","), i.Mac, d.Revision}
+	},
+}
+
+// kinds maps the names accepted on the command line to the kinds.
+var kinds = map[string]*kind{
+	"unp":         unpKind,
+	"unps":        unpKind,
+	"node":        nodeKind,
+	"nodes":       nodeKind,
+	"endpoint":    endpointKind,
+	"endpoints":   endpointKind,
+	"ep":          endpointKind,
+	"service":     serviceKind,
+	"services":    serviceKind,
+	"svc":         serviceKind,
+	"network":     networkKind,
+	"networks":    networkKind,
+	"net":         networkKind,
+	"attachment":  attachmentKind,
+	"attachments": attachmentKind,
+	"nad":         attachmentKind,
+	"interface":   interfaceKind,
+	"interfaces":  interfaceKind,
+	"if":          interfaceKind,
+}
+
+// entry is a key as printed in JSON and YAML.
+type entry struct {
+	Key      string      `json:"key"`
+	Path     string      `json:"path"`
+	Revision string      `json:"revision"`
+	Value    interface{} `json:"value"`
+}
+
+func newEntry(d *model.KVPair) entry {
+	path, _ := model.KeyToDefaultPath(d.Key)
+	return entry{Key: d.Key.String(), Path: path, Revision: d.Revision, Value: d.Value}
+}
+
+func runList(ctx context.Context, cfg *config.Config, store etcdv3.Client, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("usage: list <kind> [namespace]")
+	}
+	k, err := getKind(args[0])
+	if err != nil {
+		return err
+	}
+	namespace := ""
+	if len(args) == 2 {
+		namespace = args[1]
+	}
+	list, err := store.List(ctx, k.list(namespace))
+	if err != nil {
+		return err
+	}
+	return printKVPairs(k, list.KVPairs)
+}
+
+func runGet(ctx context.Context, cfg *config.Config, store etcdv3.Client, args []string) error {
+	k, d, err := getKVPair(ctx, store, args, "get")
+	if err != nil {
+		return err
+	}
+	return printKVPairs(k, []*model.KVPair{d})
+}
+
+func runDescribe(ctx context.Context, cfg *config.Config, store etcdv3.Client, args []string) error {
+	_, d, err := getKVPair(ctx, store, args, "describe")
+	if err != nil {
+		return err
+	}
+	e := newEntry(d)
+	if output != outputTable {
+		return printers[output](os.Stdout, &result{objects: e})
+	}
+	value, err := toYAML(e.Value)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Key:       %s\nPath:      %s\nRevision:  %s\nValue:\n", e.Key, e.Path, e.Revision)
+	for _, line := range strings.Split(strings.TrimRight(string(value), "\n"), "\n") {
+		fmt.Printf("  %s\n", line)
+	}
+	return nil
+}
+
+// getKVPair gets the key named by the arguments of the get and describe commands.
+func getKVPair(ctx context.Context, store etcdv3.Client, args []string, cmd string) (*kind, *model.KVPair, error) {
+	if len(args) != 2 {
+		return nil, nil, fmt.Errorf("usage: %s <kind> <name>", cmd)
+	}
+	k, err := getKind(args[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := k.key(args[1])
+	if err != nil {
+		return nil, nil, err
+	}
+	d, err := store.Get(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return k, d, nil
+}
+
+func getKind(name string) (*kind, error) {
+	k, ok := kinds[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown kind %q, expected unp, node, endpoint, service or network", name)
+	}
+	return k, nil
+}
+
+// splitName splits a <namespace>/<name> name.
+func splitName(name string) (string, string, error) {
+	parts := strings.Split(name, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid name %q, expected <namespace>/<name>", name)
+	}
+	return parts[0], parts[1], nil
+}
+
+func printKVPairs(k *kind, kvs []*model.KVPair) error {
+	r := &result{header: k.columns}
+	entries := make([]entry, 0, len(kvs))
+	for _, d := range kvs {
+		r.rows = append(r.rows, k.row(d))
+		entries = append(entries, newEntry(d))
+	}
+	r.objects = entries
+	return printers[output](os.Stdout, r)
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The stargazerctl command inspects and manages the Nimbess state written by stargazer.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/nimbess/stargazer/pkg/config"
+	"github.com/nimbess/stargazer/pkg/datastore"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/model"
+	log "github.com/sirupsen/logrus"
+	"os"
+	"time"
+)
+
+// VERSION is updated during the build process using git
+var VERSION = "0.0.1"
+var version = false
+var cfgName = "stargazer"
+var cfgPath = "."
+var output = outputTable
+var timeout = 30 * time.Second
+
+const usage = `Usage: stargazerctl [flags] <command> [args]
+
+Commands:
+  list <kind> [namespace]                List the keys of a kind: unp, node, endpoint, service, network,
+                                         attachment or interface
+  get <kind> <name>                      Get a key, UNPs, endpoints, services and attachments are named
+                                         <namespace>/<name>, interfaces <namespace>/<pod>/<interface>
+  describe <kind> <name>                 Show a key with its path and revision
+  diff                                   Compare the UNPs in the datastore with Kubernetes
+  resync [-dry-run]                      Write the UNPs missing or changed in the datastore
+  delete-orphans [-dry-run]              Delete the UNPs no longer in Kubernetes
+  export [-f file]                       Export the keys under the prefix into an archive
+  import [-f file] [-prefix root] [-tenant tenant] [-dry-run]
+                                         Restore an archive, optionally under another prefix
+
+Flags:
+`
+
+// command runs a subcommand with its arguments.
+type command func(ctx context.Context, cfg *config.Config, store etcdv3.Client, args []string) error
+
+var commands = map[string]command{
+	"list":           runList,
+	"get":            runGet,
+	"describe":       runDescribe,
+	"diff":           runDiff,
+	"resync":         runResync,
+	"delete-orphans": runDeleteOrphans,
+	"export":         runExport,
+	"import":         runImport,
+}
+
+func init() {
+	flag.BoolVar(&version, "v", version, "Display version")
+	flag.StringVar(&cfgPath, "config-path", cfgPath, "Stargazer config file path")
+	flag.StringVar(&cfgName, "config-name", cfgName, "Stargazer config file name")
+	flag.StringVar(&output, "o", output, "Output format: table, json or yaml")
+	flag.DurationVar(&timeout, "timeout", timeout, "Timeout of the command")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	flag.Parse()
+	if version {
+		fmt.Println(VERSION)
+		os.Exit(0)
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if _, ok := printers[output]; !ok {
+		fmt.Fprintf(os.Stderr, "unsupported output format: %s\n", output)
+		os.Exit(2)
+	}
+
+	log.SetLevel(log.WarnLevel)
+	cfg := config.NewConfig()
+	if err := cfg.Parse(cfgPath, cfgName); config.IsNotFound(err) {
+		log.WithError(err).Warn("Config file not found, using defaults")
+	} else if err != nil {
+		fatal(err)
+	}
+	if err := model.SetPrefix(cfg.EtcdPrefix, cfg.Tenant); err != nil {
+		fatal(err)
+	}
+	if err := model.SetValueEncoding(cfg.EtcdValueCodec, cfg.EtcdCompression); err != nil {
+		fatal(err)
+	}
+	store, err := datastore.New(cfg)
+	if err != nil {
+		fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err = cmd(ctx, cfg, store, flag.Args()[1:])
+	store.Close()
+	if err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	os.Exit(1)
+}
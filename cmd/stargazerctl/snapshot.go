@@ -0,0 +1,123 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"flag"
+	"fmt"
+	"github.com/nimbess/stargazer/pkg/config"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/model"
+	"github.com/nimbess/stargazer/pkg/snapshot"
+	"io"
+	"os"
+	"strings"
+)
+
+func runExport(ctx context.Context, cfg *config.Config, store etcdv3.Client, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	file := flags.String("f", "-", "Archive file, gzip compressed if ending with .gz, - for stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	a, err := snapshot.Export(ctx, store, cfg.EtcdPrefix, cfg.Tenant)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *file != "-" {
+		f, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+		if strings.HasSuffix(*file, ".gz") {
+			gz := gzip.NewWriter(f)
+			defer gz.Close()
+			w = gz
+		}
+	}
+	if err := snapshot.Write(w, a); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d keys from %s\n", len(a.Entries), model.Prefix())
+	return nil
+}
+
+func runImport(ctx context.Context, cfg *config.Config, store etcdv3.Client, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := flags.String("f", "-", "Archive file, gzip compressed if ending with .gz, - for stdin")
+	root := flags.String("prefix", "", "Root path to restore the keys under, defaults to the root of the archive")
+	tenant := flags.String("tenant", "", "Tenant to restore the keys under, defaults to the tenant of the archive")
+	dryRun := flags.Bool("dry-run", false, "Show the changes without writing to the datastore")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+		if strings.HasSuffix(*file, ".gz") {
+			gz, err := gzip.NewReader(f)
+			if err != nil {
+				return err
+			}
+			defer gz.Close()
+			r = gz
+		}
+	}
+	a, err := snapshot.Read(r)
+	if err != nil {
+		return err
+	}
+
+	if *root == "" {
+		*root = a.Root
+	}
+	if *tenant == "" {
+		*tenant = a.Tenant
+	}
+	if err := model.SetPrefix(*root, *tenant); err != nil {
+		return err
+	}
+	changes, err := snapshot.Plan(ctx, store, a)
+	if err != nil {
+		return err
+	}
+	if !*dryRun {
+		if err := snapshot.Apply(ctx, store, changes); err != nil {
+			return err
+		}
+	}
+
+	out := &result{header: []string{"ACTION", "KEY", "PATH"}, objects: changes}
+	for _, c := range changes {
+		out.rows = append(out.rows, []string{c.Action, c.Key, c.Path})
+	}
+	if changes == nil {
+		out.objects = []*snapshot.Change{}
+	}
+	return printers[output](os.Stdout, out)
+}
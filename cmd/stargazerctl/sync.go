@@ -0,0 +1,247 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	nimbessclientset "github.com/nimbess/stargazer/pkg/client/clientset/versioned"
+	"github.com/nimbess/stargazer/pkg/config"
+	unpv1 "github.com/nimbess/stargazer/pkg/crd/api/unp/v1"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/model"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Differences between the UNPs in the datastore and in Kubernetes.
+const (
+	// diffMissing is an UNP only found in Kubernetes.
+	diffMissing = "missing"
+	// diffChanged is an UNP whose value in the datastore differs from Kubernetes.
+	diffChanged = "changed"
+	// diffOrphaned is an UNP only found in the datastore.
+	diffOrphaned = "orphaned"
+)
+
+// difference is an UNP that differs between the datastore and Kubernetes.
+type difference struct {
+	Status   string `json:"status"`
+	Key      string `json:"key"`
+	Revision string `json:"revision,omitempty"`
+	Action   string `json:"action,omitempty"`
+
+	key model.UNPKey
+	// policy is the value expected in the datastore, nil for orphaned UNPs.
+	policy *model.Policy
+}
+
+// diffPolicies compares the UNPs in Kubernetes with the keys in the datastore. Policies derived from
+// other objects, e.g. Ingresses, and policies of namespaces out of scope are ignored.
+func diffPolicies(unps []unpv1.UnifiedNetworkPolicy, kvs []*model.KVPair, inScope func(namespace string) bool) []*difference {
+	stored := make(map[string]*model.KVPair, len(kvs))
+	for _, d := range kvs {
+		name := d.Key.(model.UNPKey).Name
+		if d.Value.(*model.Policy).GetMetadata().GetOrigin() != "" || !inScope(strings.SplitN(name, "/", 2)[0]) {
+			continue
+		}
+		stored[name] = d
+	}
+
+	var diffs []*difference
+	for i := range unps {
+		if !inScope(unps[i].Namespace) {
+			continue
+		}
+		name := path.Join(unps[i].Namespace, unps[i].Name)
+		policy := model.NewPolicy(&unps[i])
+		d, ok := stored[name]
+		delete(stored, name)
+		switch {
+		case !ok:
+			diffs = append(diffs, &difference{Status: diffMissing, key: model.UNPKey{Name: name}, policy: policy})
+		case !proto.Equal(policy, d.Value.(*model.Policy)):
+			diffs = append(diffs, &difference{Status: diffChanged, key: model.UNPKey{Name: name},
+				Revision: d.Revision, policy: policy})
+		}
+	}
+	for name, d := range stored {
+		diffs = append(diffs, &difference{Status: diffOrphaned, key: model.UNPKey{Name: name}, Revision: d.Revision})
+	}
+
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].key.Name < diffs[j].key.Name })
+	for _, diff := range diffs {
+		diff.Key = diff.key.String()
+	}
+	return diffs
+}
+
+func runDiff(ctx context.Context, cfg *config.Config, store etcdv3.Client, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: diff")
+	}
+	diffs, err := getDifferences(ctx, cfg, store)
+	if err != nil {
+		return err
+	}
+	return printDifferences(diffs, false)
+}
+
+func runResync(ctx context.Context, cfg *config.Config, store etcdv3.Client, args []string) error {
+	return syncDifferences(ctx, cfg, store, args, "resync", func(diff *difference) error {
+		d := &model.KVPair{Key: diff.key, Value: diff.policy, Revision: diff.Revision}
+		switch diff.Status {
+		case diffMissing:
+			diff.Action = "created"
+			return store.Create(ctx, d)
+		case diffChanged:
+			diff.Action = "updated"
+			return store.Update(ctx, d)
+		}
+		return nil
+	})
+}
+
+func runDeleteOrphans(ctx context.Context, cfg *config.Config, store etcdv3.Client, args []string) error {
+	return syncDifferences(ctx, cfg, store, args, "delete-orphans", func(diff *difference) error {
+		if diff.Status != diffOrphaned {
+			return nil
+		}
+		diff.Action = "deleted"
+		return store.Delete(ctx, diff.key)
+	})
+}
+
+// syncDifferences applies fix to each difference, unless running dry.
+func syncDifferences(ctx context.Context, cfg *config.Config, store etcdv3.Client, args []string, cmd string,
+	fix func(diff *difference) error) error {
+
+	flags := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "Show the differences without writing to the datastore")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	diffs, err := getDifferences(ctx, cfg, store)
+	if err != nil {
+		return err
+	}
+	if !*dryRun {
+		for _, diff := range diffs {
+			if err := fix(diff); err != nil {
+				_ = printDifferences(diffs, true)
+				return fmt.Errorf("failed to %s %s: %v", cmd, diff.Key, err)
+			}
+		}
+	}
+	return printDifferences(diffs, !*dryRun)
+}
+
+// getDifferences lists the UNPs in the configured namespaces from Kubernetes and the datastore, the
+// namespaces being scoped as by the stargazer controllers.
+func getDifferences(ctx context.Context, cfg *config.Config, store etcdv3.Client) ([]*difference, error) {
+	scope, err := cfg.NamespaceScope()
+	if err != nil {
+		return nil, err
+	}
+	namespaces := []string{metav1.NamespaceAll}
+	if scope.Namespaces.Len() > 0 && scope.Selector == nil {
+		namespaces = scope.Namespaces.List()
+	}
+
+	k8sConfig, err := clientcmd.BuildConfigFromFlags("", cfg.Kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kubeconfig: %s", err)
+	}
+	client, err := nimbessclientset.NewForConfig(k8sConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kubernetes client: %s", err)
+	}
+	inScope, err := namespaceFilter(scope, k8sConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var unps []unpv1.UnifiedNetworkPolicy
+	var kvs []*model.KVPair
+	for _, ns := range namespaces {
+		list, err := client.NimbessV1().UnifiedNetworkPolicies(ns).List(metav1.ListOptions{LabelSelector: cfg.LabelSelector})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list UNPs: %v", err)
+		}
+		unps = append(unps, list.Items...)
+		stored, err := store.List(ctx, model.UNPListOptions{Namespace: ns})
+		if err != nil {
+			return nil, err
+		}
+		kvs = append(kvs, stored.KVPairs...)
+	}
+	return diffPolicies(unps, kvs, inScope), nil
+}
+
+// namespaceFilter returns whether a namespace is in scope, looking up the labels of the namespaces
+// when they are selected by labels.
+func namespaceFilter(scope *config.NamespaceScope, k8sConfig *rest.Config) (func(namespace string) bool, error) {
+	if scope.Selector == nil {
+		return scope.Listed, nil
+	}
+	client, err := kubernetes.NewForConfig(k8sConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kubernetes client: %s", err)
+	}
+	list, err := client.CoreV1().Namespaces().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %v", err)
+	}
+	return selectNamespaces(scope, list.Items), nil
+}
+
+// selectNamespaces returns whether a namespace is in scope, namespaces missing from the list are not.
+func selectNamespaces(scope *config.NamespaceScope, namespaces []v1.Namespace) func(namespace string) bool {
+	selected := map[string]bool{}
+	for _, ns := range namespaces {
+		selected[ns.Name] = scope.Matches(ns.Name, ns.Labels)
+	}
+	return func(namespace string) bool {
+		return selected[namespace]
+	}
+}
+
+func printDifferences(diffs []*difference, applied bool) error {
+	r := &result{header: []string{"STATUS", "KEY", "REVISION"}, objects: diffs}
+	if applied {
+		r.header = append(r.header, "ACTION")
+	}
+	for _, diff := range diffs {
+		row := []string{diff.Status, diff.Key, diff.Revision}
+		if applied {
+			row = append(row, diff.Action)
+		}
+		r.rows = append(r.rows, row)
+	}
+	if diffs == nil {
+		r.objects = []*difference{}
+	}
+	return printers[output](os.Stdout, r)
+}
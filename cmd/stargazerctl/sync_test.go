@@ -0,0 +1,90 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"github.com/nimbess/stargazer/pkg/config"
+	unpv1 "github.com/nimbess/stargazer/pkg/crd/api/unp/v1"
+	"github.com/nimbess/stargazer/pkg/model"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func newUNP(namespace, name, network string) unpv1.UnifiedNetworkPolicy {
+	return unpv1.UnifiedNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       unpv1.UnifiedNetworkPolicySpec{Network: network},
+	}
+}
+
+func TestDiffPolicies(t *testing.T) {
+	same := newUNP("default", "same", "net1")
+	changed := newUNP("default", "changed", "net1")
+	unps := []unpv1.UnifiedNetworkPolicy{same, changed, newUNP("default", "missing", "net1")}
+
+	stale := newUNP("default", "changed", "net2")
+	ingressPolicy := model.NewPolicy(&stale)
+	ingressPolicy.Metadata.Origin = model.OriginIngress
+	kvs := []*model.KVPair{
+		{Key: model.UNPKey{Name: "default/same"}, Value: model.NewPolicy(&same), Revision: "1"},
+		{Key: model.UNPKey{Name: "default/changed"}, Value: model.NewPolicy(&stale), Revision: "2"},
+		{Key: model.UNPKey{Name: "default/orphan"}, Value: model.NewPolicy(&stale), Revision: "3"},
+		{Key: model.UNPKey{Name: "default/ingress/web/web"}, Value: ingressPolicy, Revision: "4"},
+	}
+
+	expected := []struct{ status, name, revision string }{
+		{diffChanged, "default/changed", "2"},
+		{diffMissing, "default/missing", ""},
+		{diffOrphaned, "default/orphan", "3"},
+	}
+	diffs := diffPolicies(unps, kvs, func(string) bool { return true })
+	if len(diffs) != len(expected) {
+		t.Fatalf("Expected %d differences, got %d", len(expected), len(diffs))
+	}
+	for i, e := range expected {
+		d := diffs[i]
+		if d.Status != e.status || d.key.Name != e.name || d.Revision != e.revision {
+			t.Errorf("Expected: %v\nGot: %+v", e, d)
+		}
+		if (d.policy == nil) != (e.status == diffOrphaned) {
+			t.Errorf("%s: unexpected policy %v", e.name, d.policy)
+		}
+	}
+}
+
+func TestDiffPolicies_NamespaceSelector(t *testing.T) {
+	conf := config.NewConfig()
+	conf.NamespaceSelector = "team=web"
+	scope, err := conf.NamespaceScope()
+	if err != nil {
+		t.Fatal(err)
+	}
+	inScope := selectNamespaces(scope, []v1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{"team": "web"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "db", Labels: map[string]string{"team": "db"}}},
+	})
+
+	stale := newUNP("db", "orphan", "net1")
+	unps := []unpv1.UnifiedNetworkPolicy{newUNP("web", "missing", "net1"), newUNP("db", "other", "net1"),
+		newUNP("unknown", "other", "net1")}
+	kvs := []*model.KVPair{
+		{Key: model.UNPKey{Name: "db/orphan"}, Value: model.NewPolicy(&stale), Revision: "1"},
+	}
+	diffs := diffPolicies(unps, kvs, inScope)
+	if len(diffs) != 1 || diffs[0].Status != diffMissing || diffs[0].key.Name != "web/missing" {
+		t.Errorf("Expected only web/missing to differ, got %+v", diffs)
+	}
+}
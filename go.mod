@@ -1,15 +1,23 @@
 module github.com/nimbess/stargazer
 
+go 1.13
+
 require (
 	github.com/coreos/etcd v3.3.15+incompatible
+	github.com/coreos/pkg v0.0.0-20180928190104-399ea9e2e55f
+	github.com/fsnotify/fsnotify v1.4.7
+	github.com/golang/protobuf v1.3.1
 	github.com/magiconair/properties v1.8.1 // indirect
+	github.com/mitchellh/mapstructure v1.1.2
 	github.com/sirupsen/logrus v1.4.2
 	github.com/spf13/jwalterweatherman v1.1.0 // indirect
 	github.com/spf13/viper v1.4.0
+	google.golang.org/grpc v1.23.0
 	k8s.io/api v0.0.0
 	k8s.io/apiextensions-apiserver v0.0.0
 	k8s.io/apimachinery v0.0.0
 	k8s.io/client-go v0.0.0
+	sigs.k8s.io/yaml v1.1.0
 )
 
 replace (
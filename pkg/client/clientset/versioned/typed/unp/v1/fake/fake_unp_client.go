@@ -32,6 +32,14 @@ func (c *FakeNimbessV1) UnifiedNetworkPolicies(namespace string) v1.UnifiedNetwo
 	return &FakeUnifiedNetworkPolicies{c, namespace}
 }
 
+func (c *FakeNimbessV1) Networks() v1.NetworkInterface {
+	return &FakeNetworks{c}
+}
+
+func (c *FakeNimbessV1) UnpConfigs() v1.UnpConfigInterface {
+	return &FakeUnpConfigs{c}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeNimbessV1) RESTClient() rest.Interface {
@@ -31,7 +31,6 @@ import (
 // FakeUnpConfigs implements UnpConfigInterface
 type FakeUnpConfigs struct {
 	Fake *FakeNimbessV1
-	ns   string
 }
 
 var unpconfigsResource = schema.GroupVersionResource{Group: "nimbess", Version: "v1", Resource: "unpconfigs"}
@@ -41,7 +40,7 @@ var unpconfigsKind = schema.GroupVersionKind{Group: "nimbess", Version: "v1", Ki
 // Get takes name of the unpConfig, and returns the corresponding unpConfig object, and an error if there is any.
 func (c *FakeUnpConfigs) Get(name string, options v1.GetOptions) (result *unpv1.UnpConfig, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewGetAction(unpconfigsResource, c.ns, name), &unpv1.UnpConfig{})
+		Invokes(testing.NewRootGetAction(unpconfigsResource, name), &unpv1.UnpConfig{})
 
 	if obj == nil {
 		return nil, err
@@ -52,7 +51,7 @@ func (c *FakeUnpConfigs) Get(name string, options v1.GetOptions) (result *unpv1.
 // List takes label and field selectors, and returns the list of UnpConfigs that match those selectors.
 func (c *FakeUnpConfigs) List(opts v1.ListOptions) (result *unpv1.UnpConfigList, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewListAction(unpconfigsResource, unpconfigsKind, c.ns, opts), &unpv1.UnpConfigList{})
+		Invokes(testing.NewRootListAction(unpconfigsResource, unpconfigsKind, opts), &unpv1.UnpConfigList{})
 
 	if obj == nil {
 		return nil, err
@@ -74,14 +73,14 @@ func (c *FakeUnpConfigs) List(opts v1.ListOptions) (result *unpv1.UnpConfigList,
 // Watch returns a watch.Interface that watches the requested unpConfigs.
 func (c *FakeUnpConfigs) Watch(opts v1.ListOptions) (watch.Interface, error) {
 	return c.Fake.
-		InvokesWatch(testing.NewWatchAction(unpconfigsResource, c.ns, opts))
+		InvokesWatch(testing.NewRootWatchAction(unpconfigsResource, opts))
 
 }
 
 // Create takes the representation of a unpConfig and creates it.  Returns the server's representation of the unpConfig, and an error, if there is any.
 func (c *FakeUnpConfigs) Create(unpConfig *unpv1.UnpConfig) (result *unpv1.UnpConfig, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewCreateAction(unpconfigsResource, c.ns, unpConfig), &unpv1.UnpConfig{})
+		Invokes(testing.NewRootCreateAction(unpconfigsResource, unpConfig), &unpv1.UnpConfig{})
 
 	if obj == nil {
 		return nil, err
@@ -92,7 +91,7 @@ func (c *FakeUnpConfigs) Create(unpConfig *unpv1.UnpConfig) (result *unpv1.UnpCo
 // Update takes the representation of a unpConfig and updates it. Returns the server's representation of the unpConfig, and an error, if there is any.
 func (c *FakeUnpConfigs) Update(unpConfig *unpv1.UnpConfig) (result *unpv1.UnpConfig, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewUpdateAction(unpconfigsResource, c.ns, unpConfig), &unpv1.UnpConfig{})
+		Invokes(testing.NewRootUpdateAction(unpconfigsResource, unpConfig), &unpv1.UnpConfig{})
 
 	if obj == nil {
 		return nil, err
@@ -100,17 +99,28 @@ func (c *FakeUnpConfigs) Update(unpConfig *unpv1.UnpConfig) (result *unpv1.UnpCo
 	return obj.(*unpv1.UnpConfig), err
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeUnpConfigs) UpdateStatus(unpConfig *unpv1.UnpConfig) (*unpv1.UnpConfig, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateSubresourceAction(unpconfigsResource, "status", unpConfig), &unpv1.UnpConfig{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*unpv1.UnpConfig), err
+}
+
 // Delete takes name of the unpConfig and deletes it. Returns an error if one occurs.
 func (c *FakeUnpConfigs) Delete(name string, options *v1.DeleteOptions) error {
 	_, err := c.Fake.
-		Invokes(testing.NewDeleteAction(unpconfigsResource, c.ns, name), &unpv1.UnpConfig{})
+		Invokes(testing.NewRootDeleteAction(unpconfigsResource, name), &unpv1.UnpConfig{})
 
 	return err
 }
 
 // DeleteCollection deletes a collection of objects.
 func (c *FakeUnpConfigs) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
-	action := testing.NewDeleteCollectionAction(unpconfigsResource, c.ns, listOptions)
+	action := testing.NewRootDeleteCollectionAction(unpconfigsResource, listOptions)
 
 	_, err := c.Fake.Invokes(action, &unpv1.UnpConfigList{})
 	return err
@@ -119,7 +129,7 @@ func (c *FakeUnpConfigs) DeleteCollection(options *v1.DeleteOptions, listOptions
 // Patch applies the patch and returns the patched unpConfig.
 func (c *FakeUnpConfigs) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *unpv1.UnpConfig, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewPatchSubresourceAction(unpconfigsResource, c.ns, name, pt, data, subresources...), &unpv1.UnpConfig{})
+		Invokes(testing.NewRootPatchSubresourceAction(unpconfigsResource, name, pt, data, subresources...), &unpv1.UnpConfig{})
 
 	if obj == nil {
 		return nil, err
@@ -19,3 +19,7 @@ limitations under the License.
 package v1
 
 type UnifiedNetworkPolicyExpansion interface{}
+
+type NetworkExpansion interface{}
+
+type UnpConfigExpansion interface{}
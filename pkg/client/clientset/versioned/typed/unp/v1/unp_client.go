@@ -27,6 +27,8 @@ import (
 type NimbessV1Interface interface {
 	RESTClient() rest.Interface
 	UnifiedNetworkPoliciesGetter
+	NetworksGetter
+	UnpConfigsGetter
 }
 
 // NimbessV1Client is used to interact with features provided by the nimbess group.
@@ -38,6 +40,14 @@ func (c *NimbessV1Client) UnifiedNetworkPolicies(namespace string) UnifiedNetwor
 	return newUnifiedNetworkPolicies(c, namespace)
 }
 
+func (c *NimbessV1Client) Networks() NetworkInterface {
+	return newNetworks(c)
+}
+
+func (c *NimbessV1Client) UnpConfigs() UnpConfigInterface {
+	return newUnpConfigs(c)
+}
+
 // NewForConfig creates a new NimbessV1Client for the given config.
 func NewForConfig(c *rest.Config) (*NimbessV1Client, error) {
 	config := *c
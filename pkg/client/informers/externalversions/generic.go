@@ -55,6 +55,10 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=nimbess, Version=v1
 	case v1.SchemeGroupVersion.WithResource("unifiednetworkpolicies"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Nimbess().V1().UnifiedNetworkPolicies().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("networks"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Nimbess().V1().Networks().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("unpconfigs"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Nimbess().V1().UnpConfigs().Informer()}, nil
 
 	}
 
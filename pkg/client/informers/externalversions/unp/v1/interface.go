@@ -26,6 +26,10 @@ import (
 type Interface interface {
 	// UnifiedNetworkPolicies returns a UnifiedNetworkPolicyInformer.
 	UnifiedNetworkPolicies() UnifiedNetworkPolicyInformer
+	// Networks returns a NetworkInformer.
+	Networks() NetworkInformer
+	// UnpConfigs returns a UnpConfigInformer.
+	UnpConfigs() UnpConfigInformer
 }
 
 type version struct {
@@ -43,3 +47,13 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 func (v *version) UnifiedNetworkPolicies() UnifiedNetworkPolicyInformer {
 	return &unifiedNetworkPolicyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// Networks returns a NetworkInformer.
+func (v *version) Networks() NetworkInformer {
+	return &networkInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// UnpConfigs returns a UnpConfigInformer.
+func (v *version) UnpConfigs() UnpConfigInformer {
+	return &unpConfigInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
@@ -25,3 +25,11 @@ type UnifiedNetworkPolicyListerExpansion interface{}
 // UnifiedNetworkPolicyNamespaceListerExpansion allows custom methods to be added to
 // UnifiedNetworkPolicyNamespaceLister.
 type UnifiedNetworkPolicyNamespaceListerExpansion interface{}
+
+// NetworkListerExpansion allows custom methods to be added to
+// NetworkLister.
+type NetworkListerExpansion interface{}
+
+// UnpConfigListerExpansion allows custom methods to be added to
+// UnpConfigLister.
+type UnpConfigListerExpansion interface{}
@@ -16,37 +16,164 @@
 package config
 
 import (
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"github.com/mitchellh/mapstructure"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+	"reflect"
 	"time"
 )
 
-// Controllers holds the enabled/disabled controller types
+// Supported datastores
+const (
+	DatastoreEtcd       = "etcd"
+	DatastoreKubernetes = "kubernetes"
+)
+
+// Controllers holds the enabled/disabled controller types. In the configuration file it is either a
+// comma separated list of the enabled controllers, e.g. "unp", or a map of controller names to booleans.
+// Service and EndpointSlice publish the services and their backends for the L7 policies, Ingress
+// derives L7 policies from the Ingress objects. Network publishes the networks, UNPs referencing a
+// missing network are then not written. NetworkAttachment publishes the Multus
+// NetworkAttachmentDefinitions as networks UNPs can refer to by <namespace>/<name>, PodNetwork
+// publishes the interfaces Multus attached to the pods.
 type Controllers struct {
-	UNP bool
+	UNP               bool
+	Service           bool
+	EndpointSlice     bool
+	Ingress           bool
+	Network           bool
+	NetworkAttachment bool
+	PodNetwork        bool
 }
 
 // Config stores the parsed configuration or defaults.
+// The configuration is reloaded when the file changes or on SIGHUP. Kubeconfig, Datastore,
+// DatastoreNamespace, Tenant, EtcdPrefix, EtcdMigrate, the value encoding, MetricsAddress and
+// UnpConfigName are only read at startup.
 type Config struct {
-	LogLevel        string
-	Controllers     Controllers
-	Kubeconfig      string
-	ResyncPeriod    int64
+	LogLevel    string
+	Controllers Controllers
+	// UNPWorkers is the number of workers processing the events of the UNP controller.
+	UNPWorkers int
+	// ShutdownGracePeriod is how long the controllers are given to process the queued events when
+	// stopping, after which the datastore operations in flight are cancelled.
+	ShutdownGracePeriod time.Duration
+	Kubeconfig          string
+	// ResyncPeriod is in seconds, 0 disables periodic resyncs of the informers.
+	ResyncPeriod int64
+	// Namespaces is a comma separated list of namespaces to watch, all if empty.
+	Namespaces string
+	// NamespaceSelector further restricts the watched namespaces to those whose labels match.
+	NamespaceSelector string
+	// LabelSelector restricts the watched Nimbess resources to those matching the selector.
+	LabelSelector string
+	// Tenant is an optional path segment following EtcdPrefix, used to keep the state of several
+	// instances apart.
+	Tenant          string
 	EtcdEndpoints   string
 	EtcdDialTimeout time.Duration
+	// EtcdPrefix is the root path of all keys written to etcd.
+	EtcdPrefix string
+	// EtcdMigrate allows stargazer to move keys written with an older key layout, or under the
+	// legacy root path, at startup.
+	EtcdMigrate bool
+	// EtcdCAFile, EtcdCertFile and EtcdKeyFile enable TLS towards etcd, the files are reloaded when
+	// they change on disk.
+	EtcdCAFile   string
+	EtcdCertFile string
+	EtcdKeyFile  string
+	// EtcdServerName overrides the name used to verify the etcd server certificate.
+	EtcdServerName string
+	// EtcdUsername and EtcdPassword enable etcd authentication.
+	EtcdUsername string
+	EtcdPassword string
+	// EtcdLeaseTTL attaches all written keys to a lease kept alive by stargazer, so that they expire
+	// if stargazer is gone for longer than the TTL. 0 disables leases.
+	EtcdLeaseTTL time.Duration
+	// EtcdBatchWindow coalesces the writes submitted within the window into etcd transactions,
+	// 0 disables batching.
+	EtcdBatchWindow time.Duration
+	// EtcdBatchMaxOps is the maximum number of operations of a batch transaction, which must not
+	// exceed the --max-txn-ops of the etcd servers.
+	EtcdBatchMaxOps int
+	// EtcdValueCodec ("json" or "protobuf") and EtcdCompression ("none" or "gzip") select the
+	// encoding of the written values, values of any encoding are read.
+	EtcdValueCodec  string
+	EtcdCompression string
+	// Datastore selects where the Nimbess model is stored: "etcd" or "kubernetes", in which case the
+	// keys are stored as NimbessState objects in DatastoreNamespace.
+	Datastore          string
+	DatastoreNamespace string
+	// MetricsAddress is the address serving the metrics on /debug/vars, empty to disable it.
+	MetricsAddress string
+	// UnpConfigName is the name of the cluster scoped UnpConfig object overriding this configuration
+	// at runtime, empty to disable it. Settings of the UnpConfig take precedence over the
+	// configuration file and the environment.
+	UnpConfigName string
 }
 
 // NewConfig is the constructor for Config.
 func NewConfig() *Config {
 	ctrl := Controllers{UNP: true}
 	return &Config{
-		LogLevel:        "info",
-		Controllers:     ctrl,
-		Kubeconfig:      "",
-		ResyncPeriod:    0,
-		EtcdEndpoints:   "http://127.0.0.1:52379",
-		EtcdDialTimeout: 1 * time.Second,
+		LogLevel:            "info",
+		Controllers:         ctrl,
+		UNPWorkers:          1,
+		ShutdownGracePeriod: 10 * time.Second,
+		Kubeconfig:          "",
+		ResyncPeriod:        0,
+		Namespaces:          "",
+		NamespaceSelector:   "",
+		LabelSelector:       "",
+		Tenant:              "",
+		EtcdEndpoints:       "http://127.0.0.1:52379",
+		EtcdDialTimeout:     1 * time.Second,
+		EtcdPrefix:          "/nimbess",
+		EtcdMigrate:         false,
+		EtcdCAFile:          "",
+		EtcdCertFile:        "",
+		EtcdKeyFile:         "",
+		EtcdServerName:      "",
+		EtcdUsername:        "",
+		EtcdPassword:        "",
+		EtcdLeaseTTL:        0,
+		EtcdBatchWindow:     0,
+		EtcdBatchMaxOps:     128,
+		EtcdValueCodec:      "json",
+		EtcdCompression:     "none",
+		Datastore:           DatastoreEtcd,
+		DatastoreNamespace:  "kube-system",
+		MetricsAddress:      "",
+		UnpConfigName:       "default",
+	}
+}
+
+// String returns the configuration with secrets masked, suitable for logging.
+func (c Config) String() string {
+	// plainConfig has no String method, avoiding recursion when formatting
+	type plainConfig Config
+	masked := plainConfig(c)
+	if masked.EtcdPassword != "" {
+		masked.EtcdPassword = "*****"
+	}
+	return fmt.Sprintf("%+v", masked)
+}
+
+// Enabled returns true if the controller is enabled.
+func (c *Config) Enabled(controller string) bool {
+	v := reflect.ValueOf(c.Controllers).FieldByName(controller)
+	return v.IsValid() && v.Bool()
+}
+
+// Workers returns the number of workers of a controller, at least 1.
+func (c *Config) Workers(controller string) int {
+	v := reflect.ValueOf(c).Elem().FieldByName(controller + "Workers")
+	if !v.IsValid() || v.Int() < 1 {
+		return 1
 	}
+	return int(v.Int())
 }
 
 // Parse the configuration and store in Config.
@@ -54,31 +181,104 @@ func NewConfig() *Config {
 func (c *Config) Parse(cfgPath string, cfgName string) error {
 	vpr := viper.New()
 	defaults := map[string]interface{}{
-		"LogLevel":        c.LogLevel,
-		"Controllers":     c.Controllers,
-		"Kubeconfig":      c.Kubeconfig,
-		"ResyncPeriod":    c.ResyncPeriod,
-		"EtcdEndpoints":   c.EtcdEndpoints,
-		"EtcdDialTimeout": c.EtcdDialTimeout,
+		"LogLevel":            c.LogLevel,
+		"Controllers":         c.Controllers,
+		"UNPWorkers":          c.UNPWorkers,
+		"ShutdownGracePeriod": c.ShutdownGracePeriod,
+		"Kubeconfig":          c.Kubeconfig,
+		"ResyncPeriod":        c.ResyncPeriod,
+		"Namespaces":          c.Namespaces,
+		"NamespaceSelector":   c.NamespaceSelector,
+		"LabelSelector":       c.LabelSelector,
+		"Tenant":              c.Tenant,
+		"EtcdEndpoints":       c.EtcdEndpoints,
+		"EtcdDialTimeout":     c.EtcdDialTimeout,
+		"EtcdPrefix":          c.EtcdPrefix,
+		"EtcdMigrate":         c.EtcdMigrate,
+		"EtcdCAFile":          c.EtcdCAFile,
+		"EtcdCertFile":        c.EtcdCertFile,
+		"EtcdKeyFile":         c.EtcdKeyFile,
+		"EtcdServerName":      c.EtcdServerName,
+		"EtcdUsername":        c.EtcdUsername,
+		"EtcdPassword":        c.EtcdPassword,
+		"EtcdLeaseTTL":        c.EtcdLeaseTTL,
+		"EtcdBatchWindow":     c.EtcdBatchWindow,
+		"EtcdBatchMaxOps":     c.EtcdBatchMaxOps,
+		"EtcdValueCodec":      c.EtcdValueCodec,
+		"EtcdCompression":     c.EtcdCompression,
+		"Datastore":           c.Datastore,
+		"DatastoreNamespace":  c.DatastoreNamespace,
+		"MetricsAddress":      c.MetricsAddress,
+		"UnpConfigName":       c.UnpConfigName,
 	}
 	for k, v := range defaults {
 		vpr.SetDefault(k, v)
 	}
 
-	// TODO: tbd to use default paths and name
-	vpr.AddConfigPath(cfgPath)
-	vpr.SetConfigName(cfgName)
+	setConfigFile(vpr, cfgPath, cfgName)
 	vpr.AutomaticEnv()
 	_ = vpr.BindEnv("EtcdEndpoints", "ETCDCTL_ENDPOINTS")
+	_ = vpr.BindEnv("EtcdCAFile", "ETCDCTL_CACERT")
+	_ = vpr.BindEnv("EtcdCertFile", "ETCDCTL_CERT")
+	_ = vpr.BindEnv("EtcdKeyFile", "ETCDCTL_KEY")
+	_ = vpr.BindEnv("EtcdPassword", "ETCD_PASSWORD")
 	err := vpr.ReadInConfig()
 	if err != nil {
 		log.WithError(err).Warn("Failed to read config")
 		return err
 	}
 
-	err = vpr.Unmarshal(c)
+	// Decode into a copy so that defaults are kept if the configuration is invalid
+	parsed := *c
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           &parsed,
+		ErrorUnused:      true,
+		WeaklyTypedInput: true,
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			controllersHook,
+			durationHook,
+		),
+	})
+	if err == nil {
+		err = decoder.Decode(vpr.AllSettings())
+	}
+	if err == nil {
+		err = parsed.Validate()
+	}
 	if err != nil {
+		err = newInvalidError(vpr.ConfigFileUsed(), err)
 		log.WithError(err).Warn("Failed to unmarshal config")
+		return err
+	}
+	*c = parsed
+	return nil
+}
+
+// IsNotFound returns true if Parse failed because the configuration file does not exist.
+func IsNotFound(err error) bool {
+	_, ok := err.(viper.ConfigFileNotFoundError)
+	return ok
+}
+
+// Watch calls onChange whenever the configuration file changes, including when a mounted ConfigMap
+// is updated. The file is watched for the lifetime of the process. Returns an error if the file
+// can't be found.
+func Watch(cfgPath string, cfgName string, onChange func()) error {
+	vpr := viper.New()
+	setConfigFile(vpr, cfgPath, cfgName)
+	if err := vpr.ReadInConfig(); err != nil {
+		return err
 	}
-	return err
+	vpr.OnConfigChange(func(e fsnotify.Event) {
+		log.WithField("file", e.Name).Debug("Config file changed")
+		onChange()
+	})
+	vpr.WatchConfig()
+	return nil
+}
+
+func setConfigFile(vpr *viper.Viper, cfgPath string, cfgName string) {
+	// TODO: tbd to use default paths and name
+	vpr.AddConfigPath(cfgPath)
+	vpr.SetConfigName(cfgName)
 }
@@ -22,6 +22,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 type testinput struct {
@@ -40,22 +41,45 @@ type testio struct {
 	expected testoutput
 }
 
-var defaultCfg = &config.Config{
-	LogLevel: "Debug", Controllers: "node", NodeWorkers: 1, UNPWorkers: 1, Kubeconfig: "/etc/kubernetes/admin.conf",
-	EtcdEndpoints: "http://127.0.0.1:12379", EtcdDialTimeout: 1,
-}
+// parsedCfg is the configuration of testdata/stargazer.yaml
+var parsedCfg = func() *config.Config {
+	c := config.NewConfig()
+	c.LogLevel = "Debug"
+	c.Controllers = config.Controllers{UNP: true}
+	c.UNPWorkers = 2
+	c.Kubeconfig = "/etc/kubernetes/admin.conf"
+	c.EtcdEndpoints = "http://127.0.0.1:12379"
+	c.EtcdDialTimeout = time.Second
+	return c
+}()
+
+// mapCfg is the configuration of testdata/map.yaml
+var mapCfg = func() *config.Config {
+	c := config.NewConfig()
+	c.LogLevel = "Debug"
+	c.Controllers = config.Controllers{UNP: false}
+	return c
+}()
 
 var tests = []testio{
 	// pass: successful read and parse of config
-	{testinput{"parse 1", "./testdata", "stargazer"}, testoutput{"success", *defaultCfg}},
+	{testinput{"parse 1", "./testdata", "stargazer"}, testoutput{"success", *parsedCfg}},
 	// pass: bad path, expected fail
-	{testinput{"parse 2", "./badpath", "stargazer"}, testoutput{"badpath", *defaultCfg}},
+	{testinput{"parse 2", "./badpath", "stargazer"}, testoutput{"badpath", *config.NewConfig()}},
 	// pass: bad name, expected fail
-	{testinput{"parse 3", "./testdata", "badname"}, testoutput{"badname", *defaultCfg}},
-	// pass: successful read and parse of config with extra params that are ignored
-	{testinput{"parse 4", "./testdata", "extra"}, testoutput{"success", *defaultCfg}},
+	{testinput{"parse 3", "./testdata", "badname"}, testoutput{"badname", *config.NewConfig()}},
+	// pass: fail to parse config with unknown params
+	{testinput{"parse 4", "./testdata", "extra"}, testoutput{"invalid", *config.NewConfig()}},
 	// pass: fail to read and parse of config with invalid params
-	{testinput{"parse 5", "./testdata", "invalid"}, testoutput{"invalid", *defaultCfg}},
+	{testinput{"parse 5", "./testdata", "invalid"}, testoutput{"invalid", *config.NewConfig()}},
+	// pass: fail to parse config with unsupported controllers
+	{testinput{"parse 6", "./testdata", "controllers"}, testoutput{"invalid", *config.NewConfig()}},
+	// pass: fail to parse config with a duration without unit
+	{testinput{"parse 7", "./testdata", "duration"}, testoutput{"invalid", *config.NewConfig()}},
+	// pass: fail to validate config with invalid values
+	{testinput{"parse 8", "./testdata", "values"}, testoutput{"invalid", *config.NewConfig()}},
+	// pass: successful read and parse of config with controllers as a map
+	{testinput{"parse 9", "./testdata", "map"}, testoutput{"success", *mapCfg}},
 }
 
 func init() {
@@ -97,6 +121,39 @@ func TestConfig_Parse(t *testing.T) {
 					test.expected.error,
 					"nil")
 			}
+			if test.expected.error == "invalid" {
+				if _, ok := err.(*config.InvalidError); !ok {
+					fail(t, test, fmt.Sprintf("%+v", test.in), "InvalidError", fmt.Sprintf("%v", err))
+				}
+			}
+			if test.expected.cfg != *cfg { // fail if defaults not kept
+				fail(t, test, fmt.Sprintf("%+v", test.in),
+					fmt.Sprintf("%+v", test.expected.cfg),
+					fmt.Sprintf("%+v", *cfg))
+			}
+		}
+	}
+}
+
+func TestConfig_Validate_EtcdEndpoints(t *testing.T) {
+	tests := []struct {
+		endpoints string
+		valid     bool
+	}{
+		{"http://127.0.0.1:2379", true},
+		{"https://etcd-0.etcd:2379,https://etcd-1.etcd:2379", true},
+		{"127.0.0.1:2379", true},
+		{"etcd:2379, [::1]:2379", true},
+		{"127.0.0.1", false},
+		{"http://", false},
+		{":2379", false},
+	}
+	for _, test := range tests {
+		cfg := config.NewConfig()
+		cfg.EtcdEndpoints = test.endpoints
+		err := cfg.Validate()
+		if (err == nil) != test.valid {
+			t.Errorf("%s\nExpected valid: %v\nGot: %v", test.endpoints, test.valid, err)
 		}
 	}
 }
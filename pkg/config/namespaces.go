@@ -0,0 +1,70 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// NamespaceScope is the set of namespaces stargazer watches, from the Namespaces and
+// NamespaceSelector settings.
+type NamespaceScope struct {
+	// Namespaces are the listed namespaces, empty for all namespaces.
+	Namespaces sets.String
+	// Selector selects the namespaces by their labels, nil for all namespaces.
+	Selector labels.Selector
+}
+
+// NamespaceScope parses the Namespaces and NamespaceSelector settings.
+func (c *Config) NamespaceScope() (*NamespaceScope, error) {
+	s := &NamespaceScope{Namespaces: sets.NewString()}
+	for _, ns := range strings.Split(c.Namespaces, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			s.Namespaces.Insert(ns)
+		}
+	}
+	if c.NamespaceSelector != "" {
+		selector, err := labels.Parse(c.NamespaceSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace selector %q: %v", c.NamespaceSelector, err)
+		}
+		s.Selector = selector
+	}
+	return s, nil
+}
+
+// Single returns the namespace when a single namespace is listed and no selector is set, in which
+// case listing that namespace is enough. Returns metav1.NamespaceAll otherwise.
+func (s *NamespaceScope) Single() string {
+	if s.Namespaces.Len() == 1 && s.Selector == nil {
+		return s.Namespaces.List()[0]
+	}
+	return metav1.NamespaceAll
+}
+
+// Listed returns true if the namespace is listed, or no namespaces are.
+func (s *NamespaceScope) Listed(namespace string) bool {
+	return s.Namespaces.Len() == 0 || s.Namespaces.Has(namespace)
+}
+
+// Matches returns true if a namespace with the labels is in scope.
+func (s *NamespaceScope) Matches(namespace string, nsLabels map[string]string) bool {
+	return s.Listed(namespace) && (s.Selector == nil || s.Selector.Matches(labels.Set(nsLabels)))
+}
@@ -0,0 +1,273 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"github.com/nimbess/stargazer/pkg/model"
+	log "github.com/sirupsen/logrus"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+var (
+	controllersType = reflect.TypeOf(Controllers{})
+	durationType    = reflect.TypeOf(time.Duration(0))
+)
+
+// ControllerName returns the name of the Controllers field of a controller, matched case insensitively.
+func ControllerName(name string) (string, bool) {
+	for i := 0; i < controllersType.NumField(); i++ {
+		if strings.EqualFold(controllersType.Field(i).Name, name) {
+			return controllersType.Field(i).Name, true
+		}
+	}
+	return "", false
+}
+
+// ParseControllers returns the controllers enabled by a comma separated list of controller names.
+func ParseControllers(list string) (Controllers, error) {
+	var c Controllers
+	v := reflect.ValueOf(&c).Elem()
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		field, ok := ControllerName(name)
+		if !ok {
+			var supported []string
+			for i := 0; i < controllersType.NumField(); i++ {
+				supported = append(supported, strings.ToLower(controllersType.Field(i).Name))
+			}
+			return c, fmt.Errorf("unsupported controller %q, supported controllers: %s",
+				name, strings.Join(supported, ", "))
+		}
+		v.FieldByName(field).SetBool(true)
+	}
+	return c, nil
+}
+
+// controllersHook decodes a comma separated list of controller names into Controllers.
+func controllersHook(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+	if t != controllersType || f.Kind() != reflect.String {
+		return data, nil
+	}
+	return ParseControllers(data.(string))
+}
+
+// durationHook decodes durations, which need a unit. Numbers other than 0 are rejected instead
+// of being read as nanoseconds.
+func durationHook(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+	if t != durationType || f == durationType {
+		return data, nil
+	}
+	switch f.Kind() {
+	case reflect.String:
+		d, err := time.ParseDuration(data.(string))
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration %q, expected e.g. 500ms or 10s", data)
+		}
+		return d, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
+		if reflect.ValueOf(data).Convert(reflect.TypeOf(float64(0))).Float() != 0 {
+			return nil, fmt.Errorf("duration %v has no unit, expected e.g. %vs", data, data)
+		}
+		return time.Duration(0), nil
+	}
+	return data, nil
+}
+
+// Validate checks the values of the configuration, returning all the invalid settings.
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(setting string, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", setting, fmt.Sprintf(format, args...)))
+	}
+
+	if _, err := log.ParseLevel(c.LogLevel); err != nil {
+		invalid("LogLevel", "unsupported level %q", c.LogLevel)
+	}
+	if c.UNPWorkers < 1 {
+		invalid("UNPWorkers", "must be at least 1, got %d", c.UNPWorkers)
+	}
+	if c.ShutdownGracePeriod < 0 {
+		invalid("ShutdownGracePeriod", "must not be negative, got %s", c.ShutdownGracePeriod)
+	}
+	if c.ResyncPeriod < 0 {
+		invalid("ResyncPeriod", "must not be negative, got %d", c.ResyncPeriod)
+	}
+	if _, err := labels.Parse(c.NamespaceSelector); err != nil {
+		invalid("NamespaceSelector", "%v", err)
+	}
+	if _, err := labels.Parse(c.LabelSelector); err != nil {
+		invalid("LabelSelector", "%v", err)
+	}
+
+	switch c.Datastore {
+	case DatastoreEtcd:
+		if c.EtcdEndpoints == "" {
+			invalid("EtcdEndpoints", "required for the etcd datastore")
+		}
+		for _, ep := range strings.Split(c.EtcdEndpoints, ",") {
+			if ep = strings.TrimSpace(ep); ep == "" {
+				continue
+			}
+			if !validEndpoint(ep) {
+				invalid("EtcdEndpoints", "invalid endpoint %q, expected e.g. http://127.0.0.1:2379 or 127.0.0.1:2379", ep)
+			}
+		}
+	case DatastoreKubernetes:
+		if c.DatastoreNamespace == "" {
+			invalid("DatastoreNamespace", "required for the kubernetes datastore")
+		}
+	default:
+		invalid("Datastore", "unsupported datastore %q, expected %s or %s", c.Datastore, DatastoreEtcd, DatastoreKubernetes)
+	}
+	if c.EtcdDialTimeout <= 0 {
+		invalid("EtcdDialTimeout", "must be positive, got %s", c.EtcdDialTimeout)
+	}
+	if c.EtcdLeaseTTL < 0 || (c.EtcdLeaseTTL > 0 && c.EtcdLeaseTTL < time.Second) {
+		invalid("EtcdLeaseTTL", "must be 0 or at least 1s, got %s", c.EtcdLeaseTTL)
+	}
+	if c.EtcdBatchWindow < 0 {
+		invalid("EtcdBatchWindow", "must not be negative, got %s", c.EtcdBatchWindow)
+	}
+	if c.EtcdBatchMaxOps < 1 {
+		invalid("EtcdBatchMaxOps", "must be at least 1, got %d", c.EtcdBatchMaxOps)
+	}
+	if (c.EtcdCertFile == "") != (c.EtcdKeyFile == "") {
+		invalid("EtcdCertFile", "EtcdCertFile and EtcdKeyFile must be set together")
+	}
+	if c.EtcdPassword != "" && c.EtcdUsername == "" {
+		invalid("EtcdUsername", "required with EtcdPassword")
+	}
+	switch c.EtcdValueCodec {
+	case model.CodecJSON, model.CodecProtobuf:
+	default:
+		invalid("EtcdValueCodec", "unsupported codec %q, expected %s or %s", c.EtcdValueCodec, model.CodecJSON, model.CodecProtobuf)
+	}
+	switch c.EtcdCompression {
+	case model.CompressionNone, model.CompressionGzip:
+	default:
+		invalid("EtcdCompression", "unsupported compression %q, expected %s or %s",
+			c.EtcdCompression, model.CompressionNone, model.CompressionGzip)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// InvalidError is returned by Parse for a configuration file that can't be decoded or holds invalid values.
+type InvalidError struct {
+	File     string
+	Problems []string
+}
+
+func (e *InvalidError) Error() string {
+	return fmt.Sprintf("invalid config %s: %s", e.File, strings.Join(e.Problems, "; "))
+}
+
+// newInvalidError lists the problems reported by the decoder or by Validate.
+func newInvalidError(file string, err error) *InvalidError {
+	e := &InvalidError{File: file}
+	switch err := err.(type) {
+	case *mapstructure.Error:
+		for _, msg := range err.Errors {
+			if strings.HasPrefix(msg, unknownKeysPrefix) {
+				msg = unknownSettings(strings.Split(strings.TrimPrefix(msg, unknownKeysPrefix), ", "))
+			}
+			e.Problems = append(e.Problems, msg)
+		}
+	case utilerrors.Aggregate:
+		for _, err := range err.Errors() {
+			e.Problems = append(e.Problems, err.Error())
+		}
+	default:
+		e.Problems = []string{err.Error()}
+	}
+	return e
+}
+
+// unknownKeysPrefix starts the decoder error listing the keys that don't match a setting
+const unknownKeysPrefix = "'' has invalid keys: "
+
+// unknownSettings describes the unknown keys, suggesting the closest setting for likely typos.
+func unknownSettings(keys []string) string {
+	t := reflect.TypeOf(Config{})
+	for i, key := range keys {
+		best, bestDist := "", 3
+		for j := 0; j < t.NumField(); j++ {
+			name := t.Field(j).Name
+			if d := editDistance(key, strings.ToLower(name)); d < bestDist {
+				best, bestDist = name, d
+			}
+		}
+		if best != "" {
+			keys[i] = fmt.Sprintf("%s (did you mean %s?)", key, best)
+		}
+	}
+	return "unknown settings: " + strings.Join(keys, ", ")
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// validEndpoint returns true for the etcd endpoints given as URLs or, as accepted by the etcd
+// client, as host:port.
+func validEndpoint(ep string) bool {
+	if u, err := url.Parse(ep); err == nil && u.Host != "" {
+		return true
+	}
+	host, port, err := net.SplitHostPort(ep)
+	if err != nil || host == "" {
+		return false
+	}
+	_, err = strconv.ParseUint(port, 10, 16)
+	return err == nil
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/cache"
+)
+
+// dispatcher is the only event handler registered with the shared informer of a controller. It
+// forwards the events to the controller currently running for the informer, and requeues the
+// controllers reading its objects. Restarted controllers replace the previous ones in the
+// dispatcher instead of registering further event handlers with the informer.
+type dispatcher struct {
+	name       string
+	lock       sync.RWMutex
+	controller *Controller
+	// dependents are the controllers reading the objects of the informer, by name
+	dependents map[string]*Controller
+}
+
+// dispatcher returns the dispatcher of the informer of a controller, registering it with the
+// informer on first use.
+func (i *Informers) dispatcher(name string) *dispatcher {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if d, ok := i.dispatchers[name]; ok {
+		return d
+	}
+	d := &dispatcher{name: name, dependents: map[string]*Controller{}}
+	informerFuncs[name](i).AddEventHandler(cache.FilteringResourceEventHandler{FilterFunc: i.InScope, Handler: d})
+	i.dispatchers[name] = d
+	return d
+}
+
+// release removes a stopped controller from all dispatchers, its events are dropped from then on.
+func (i *Informers) release(name string, c *Controller) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	for _, d := range i.dispatchers {
+		d.lock.Lock()
+		if d.controller == c {
+			d.controller = nil
+		}
+		if d.dependents[name] == c {
+			delete(d.dependents, name)
+		}
+		d.lock.Unlock()
+	}
+}
+
+// setController sets the controller the events of the informer are queued to.
+func (d *dispatcher) setController(c *Controller) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.controller = c
+}
+
+// addDependent requeues all objects of the controller when objects of the informer are created,
+// deleted or their spec changed.
+func (d *dispatcher) addDependent(name string, c *Controller) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.dependents[name] = c
+}
+
+// OnAdd is required for the cache.ResourceEventHandler interface.
+func (d *dispatcher) OnAdd(obj interface{}) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	if d.controller != nil {
+		d.controller.enqueue("create", obj)
+	}
+	d.requeueDependents()
+}
+
+// OnUpdate is required for the cache.ResourceEventHandler interface.
+func (d *dispatcher) OnUpdate(oldObj, newObj interface{}) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	if d.controller != nil {
+		d.controller.enqueue("update", oldObj)
+	}
+	if specChanged(oldObj, newObj) {
+		d.requeueDependents()
+	}
+}
+
+// OnDelete is required for the cache.ResourceEventHandler interface.
+func (d *dispatcher) OnDelete(obj interface{}) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	if d.controller != nil {
+		d.controller.enqueue("delete", obj)
+	}
+	d.requeueDependents()
+}
+
+// requeueNamespace queues an event for the objects of a namespace moved in or out of scope, and
+// requeues the dependent controllers.
+func (d *dispatcher) requeueNamespace(eventType string, objs []interface{}) {
+	if len(objs) == 0 {
+		return
+	}
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	if d.controller != nil {
+		for _, obj := range objs {
+			d.controller.enqueue(eventType, obj)
+		}
+	}
+	d.requeueDependents()
+}
+
+// requeueDependents requeues all objects of the dependent controllers. Must be called with the
+// lock held.
+func (d *dispatcher) requeueDependents() {
+	for _, c := range d.dependents {
+		c.logger.Debugf("%s changed, requeueing all objects", d.name)
+		c.requeueAll()
+	}
+}
+
+// specChanged returns true if the generation of an object changed. Objects without generation,
+// e.g. Services, are compared by resource version.
+func specChanged(oldObj, newObj interface{}) bool {
+	oldMeta, err := meta.Accessor(oldObj)
+	if err != nil {
+		return false
+	}
+	newMeta, err := meta.Accessor(newObj)
+	if err != nil {
+		return false
+	}
+	return oldMeta.GetGeneration() != newMeta.GetGeneration() ||
+		newMeta.GetGeneration() == 0 && oldMeta.GetResourceVersion() != newMeta.GetResourceVersion()
+}
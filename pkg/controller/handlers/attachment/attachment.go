@@ -0,0 +1,179 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package attachment publishes the Multus NetworkAttachmentDefinitions as Nimbess networks and the
+// interfaces Multus attached to the pods, so that policies can apply to a single interface.
+package attachment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	"github.com/nimbess/stargazer/pkg/config"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/model"
+	log "github.com/sirupsen/logrus"
+	"path"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+// GroupVersionResource of the Multus NetworkAttachmentDefinitions, watched with the dynamic client.
+var GroupVersionResource = schema.GroupVersionResource{
+	Group:    "k8s.cni.cncf.io",
+	Version:  "v1",
+	Resource: "network-attachment-definitions",
+}
+
+// cniConfig holds the fields of a CNI configuration, or configuration list, published with the
+// network. Plugins not setting them are ignored.
+type cniConfig struct {
+	Type    string      `json:"type"`
+	VLAN    int         `json:"vlan"`
+	VLANID  int         `json:"vlanId"`
+	IPAM    ipamConfig  `json:"ipam"`
+	Plugins []cniConfig `json:"plugins"`
+}
+
+type ipamConfig struct {
+	Subnet string `json:"subnet"`
+	Ranges [][]struct {
+		Subnet string `json:"subnet"`
+	} `json:"ranges"`
+}
+
+// NetworkAttachment writes the NetworkAttachmentDefinitions as NetworkAttachmentKeys.
+type NetworkAttachment struct {
+	etcdClient etcdv3.Client
+	ctx        context.Context
+}
+
+// Init initializes handler configuration
+func (a *NetworkAttachment) Init(c *config.Config, etcdClient etcdv3.Client, ctx context.Context) error {
+	a.etcdClient = etcdClient
+	a.ctx = ctx
+	return nil
+}
+
+// ObjectCreated writes the network attachment to Nimbess etcd
+func (a *NetworkAttachment) ObjectCreated(obj interface{}) error {
+	nad := obj.(*unstructured.Unstructured)
+	log.Debugf("Network attachment created: %s/%s", nad.GetNamespace(), nad.GetName())
+	kv, err := a.K8sToNimbess(nad)
+	if err != nil {
+		log.WithError(err).Warnf("Invalid network attachment %s/%s, not written to Nimbess etcd",
+			nad.GetNamespace(), nad.GetName())
+		return remove(a.ctx, a.etcdClient, model.NetworkAttachmentKey{Namespace: nad.GetNamespace(), Name: nad.GetName()})
+	}
+	return put(a.ctx, a.etcdClient, kv)
+}
+
+// ObjectDeleted deletes the network attachment from Nimbess etcd
+func (a *NetworkAttachment) ObjectDeleted(name string) error {
+	log.Debugf("Network attachment deleted: %s", name)
+	namespace, name, err := cache.SplitMetaNamespaceKey(name)
+	if err != nil {
+		log.WithError(err).Errorf("Invalid network attachment key: %s", name)
+		return nil
+	}
+	return remove(a.ctx, a.etcdClient, model.NetworkAttachmentKey{Namespace: namespace, Name: name})
+}
+
+// ObjectUpdated writes the updated network attachment to Nimbess etcd
+func (a *NetworkAttachment) ObjectUpdated(oldObj, newObj interface{}) error {
+	return a.ObjectCreated(newObj)
+}
+
+// TestHandler tests the handler configuration
+func (a *NetworkAttachment) TestHandler() {
+
+}
+
+// K8sToNimbess translates a NetworkAttachmentDefinition into a Nimbess Key/Value Pair to be written
+// into ETCD. The data plane is the CNI plugin type, the CIDRs are the IPAM subnets. Definitions
+// without configuration, whose configuration is read from the nodes, only have a name.
+func (a *NetworkAttachment) K8sToNimbess(nad *unstructured.Unstructured) (*model.KVPair, error) {
+	network := &model.Network{Name: path.Join(nad.GetNamespace(), nad.GetName())}
+	raw, _, err := unstructured.NestedString(nad.Object, "spec", "config")
+	if err != nil {
+		return nil, err
+	}
+	if raw != "" {
+		var conf cniConfig
+		if err := json.Unmarshal([]byte(raw), &conf); err != nil {
+			return nil, fmt.Errorf("invalid CNI configuration: %v", err)
+		}
+		for _, c := range append([]cniConfig{conf}, conf.Plugins...) {
+			if network.DataPlane == "" {
+				network.DataPlane = c.Type
+			}
+			if network.Vlan == 0 {
+				network.Vlan = int32(c.VLAN + c.VLANID)
+			}
+			if c.IPAM.Subnet != "" {
+				network.Cidrs = append(network.Cidrs, c.IPAM.Subnet)
+			}
+			for _, set := range c.IPAM.Ranges {
+				for _, r := range set {
+					network.Cidrs = append(network.Cidrs, r.Subnet)
+				}
+			}
+		}
+	}
+	key := model.NetworkAttachmentKey{Namespace: nad.GetNamespace(), Name: nad.GetName()}
+	return &model.KVPair{Key: key, Value: network}, nil
+}
+
+// put writes the key unless it already holds the value, pods are updated far more often than
+// their interfaces change.
+func put(ctx context.Context, etcdClient etcdv3.Client, kv *model.KVPair) error {
+	current, err := etcdClient.Get(ctx, kv.Key)
+	if err == nil {
+		if proto.Equal(current.Value.(proto.Message), kv.Value.(proto.Message)) {
+			return nil
+		}
+		err = etcdClient.Update(ctx, kv)
+	} else if etcdv3.IsNotFound(err) {
+		err = etcdClient.Create(ctx, kv)
+		if etcdv3.IsExists(err) {
+			err = etcdClient.Update(ctx, kv)
+		}
+	}
+	if err != nil {
+		log.Errorf("Failed to write to Nimbess etcd: %v, error: %v", kv.Key, err)
+		if etcdv3.IsRetriable(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// remove deletes the key, keys already deleted are ignored.
+func remove(ctx context.Context, etcdClient etcdv3.Client, k model.Key) error {
+	err := etcdClient.Delete(ctx, k)
+	if etcdv3.IsNotFound(err) {
+		log.Debugf("Key already deleted from Nimbess etcd: %v", k)
+		return nil
+	}
+	if err != nil {
+		log.Errorf("Failed to delete key from Nimbess etcd: %v, error: %v", k, err)
+		if etcdv3.IsRetriable(err) {
+			return err
+		}
+	}
+	return nil
+}
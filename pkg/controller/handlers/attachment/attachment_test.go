@@ -0,0 +1,169 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attachment_test
+
+import (
+	"context"
+	"github.com/nimbess/stargazer/pkg/config"
+	"github.com/nimbess/stargazer/pkg/controller/handlers/attachment"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/model"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type attachmenttest struct {
+	testName  string
+	config    string
+	cidrs     []string
+	vlan      int32
+	dataPlane string
+	valid     bool
+}
+
+var attachmentTests = []attachmenttest{
+	// pass: macvlan with a single subnet
+	{"attachment 1", `{"cniVersion":"0.3.1","type":"macvlan","master":"eth1","ipam":{"type":"host-local","subnet":"10.1.0.0/24"}}`,
+		[]string{"10.1.0.0/24"}, 0, "macvlan", true},
+	// pass: configuration list with a VLAN and IPAM ranges
+	{"attachment 2", `{"cniVersion":"0.3.1","plugins":[{"type":"vlan","vlanId":42,"ipam":{"type":"whereabouts",` +
+		`"ranges":[[{"subnet":"10.2.0.0/24"}],[{"subnet":"fd00:2::/64"}]]}},{"type":"tuning"}]}`,
+		[]string{"10.2.0.0/24", "fd00:2::/64"}, 42, "vlan", true},
+	// pass: configuration read from the nodes
+	{"attachment 3", "", nil, 0, "", true},
+	// fail: invalid JSON
+	{"attachment 4", `{"type":`, nil, 0, "", false},
+}
+
+func newAttachment(namespace, name, config string) *unstructured.Unstructured {
+	nad := &unstructured.Unstructured{}
+	nad.SetAPIVersion("k8s.cni.cncf.io/v1")
+	nad.SetKind("NetworkAttachmentDefinition")
+	nad.SetNamespace(namespace)
+	nad.SetName(name)
+	if config != "" {
+		_ = unstructured.SetNestedField(nad.Object, config, "spec", "config")
+	}
+	return nad
+}
+
+func TestNetworkAttachment_K8sToNimbess(t *testing.T) {
+	h := &attachment.NetworkAttachment{}
+	for _, test := range attachmentTests {
+		kv, err := h.K8sToNimbess(newAttachment("default", "secondary", test.config))
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %v, got %v", test.testName, test.valid, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		n := kv.Value.(*model.Network)
+		if n.Name != "default/secondary" || !reflect.DeepEqual(n.Cidrs, test.cidrs) || n.Vlan != test.vlan ||
+			n.DataPlane != test.dataPlane {
+			t.Errorf("%s\nExpected: %v, %d, %s\nGot: %+v", test.testName, test.cidrs, test.vlan, test.dataPlane, n)
+		}
+	}
+}
+
+func TestPodNetwork_ObjectUpdated(t *testing.T) {
+	store := etcdv3.NewMemoryClient()
+	h := &attachment.PodNetwork{}
+	if err := h.Init(config.NewConfig(), store, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-0",
+		Annotations: map[string]string{attachment.NetworkStatusAnnotation: `[` +
+			`{"name":"cbr0","interface":"eth0","ips":["10.244.1.5"],"default":true},` +
+			`{"name":"macvlan","interface":"net1","ips":["10.1.0.5"],"mac":"0a:58:0a:01:00:05"},` +
+			`{"name":"kube-system/vlan42","interface":"net2","ips":["10.2.0.5"]}]`}}}
+	if err := h.ObjectCreated(pod); err != nil {
+		t.Fatal(err)
+	}
+	kv, err := store.Get(context.Background(), model.PodInterfaceKey{Namespace: "default", Pod: "web-0", Interface: "net1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := kv.Value.(*model.PodInterface); got.Network != "default/macvlan" || got.Mac != "0a:58:0a:01:00:05" {
+		t.Errorf("Unexpected value written: %+v", got)
+	}
+
+	// interfaces no longer in the status are removed
+	pod.Annotations = map[string]string{attachment.LegacyNetworkStatusAnnotation: `[` +
+		`{"name":"cbr0","interface":"eth0","ips":["10.244.1.5"],"default":true}]`}
+	if err := h.ObjectUpdated(nil, pod); err != nil {
+		t.Fatal(err)
+	}
+	list, err := store.List(context.Background(), model.PodInterfaceListOptions{Namespace: "default", Pod: "web-0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.KVPairs) != 1 || list.KVPairs[0].Key.(model.PodInterfaceKey).Interface != "eth0" {
+		t.Errorf("Expected only eth0 to be left, got %v", list.KVPairs)
+	}
+
+	if err := h.ObjectDeleted("default/web-0"); err != nil {
+		t.Fatal(err)
+	}
+	list, err = store.List(context.Background(), model.PodInterfaceListOptions{Namespace: "default", Pod: "web-0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.KVPairs) != 0 {
+		t.Errorf("Expected all interfaces to be deleted, got %v", list.KVPairs)
+	}
+}
+
+// countingClient counts the List requests.
+type countingClient struct {
+	etcdv3.Client
+	lists int
+}
+
+func (c *countingClient) List(ctx context.Context, l model.ListInterface) (*model.KVPairList, error) {
+	c.lists++
+	return c.Client.List(ctx, l)
+}
+
+func TestPodNetwork_ObjectCreated_Published(t *testing.T) {
+	store := &countingClient{Client: etcdv3.NewMemoryClient()}
+	// an interface written by a previous run for a pod that lost it since
+	stale := &model.KVPair{Key: model.PodInterfaceKey{Namespace: "default", Pod: "web-0", Interface: "net1"},
+		Value: &model.PodInterface{Namespace: "default", Pod: "web-0", Interface: "net1"}}
+	if err := store.Create(context.Background(), stale); err != nil {
+		t.Fatal(err)
+	}
+	h := &attachment.PodNetwork{}
+	if err := h.Init(config.NewConfig(), store, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"web-0", "web-1", "web-2"} {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+		if err := h.ObjectCreated(pod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := store.Get(context.Background(), stale.Key); !etcdv3.IsNotFound(err) {
+		t.Errorf("Expected the stale interface to be deleted, got %v", err)
+	}
+	if store.lists != 1 {
+		t.Errorf("Expected the published interfaces to be listed once, got %d lists", store.lists)
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attachment
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/nimbess/stargazer/pkg/config"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/model"
+	log "github.com/sirupsen/logrus"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Annotations set by Multus on the pods with the status of their interfaces. The former is set by
+// the older releases of Multus.
+const (
+	NetworkStatusAnnotation       = "k8s.v1.cni.cncf.io/network-status"
+	LegacyNetworkStatusAnnotation = "k8s.v1.cni.cncf.io/networks-status"
+)
+
+// NetworkStatus is an element of the network-status annotation.
+type NetworkStatus struct {
+	Name      string   `json:"name"`
+	Interface string   `json:"interface"`
+	IPs       []string `json:"ips"`
+	Mac       string   `json:"mac"`
+	Default   bool     `json:"default"`
+}
+
+// PodNetwork writes the interfaces of the pods as PodInterfaceKeys.
+type PodNetwork struct {
+	etcdClient etcdv3.Client
+	ctx        context.Context
+
+	// published holds the interfaces written for each pod by namespace/name. It is listed from
+	// Nimbess etcd once, so that the events of pods without interfaces need no request.
+	lock      sync.Mutex
+	published map[string]map[model.Key]bool
+}
+
+// Init initializes handler configuration
+func (p *PodNetwork) Init(c *config.Config, etcdClient etcdv3.Client, ctx context.Context) error {
+	p.etcdClient = etcdClient
+	p.ctx = ctx
+	return nil
+}
+
+// ObjectCreated writes the interfaces of the pod to Nimbess etcd and removes those it no longer has
+func (p *PodNetwork) ObjectCreated(obj interface{}) error {
+	pod := obj.(*corev1.Pod)
+	kvs, err := p.K8sToNimbess(pod)
+	if err != nil {
+		log.WithError(err).Warnf("Invalid network status of pod %s/%s, interfaces not written to Nimbess etcd",
+			pod.Namespace, pod.Name)
+	}
+	if err := p.loadPublished(); err != nil {
+		return err
+	}
+	name := pod.Namespace + "/" + pod.Name
+	written := map[model.Key]bool{}
+	for _, kv := range kvs {
+		if err := put(p.ctx, p.etcdClient, kv); err != nil {
+			p.addPublished(name, written)
+			return err
+		}
+		written[kv.Key] = true
+	}
+	return p.removeInterfaces(name, written)
+}
+
+// ObjectDeleted deletes the interfaces of the pod from Nimbess etcd
+func (p *PodNetwork) ObjectDeleted(name string) error {
+	if _, _, err := cache.SplitMetaNamespaceKey(name); err != nil {
+		log.WithError(err).Errorf("Invalid pod key: %s", name)
+		return nil
+	}
+	if err := p.loadPublished(); err != nil {
+		return err
+	}
+	return p.removeInterfaces(name, nil)
+}
+
+// ObjectUpdated writes the interfaces of the updated pod to Nimbess etcd
+func (p *PodNetwork) ObjectUpdated(oldObj, newObj interface{}) error {
+	return p.ObjectCreated(newObj)
+}
+
+// TestHandler tests the handler configuration
+func (p *PodNetwork) TestHandler() {
+
+}
+
+// K8sToNimbess translates the network status of a pod into a Nimbess Key/Value Pair per interface
+// to be written into ETCD. The networks of the attachments are qualified with the namespace of the
+// pod when Multus omitted it.
+func (p *PodNetwork) K8sToNimbess(pod *corev1.Pod) ([]*model.KVPair, error) {
+	raw, ok := pod.Annotations[NetworkStatusAnnotation]
+	if !ok {
+		raw, ok = pod.Annotations[LegacyNetworkStatusAnnotation]
+	}
+	if !ok || raw == "" {
+		return nil, nil
+	}
+	var statuses []NetworkStatus
+	if err := json.Unmarshal([]byte(raw), &statuses); err != nil {
+		return nil, err
+	}
+
+	var kvs []*model.KVPair
+	for _, s := range statuses {
+		if s.Interface == "" {
+			// the interface name is only missing with Multus releases older than the annotation
+			continue
+		}
+		network := s.Name
+		if !s.Default && !strings.Contains(network, "/") {
+			network = pod.Namespace + "/" + network
+		}
+		kvs = append(kvs, &model.KVPair{
+			Key: model.PodInterfaceKey{Namespace: pod.Namespace, Pod: pod.Name, Interface: s.Interface},
+			Value: &model.PodInterface{
+				Namespace: pod.Namespace,
+				Pod:       pod.Name,
+				Network:   network,
+				Interface: s.Interface,
+				Ips:       s.IPs,
+				Mac:       s.Mac,
+				Default:   s.Default,
+			},
+		})
+	}
+	return kvs, nil
+}
+
+// loadPublished lists the interfaces written by the previous runs, once.
+func (p *PodNetwork) loadPublished() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.published != nil {
+		return nil
+	}
+	list, err := p.etcdClient.List(p.ctx, model.PodInterfaceListOptions{})
+	if err != nil {
+		log.Errorf("Failed to list pod interfaces in Nimbess etcd: %v", err)
+		return err
+	}
+	p.published = map[string]map[model.Key]bool{}
+	for _, kv := range list.KVPairs {
+		k := kv.Key.(model.PodInterfaceKey)
+		name := k.Namespace + "/" + k.Pod
+		if p.published[name] == nil {
+			p.published[name] = map[model.Key]bool{}
+		}
+		p.published[name][k] = true
+	}
+	return nil
+}
+
+// addPublished records interfaces written for a pod.
+func (p *PodNetwork) addPublished(name string, keys map[model.Key]bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for k := range keys {
+		if p.published[name] == nil {
+			p.published[name] = map[model.Key]bool{}
+		}
+		p.published[name][k] = true
+	}
+}
+
+// removeInterfaces deletes the published interfaces of the pod except those kept, which are
+// recorded as the interfaces of the pod.
+func (p *PodNetwork) removeInterfaces(name string, keep map[model.Key]bool) error {
+	p.lock.Lock()
+	var stale []model.Key
+	for k := range p.published[name] {
+		if !keep[k] {
+			stale = append(stale, k)
+		}
+	}
+	delete(p.published, name)
+	p.lock.Unlock()
+
+	p.addPublished(name, keep)
+	for i, k := range stale {
+		if err := remove(p.ctx, p.etcdClient, k); err != nil {
+			// keep the interfaces left for the retry
+			for _, left := range stale[i:] {
+				p.addPublished(name, map[model.Key]bool{left: true})
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -16,25 +16,69 @@ package handlers
 
 import (
 	"context"
+	nimbessclientset "github.com/nimbess/stargazer/pkg/client/clientset/versioned"
+	unpinformer "github.com/nimbess/stargazer/pkg/client/informers/externalversions"
 	"github.com/nimbess/stargazer/pkg/config"
+	"github.com/nimbess/stargazer/pkg/controller/handlers/attachment"
+	"github.com/nimbess/stargazer/pkg/controller/handlers/ingress"
+	"github.com/nimbess/stargazer/pkg/controller/handlers/network"
+	"github.com/nimbess/stargazer/pkg/controller/handlers/service"
 	"github.com/nimbess/stargazer/pkg/controller/handlers/unp"
 	"github.com/nimbess/stargazer/pkg/etcdv3"
+
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
 // Handler is implemented by any handler.
-// The Handle method is used to process event
+// The Handle method is used to process event. Errors returned by the Object methods
+// cause the event to be retried. The queue of the controller only holds the keys of the objects,
+// ObjectUpdated is called with the current object and a nil old object.
 type Handler interface {
 	Init(c *config.Config, etcdClient etcdv3.Client, ctx context.Context) error
-	ObjectCreated(obj interface{})
-	ObjectDeleted(name string)
-	ObjectUpdated(oldObj, newObj interface{})
+	ObjectCreated(obj interface{}) error
+	ObjectDeleted(name string) error
+	ObjectUpdated(oldObj, newObj interface{}) error
 	TestHandler()
 }
 
+// CacheHandler is implemented by handlers reading the informer cache of their controller, e.g. to
+// write several objects into a single key. The cache is set before the controller is started.
+type CacheHandler interface {
+	SetCache(indexer cache.Indexer)
+}
+
+// ClientHandler is implemented by handlers reading or updating other Nimbess resources than the
+// one of their controller. The client is set before the controller is started, the listers
+// obtained from the factory are started with it.
+type ClientHandler interface {
+	SetClient(client nimbessclientset.Interface, factory unpinformer.SharedInformerFactory)
+}
+
+// CoreHandler is implemented by handlers reading Kubernetes resources other than the one of their
+// controller. The factory is set before the controller is started, the listers obtained from it
+// are started with it.
+type CoreHandler interface {
+	SetCore(factory informers.SharedInformerFactory)
+}
+
+// DynamicHandler is implemented by handlers reading resources of other projects, watched with the
+// dynamic client. The factory is set before the controller is started.
+type DynamicHandler interface {
+	SetDynamic(factory dynamicinformer.DynamicSharedInformerFactory)
+}
+
 // Map maps each event handler function to a name for easily lookup
 var Map = map[string]Handler{
-	"default": &Default{},
-	"UNP":     &unp.UNP{},
+	"default":           &Default{},
+	"UNP":               &unp.UNP{},
+	"Service":           &service.Service{},
+	"EndpointSlice":     &service.EndpointSlice{},
+	"Ingress":           &ingress.Ingress{},
+	"Network":           &network.Network{},
+	"NetworkAttachment": &attachment.NetworkAttachment{},
+	"PodNetwork":        &attachment.PodNetwork{},
 }
 
 // Default handler implements Handler interface,
@@ -49,18 +93,18 @@ func (d *Default) Init(c *config.Config, etcdClient etcdv3.Client, ctx context.C
 }
 
 // ObjectCreated sends events on object creation
-func (d *Default) ObjectCreated(obj interface{}) {
-
+func (d *Default) ObjectCreated(obj interface{}) error {
+	return nil
 }
 
 // ObjectDeleted sends events on object deletion
-func (d *Default) ObjectDeleted(name string) {
-
+func (d *Default) ObjectDeleted(name string) error {
+	return nil
 }
 
 // ObjectUpdated sends events on object updation
-func (d *Default) ObjectUpdated(oldObj, newObj interface{}) {
-
+func (d *Default) ObjectUpdated(oldObj, newObj interface{}) error {
+	return nil
 }
 
 // TestHandler tests the handler configurarion by sending test messages.
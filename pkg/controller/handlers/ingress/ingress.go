@@ -0,0 +1,239 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ingress derives L7 allow-lists from the host and path rules of the Ingress objects.
+package ingress
+
+import (
+	"context"
+	"github.com/nimbess/stargazer/pkg/config"
+	"github.com/nimbess/stargazer/pkg/controller/handlers/unp"
+	unpv1 "github.com/nimbess/stargazer/pkg/crd/api/unp/v1"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/model"
+	"github.com/nimbess/stargazer/pkg/model/node"
+	log "github.com/sirupsen/logrus"
+	"path"
+	"sort"
+	"strings"
+
+	networking "k8s.io/api/networking/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/informers"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Ingress writes a policy for each backend Service of an Ingress, allowing the hosts and paths
+// routed to the pods of the Service, under the UNP prefix.
+type Ingress struct {
+	etcdClient etcdv3.Client
+	ctx        context.Context
+	services   corelisters.ServiceLister
+}
+
+// Init initializes handler configuration
+func (i *Ingress) Init(c *config.Config, etcdClient etcdv3.Client, ctx context.Context) error {
+	i.etcdClient = etcdClient
+	i.ctx = ctx
+	return nil
+}
+
+// SetCore sets the lister of the Services whose selectors select the pods of the policies.
+func (i *Ingress) SetCore(factory informers.SharedInformerFactory) {
+	i.services = factory.Core().V1().Services().Lister()
+}
+
+// ObjectCreated writes the policies of the Ingress to Nimbess etcd and deletes those of the
+// Services it no longer routes to.
+func (i *Ingress) ObjectCreated(obj interface{}) error {
+	ing := obj.(*networking.Ingress)
+	log.Debugf("Ingress created: %s/%s", ing.Namespace, ing.Name)
+	kvs, err := i.K8sToNimbess(ing)
+	if err != nil {
+		log.WithError(err).Errorf("Failed to look up the services of Ingress %s/%s", ing.Namespace, ing.Name)
+		return err
+	}
+	if len(kvs) == 0 {
+		log.Infof("Ingress %s/%s has no backend service selecting pods, no policy written", ing.Namespace, ing.Name)
+	}
+	current := map[model.UNPKey]bool{}
+	for _, kv := range kvs {
+		current[kv.Key.(model.UNPKey)] = true
+		err := i.etcdClient.Create(i.ctx, kv)
+		if etcdv3.IsExists(err) {
+			err = i.etcdClient.Update(i.ctx, kv)
+		}
+		if err != nil {
+			log.Errorf("Failed to write to Nimbess etcd: %v, error: %v", kv.Key, err)
+			if etcdv3.IsRetriable(err) {
+				return err
+			}
+		}
+	}
+	return i.removePolicies(ing.Namespace, ing.Name, current)
+}
+
+// ObjectDeleted deletes the policies of the Ingress from Nimbess etcd
+func (i *Ingress) ObjectDeleted(name string) error {
+	log.Debugf("Ingress deleted: %s", name)
+	namespace, name, err := cache.SplitMetaNamespaceKey(name)
+	if err != nil {
+		log.WithError(err).Errorf("Invalid ingress key: %s", name)
+		return nil
+	}
+	return i.removePolicies(namespace, name, nil)
+}
+
+// removePolicies deletes the policies of an Ingress, except the ones to keep.
+func (i *Ingress) removePolicies(namespace, name string, keep map[model.UNPKey]bool) error {
+	list, err := i.etcdClient.List(i.ctx, model.UNPListOptions{Namespace: namespace})
+	if err != nil {
+		log.Errorf("Failed to list the policies of Ingress %s/%s, error: %v", namespace, name, err)
+		if etcdv3.IsRetriable(err) {
+			return err
+		}
+		return nil
+	}
+	prefix := model.IngressPolicyName(namespace, name, "") + "/"
+	for _, kv := range list.KVPairs {
+		k := kv.Key.(model.UNPKey)
+		if !strings.HasPrefix(k.Name, prefix) || keep[k] {
+			continue
+		}
+		err := i.etcdClient.Delete(i.ctx, k)
+		if etcdv3.IsNotFound(err) {
+			log.Debugf("Key already deleted from Nimbess etcd: %v", k)
+			continue
+		}
+		if err != nil {
+			log.Errorf("Failed to delete key from Nimbess etcd: %v, error: %v", k, err)
+			if etcdv3.IsRetriable(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ObjectUpdated writes the updated policies of the Ingress to Nimbess etcd
+func (i *Ingress) ObjectUpdated(oldObj, newObj interface{}) error {
+	return i.ObjectCreated(newObj)
+}
+
+// TestHandler tests the handler configuration
+func (i *Ingress) TestHandler() {
+
+}
+
+// backend collects the URLs an Ingress routes to a Service.
+type backend struct {
+	urls []string
+	// all is set for the default backend, which is routed all the other URLs
+	all bool
+}
+
+// K8sToNimbess translates an Ingress into a Nimbess policy for each of its backend Services,
+// selecting the pods of the Service, allowing the URLs routed to it and denying the others. Rules
+// without host match any host. The default backend of the Ingress is allowed all URLs.
+// Services that don't exist or have no selector are skipped, as are rules without paths, which
+// route to the default backend.
+func (i *Ingress) K8sToNimbess(ing *networking.Ingress) ([]*model.KVPair, error) {
+	backends := map[string]*backend{}
+	backendOf := func(service string) *backend {
+		b, ok := backends[service]
+		if !ok {
+			b = &backend{}
+			backends[service] = b
+		}
+		return b
+	}
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		host := rule.Host
+		if host == "" {
+			host = "*"
+		}
+		for _, p := range rule.HTTP.Paths {
+			b := backendOf(p.Backend.ServiceName)
+			// paths are prefixes: the path itself and everything below it
+			prefix := strings.TrimSuffix(p.Path, "/")
+			if prefix != "" {
+				b.urls = append(b.urls, host+prefix)
+			}
+			b.urls = append(b.urls, host+prefix+"/*")
+		}
+	}
+	if ing.Spec.Backend != nil {
+		backendOf(ing.Spec.Backend.ServiceName).all = true
+	}
+
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var kvs []*model.KVPair
+	for _, name := range names {
+		svc, err := i.services.Services(ing.Namespace).Get(name)
+		if apierrors.IsNotFound(err) {
+			log.Debugf("Backend service %s/%s of Ingress %s not found", ing.Namespace, name, ing.Name)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(svc.Spec.Selector) == 0 {
+			log.Debugf("Backend service %s/%s of Ingress %s has no selector", ing.Namespace, name, ing.Name)
+			continue
+		}
+		kvs = append(kvs, newPolicy(ing, name, svc.Spec.Selector, backends[name]))
+	}
+	return kvs, nil
+}
+
+// newPolicy returns the policy of the pods selected by a backend Service of an Ingress.
+func newPolicy(ing *networking.Ingress, service string, selector map[string]string, b *backend) *model.KVPair {
+	podSelector := &node.LabelSelector{MatchLabels: selector}
+	defaultAction := unp.ActionDeny
+	if b.all {
+		defaultAction = unp.ActionAllow
+	}
+	filter := &node.URLFilter{PodSelector: podSelector}
+	if len(b.urls) > 0 {
+		filter.Urls = b.urls
+		filter.Action = unp.ActionAllow
+	}
+	policy := &model.Policy{
+		Metadata: &node.ObjectMeta{
+			Namespace: ing.Namespace,
+			Name:      path.Join(ing.Name, service),
+			Uid:       string(ing.UID),
+			Labels:    ing.Labels,
+			Origin:    model.OriginIngress,
+		},
+		Spec: &node.PolicySpec{
+			PodSelector:     podSelector,
+			EnforcementMode: unpv1.EnforcementModeEnforce,
+			L7Policies: []*node.L7Policy{{
+				Default:   &node.DefaultPolicy{Action: defaultAction},
+				UrlFilter: filter,
+			}},
+		},
+	}
+	name := model.IngressPolicyName(ing.Namespace, ing.Name, service)
+	return &model.KVPair{Key: model.UNPKey{Name: name}, Value: policy}
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingress_test
+
+import (
+	"context"
+	"github.com/nimbess/stargazer/pkg/config"
+	"github.com/nimbess/stargazer/pkg/controller/handlers/ingress"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/model"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type ingresstest struct {
+	testName string
+	spec     networking.IngressSpec
+	// expected policies by backend service
+	urls    map[string][]string
+	actions map[string]string
+}
+
+func paths(host, service string, p ...string) networking.IngressRule {
+	rule := networking.IngressRule{Host: host}
+	rule.HTTP = &networking.HTTPIngressRuleValue{}
+	for _, path := range p {
+		rule.HTTP.Paths = append(rule.HTTP.Paths, networking.HTTPIngressPath{Path: path,
+			Backend: networking.IngressBackend{ServiceName: service}})
+	}
+	return rule
+}
+
+var ingressTests = []ingresstest{
+	// pass: host and path rules
+	{"ingress 1", networking.IngressSpec{Rules: []networking.IngressRule{paths("shop.example.com", "web", "/cart/", "/")}},
+		map[string][]string{"web": {"shop.example.com/cart", "shop.example.com/cart/*", "shop.example.com/*"}},
+		map[string]string{"web": "deny"}},
+	// pass: rules without host, rules without paths route to the default backend
+	{"ingress 2", networking.IngressSpec{Rules: []networking.IngressRule{paths("", "api", "/api"), {Host: "www.example.com"}}},
+		map[string][]string{"api": {"*/api", "*/api/*"}},
+		map[string]string{"api": "deny"}},
+	// pass: a default backend allows all URLs
+	{"ingress 3", networking.IngressSpec{Backend: &networking.IngressBackend{ServiceName: "web"}},
+		map[string][]string{"web": nil},
+		map[string]string{"web": "allow"}},
+	// pass: a policy for each backend service
+	{"ingress 4", networking.IngressSpec{Backend: &networking.IngressBackend{ServiceName: "web"},
+		Rules: []networking.IngressRule{paths("shop.example.com", "api", "/api"), paths("shop.example.com", "web", "/static")}},
+		map[string][]string{"api": {"shop.example.com/api", "shop.example.com/api/*"},
+			"web": {"shop.example.com/static", "shop.example.com/static/*"}},
+		map[string]string{"api": "deny", "web": "allow"}},
+	// pass: missing services and services without selector are skipped
+	{"ingress 5", networking.IngressSpec{Rules: []networking.IngressRule{paths("", "missing", "/"), paths("", "external", "/")}},
+		map[string][]string{}, map[string]string{}},
+	// pass: no backends, no policy
+	{"ingress 6", networking.IngressSpec{}, map[string][]string{}, map[string]string{}},
+}
+
+func newService(name string, selector map[string]string) *v1.Service {
+	return &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}, Spec: v1.ServiceSpec{Selector: selector}}
+}
+
+func newHandler(t *testing.T, store etcdv3.Client) *ingress.Ingress {
+	h := &ingress.Ingress{}
+	if err := h.Init(config.NewConfig(), store, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	indexer := factory.Core().V1().Services().Informer().GetIndexer()
+	for _, svc := range []*v1.Service{
+		newService("web", map[string]string{"app": "web"}),
+		newService("api", map[string]string{"app": "api"}),
+		newService("external", nil),
+	} {
+		if err := indexer.Add(svc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h.SetCore(factory)
+	return h
+}
+
+func TestIngress_K8sToNimbess(t *testing.T) {
+	h := newHandler(t, etcdv3.NewMemoryClient())
+	for _, test := range ingressTests {
+		kvs, err := h.K8sToNimbess(&networking.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "shop"}, Spec: test.spec})
+		if err != nil {
+			t.Fatalf("%s: %v", test.testName, err)
+		}
+		if len(kvs) != len(test.actions) {
+			t.Errorf("%s\nExpected %d policies, got %d", test.testName, len(test.actions), len(kvs))
+			continue
+		}
+		for _, kv := range kvs {
+			spec := kv.Value.(*model.Policy).Spec
+			service := spec.PodSelector.MatchLabels["app"]
+			if kv.Key.(model.UNPKey).Name != "default/ingress/shop/"+service {
+				t.Errorf("%s\nUnexpected key %v for the pods of %s", test.testName, kv.Key, service)
+			}
+			l7 := spec.L7Policies[0]
+			if !reflect.DeepEqual(l7.UrlFilter.Urls, test.urls[service]) || l7.Default.Action != test.actions[service] {
+				t.Errorf("%s\nExpected: %v, %s\nGot: %v, %s", test.testName, test.urls[service], test.actions[service],
+					l7.UrlFilter.Urls, l7.Default.Action)
+			}
+			if !reflect.DeepEqual(l7.UrlFilter.PodSelector, spec.PodSelector) {
+				t.Errorf("%s\nExpected the URL filter to select the pods of %s, got %v", test.testName, service,
+					l7.UrlFilter.PodSelector)
+			}
+		}
+	}
+}
+
+func TestIngress_ObjectCreated(t *testing.T) {
+	store := etcdv3.NewMemoryClient()
+	h := newHandler(t, store)
+	ing := &networking.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "shop"}}
+	webKey := model.UNPKey{Name: "default/ingress/shop/web"}
+	apiKey := model.UNPKey{Name: "default/ingress/shop/api"}
+
+	ing.Spec.Rules = []networking.IngressRule{paths("a.example.com", "web", "/"), paths("a.example.com", "api", "/api")}
+	if err := h.ObjectCreated(ing); err != nil {
+		t.Fatal(err)
+	}
+	// the api service is no longer routed to, its policy is deleted
+	ing.Spec.Rules = []networking.IngressRule{paths("b.example.com", "web", "/")}
+	if err := h.ObjectUpdated(nil, ing); err != nil {
+		t.Fatal(err)
+	}
+	kv, err := store.Get(context.Background(), webKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := kv.Value.(*model.Policy)
+	if p.Metadata.Origin != model.OriginIngress || p.Spec.L7Policies[0].UrlFilter.Urls[0] != "b.example.com/*" {
+		t.Errorf("Unexpected value written: %+v", p)
+	}
+	if _, err := store.Get(context.Background(), apiKey); !etcdv3.IsNotFound(err) {
+		t.Errorf("Expected the policy of the api service to be deleted, got %v", err)
+	}
+
+	// the policies of other Ingresses are left untouched
+	other := &networking.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "shop2"},
+		Spec: networking.IngressSpec{Backend: &networking.IngressBackend{ServiceName: "web"}}}
+	if err := h.ObjectCreated(other); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.ObjectDeleted("default/shop"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(context.Background(), webKey); !etcdv3.IsNotFound(err) {
+		t.Errorf("Expected key to be deleted, got %v", err)
+	}
+	if _, err := store.Get(context.Background(), model.UNPKey{Name: "default/ingress/shop2/web"}); err != nil {
+		t.Errorf("Expected the policy of another Ingress to be kept, got %v", err)
+	}
+}
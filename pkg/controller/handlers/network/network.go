@@ -0,0 +1,179 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package network publishes the networks the UNPs are applied to and reports the number of UNPs
+// using each network in its status.
+package network
+
+import (
+	"context"
+	"github.com/golang/protobuf/proto"
+	nimbessclientset "github.com/nimbess/stargazer/pkg/client/clientset/versioned"
+	unpinformer "github.com/nimbess/stargazer/pkg/client/informers/externalversions"
+	unplisters "github.com/nimbess/stargazer/pkg/client/listers/unp/v1"
+	"github.com/nimbess/stargazer/pkg/config"
+	"github.com/nimbess/stargazer/pkg/controller/handlers/unp"
+	unpv1 "github.com/nimbess/stargazer/pkg/crd/api/unp/v1"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/model"
+	log "github.com/sirupsen/logrus"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/util/retry"
+)
+
+// Network writes the networks as NetworkKeys and counts the UNPs referencing them.
+type Network struct {
+	etcdClient etcdv3.Client
+	ctx        context.Context
+	client     nimbessclientset.Interface
+	policies   unplisters.UnifiedNetworkPolicyLister
+}
+
+// Init initializes handler configuration
+func (n *Network) Init(c *config.Config, etcdClient etcdv3.Client, ctx context.Context) error {
+	n.etcdClient = etcdClient
+	n.ctx = ctx
+	return nil
+}
+
+// SetClient sets the client updating the status of the networks and the lister of the UNPs.
+func (n *Network) SetClient(client nimbessclientset.Interface, factory unpinformer.SharedInformerFactory) {
+	n.client = client
+	n.policies = factory.Nimbess().V1().UnifiedNetworkPolicies().Lister()
+}
+
+// ObjectCreated writes the network to Nimbess etcd and updates its status
+func (n *Network) ObjectCreated(obj interface{}) error {
+	network := obj.(*unpv1.Network)
+	log.Debugf("Network created: %s", network.Name)
+	if err := Validate(network); err != nil {
+		log.WithError(err).Warnf("Invalid network %s, not written to Nimbess etcd", network.Name)
+		if err := n.remove(model.NetworkKey{Name: network.Name}); err != nil {
+			return err
+		}
+	} else if err := n.put(n.K8sToNimbess(network)); err != nil {
+		return err
+	}
+	return n.updateStatus(network)
+}
+
+// ObjectDeleted deletes the network from Nimbess etcd
+func (n *Network) ObjectDeleted(name string) error {
+	log.Debugf("Network deleted: %s", name)
+	return n.remove(model.NetworkKey{Name: name})
+}
+
+// ObjectUpdated writes the updated network to Nimbess etcd
+func (n *Network) ObjectUpdated(oldObj, newObj interface{}) error {
+	return n.ObjectCreated(newObj)
+}
+
+// TestHandler tests the handler configuration
+func (n *Network) TestHandler() {
+
+}
+
+// K8sToNimbess translates a K8S network into a Nimbess Key/Value Pair to be written into ETCD
+func (n *Network) K8sToNimbess(network *unpv1.Network) *model.KVPair {
+	return &model.KVPair{Key: model.NetworkKey{Name: network.Name}, Value: model.NewNetwork(network)}
+}
+
+// Policies returns the number of UNPs referencing the network.
+func (n *Network) Policies(name string) (int, error) {
+	policies, err := n.policies.List(labels.Everything())
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, p := range policies {
+		for _, network := range unp.Networks(p) {
+			if network == name {
+				count++
+				break
+			}
+		}
+	}
+	return count, nil
+}
+
+// updateStatus sets the number of UNPs referencing the network, if it changed.
+func (n *Network) updateStatus(network *unpv1.Network) error {
+	if n.client == nil {
+		return nil
+	}
+	count, err := n.Policies(network.Name)
+	if err != nil {
+		return err
+	}
+	if count == network.Status.Policies {
+		return nil
+	}
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := n.client.NimbessV1().Networks().Get(network.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		latest.Status.Policies = count
+		_, err = n.client.NimbessV1().Networks().UpdateStatus(latest)
+		return err
+	})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		log.WithError(err).Errorf("Failed to update status of network %s", network.Name)
+	}
+	return err
+}
+
+// put writes the key unless it already holds the value, to avoid waking up the agents on resyncs.
+func (n *Network) put(kv *model.KVPair) error {
+	current, err := n.etcdClient.Get(n.ctx, kv.Key)
+	if err == nil {
+		if proto.Equal(current.Value.(*model.Network), kv.Value.(*model.Network)) {
+			return nil
+		}
+		err = n.etcdClient.Update(n.ctx, kv)
+	} else if etcdv3.IsNotFound(err) {
+		err = n.etcdClient.Create(n.ctx, kv)
+		if etcdv3.IsExists(err) {
+			err = n.etcdClient.Update(n.ctx, kv)
+		}
+	}
+	if err != nil {
+		log.Errorf("Failed to write to Nimbess etcd: %v, error: %v", kv.Key, err)
+		if etcdv3.IsRetriable(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// remove deletes the key, keys already deleted are ignored.
+func (n *Network) remove(k model.Key) error {
+	err := n.etcdClient.Delete(n.ctx, k)
+	if etcdv3.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		log.Errorf("Failed to delete key from Nimbess etcd: %v, error: %v", k, err)
+		if etcdv3.IsRetriable(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package network_test
+
+import (
+	"context"
+	"github.com/nimbess/stargazer/pkg/client/clientset/versioned/fake"
+	unpinformer "github.com/nimbess/stargazer/pkg/client/informers/externalversions"
+	"github.com/nimbess/stargazer/pkg/config"
+	"github.com/nimbess/stargazer/pkg/controller/handlers/network"
+	unpv1 "github.com/nimbess/stargazer/pkg/crd/api/unp/v1"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/model"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type validatetest struct {
+	testName string
+	spec     unpv1.NetworkSpec
+	valid    bool
+}
+
+var validateTests = []validatetest{
+	// pass: VLAN network
+	{"validate 1", unpv1.NetworkSpec{CIDRs: []string{"10.0.0.0/24", "fd00::/64"}, VLAN: 100, DataPlane: "bess"}, true},
+	// pass: flat network
+	{"validate 2", unpv1.NetworkSpec{}, true},
+	// fail: invalid CIDR
+	{"validate 3", unpv1.NetworkSpec{CIDRs: []string{"10.0.0.0"}}, false},
+	// fail: VLAN out of range
+	{"validate 4", unpv1.NetworkSpec{VLAN: 4095}, false},
+	// fail: VLAN and VNI
+	{"validate 5", unpv1.NetworkSpec{VLAN: 100, VNI: 5000}, false},
+	// fail: unsupported data plane
+	{"validate 6", unpv1.NetworkSpec{DataPlane: "ovs"}, false},
+	// fail: invalid node selector
+	{"validate 7", unpv1.NetworkSpec{NodeSelector: metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "zone", Operator: "Foo"}}}}, false},
+	// fail: negative VLAN
+	{"validate 8", unpv1.NetworkSpec{VLAN: -1}, false},
+	// pass: VLAN 0 is untagged
+	{"validate 9", unpv1.NetworkSpec{VLAN: 0, CIDRs: []string{"10.0.0.0/24"}}, true},
+}
+
+func TestValidate(t *testing.T) {
+	for _, test := range validateTests {
+		n := &unpv1.Network{ObjectMeta: metav1.ObjectMeta{Name: "devNetwork"}, Spec: test.spec}
+		if err := network.Validate(n); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %v, got %v", test.testName, test.valid, err)
+		}
+	}
+}
+
+func TestNetwork_ObjectCreated(t *testing.T) {
+	n := &unpv1.Network{
+		ObjectMeta: metav1.ObjectMeta{Name: "devNetwork"},
+		Spec:       unpv1.NetworkSpec{CIDRs: []string{"10.0.0.0/24"}, VNI: 5000},
+	}
+	client := fake.NewSimpleClientset()
+	if _, err := client.NimbessV1().Networks().Create(n); err != nil {
+		t.Fatal(err)
+	}
+	factory := unpinformer.NewSharedInformerFactory(client, 0)
+	policies := factory.Nimbess().V1().UnifiedNetworkPolicies().Informer().GetIndexer()
+	for _, name := range []string{"a", "b"} {
+		p := &unpv1.UnifiedNetworkPolicy{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+		p.Spec.Network = "devNetwork"
+		if err := policies.Add(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store := etcdv3.NewMemoryClient()
+	h := &network.Network{}
+	if err := h.Init(config.NewConfig(), store, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	h.SetClient(client, factory)
+	if err := h.ObjectCreated(n); err != nil {
+		t.Fatal(err)
+	}
+
+	kv, err := store.Get(context.Background(), model.NetworkKey{Name: "devNetwork"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := kv.Value.(*model.Network); got.Vni != 5000 || len(got.Cidrs) != 1 {
+		t.Errorf("Unexpected value written: %+v", got)
+	}
+	updated, err := client.NimbessV1().Networks().Get("devNetwork", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Status.Policies != 2 {
+		t.Errorf("Expected 2 policies in status, got %d", updated.Status.Policies)
+	}
+
+	if err := h.ObjectDeleted("devNetwork"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(context.Background(), model.NetworkKey{Name: "devNetwork"}); !etcdv3.IsNotFound(err) {
+		t.Errorf("Expected key to be deleted, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package network
+
+import (
+	"net"
+
+	unpv1 "github.com/nimbess/stargazer/pkg/crd/api/unp/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Supported data planes, empty selects the default data plane of the agents.
+const (
+	DataPlaneBESS   = "bess"
+	DataPlaneKernel = "kernel"
+)
+
+const (
+	maxVLAN = 4094
+	maxVNI  = 1<<24 - 1
+)
+
+// Validate checks a Network before it is written to Nimbess etcd.
+func Validate(n *unpv1.Network) error {
+	var errs field.ErrorList
+	spec := field.NewPath("spec")
+
+	for i, cidr := range n.Spec.CIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			errs = append(errs, field.Invalid(spec.Child("cidrs").Index(i), cidr, "must be a valid CIDR"))
+		}
+	}
+	if n.Spec.VLAN < 0 || n.Spec.VLAN > maxVLAN {
+		errs = append(errs, field.Invalid(spec.Child("vlan"), n.Spec.VLAN, "must be between 1 and 4094, or 0 for an untagged network"))
+	}
+	if n.Spec.VNI < 0 || n.Spec.VNI > maxVNI {
+		errs = append(errs, field.Invalid(spec.Child("vni"), n.Spec.VNI, "must be between 1 and 16777215, or 0 for no VXLAN"))
+	}
+	if n.Spec.VLAN != 0 && n.Spec.VNI != 0 {
+		errs = append(errs, field.Forbidden(spec.Child("vni"), "vlan and vni are exclusive"))
+	}
+	switch n.Spec.DataPlane {
+	case "", DataPlaneBESS, DataPlaneKernel:
+	default:
+		errs = append(errs, field.NotSupported(spec.Child("dataPlane"), n.Spec.DataPlane,
+			[]string{DataPlaneBESS, DataPlaneKernel}))
+	}
+	if _, err := metav1.LabelSelectorAsSelector(&n.Spec.NodeSelector); err != nil {
+		errs = append(errs, field.Invalid(spec.Child("nodeSelector"), metav1.FormatLabelSelector(&n.Spec.NodeSelector),
+			err.Error()))
+	}
+	return errs.ToAggregate()
+}
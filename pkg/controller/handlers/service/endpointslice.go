@@ -0,0 +1,192 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"github.com/nimbess/stargazer/pkg/config"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/model"
+	log "github.com/sirupsen/logrus"
+	"sort"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1alpha1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// EndpointSlice writes the backends of the services as EndpointKeys. A service may have several
+// endpoint slices, the EndpointKey holds the addresses and ports of all of them.
+type EndpointSlice struct {
+	etcdClient etcdv3.Client
+	ctx        context.Context
+	indexer    cache.Indexer
+
+	lock sync.Mutex
+	// services maps the keys of the endpoint slices to the name of their service, deleted
+	// slices are no longer in the informer cache.
+	services map[string]string
+}
+
+// Init initializes handler configuration
+func (e *EndpointSlice) Init(c *config.Config, etcdClient etcdv3.Client, ctx context.Context) error {
+	e.etcdClient = etcdClient
+	e.ctx = ctx
+	e.services = map[string]string{}
+	return nil
+}
+
+// SetCache sets the informer cache the slices of a service are read from.
+func (e *EndpointSlice) SetCache(indexer cache.Indexer) {
+	e.indexer = indexer
+}
+
+// ObjectCreated writes the endpoints of the service of the slice to Nimbess etcd
+func (e *EndpointSlice) ObjectCreated(obj interface{}) error {
+	slice := obj.(*discovery.EndpointSlice)
+	key, err := cache.MetaNamespaceKeyFunc(slice)
+	if err != nil {
+		return nil
+	}
+	service := slice.Labels[discovery.LabelServiceName]
+
+	e.lock.Lock()
+	previous, known := e.services[key]
+	if service == "" {
+		delete(e.services, key)
+	} else {
+		e.services[key] = service
+	}
+	e.lock.Unlock()
+
+	if known && previous != service {
+		if err := e.sync(slice.Namespace, previous); err != nil {
+			return err
+		}
+	}
+	if service == "" {
+		log.Debugf("Endpoint slice without service: %s", key)
+		return nil
+	}
+	return e.sync(slice.Namespace, service)
+}
+
+// ObjectDeleted writes the endpoints of the service of the slice without it to Nimbess etcd
+func (e *EndpointSlice) ObjectDeleted(name string) error {
+	e.lock.Lock()
+	service, known := e.services[name]
+	delete(e.services, name)
+	e.lock.Unlock()
+	if !known {
+		return nil
+	}
+	namespace, _, err := cache.SplitMetaNamespaceKey(name)
+	if err != nil {
+		return nil
+	}
+	return e.sync(namespace, service)
+}
+
+// ObjectUpdated writes the endpoints of the service of the slice to Nimbess etcd
+func (e *EndpointSlice) ObjectUpdated(oldObj, newObj interface{}) error {
+	return e.ObjectCreated(newObj)
+}
+
+// TestHandler tests the handler configuration
+func (e *EndpointSlice) TestHandler() {
+
+}
+
+// sync writes the endpoints of a service from all its slices, or deletes them when the service
+// has no slice left.
+func (e *EndpointSlice) sync(namespace, service string) error {
+	var slices []*discovery.EndpointSlice
+	selector := labels.SelectorFromSet(labels.Set{discovery.LabelServiceName: service})
+	err := cache.ListAllByNamespace(e.indexer, namespace, selector, func(obj interface{}) {
+		slices = append(slices, obj.(*discovery.EndpointSlice))
+	})
+	if err != nil {
+		log.WithError(err).Errorf("Failed to list endpoint slices of %s/%s", namespace, service)
+		return nil
+	}
+	if len(slices) == 0 {
+		return remove(e.ctx, e.etcdClient, model.EndpointKey{Namespace: namespace, Name: service})
+	}
+	return put(e.ctx, e.etcdClient, e.K8sToNimbess(namespace, service, slices))
+}
+
+// K8sToNimbess translates the K8S endpoint slices of a service into a Nimbess Key/Value Pair to be
+// written into ETCD. Addresses and ports are sorted, duplicates are merged.
+func (e *EndpointSlice) K8sToNimbess(namespace, service string, slices []*discovery.EndpointSlice) *model.KVPair {
+	value := &model.Endpoint{Namespace: namespace, Name: service}
+	addresses := map[string]*model.EndpointAddress{}
+	type portKey struct {
+		name     string
+		port     int32
+		protocol string
+	}
+	ports := map[portKey]bool{}
+	for _, slice := range slices {
+		for _, ep := range slice.Endpoints {
+			address := model.EndpointAddress{
+				NodeName: ep.Topology[corev1.LabelHostname],
+				// endpoints are ready unless known otherwise
+				Ready: ep.Conditions.Ready == nil || *ep.Conditions.Ready,
+			}
+			if ep.TargetRef != nil && ep.TargetRef.Kind == "Pod" {
+				address.PodName = ep.TargetRef.Name
+			}
+			for _, ip := range ep.Addresses {
+				if a, ok := addresses[ip]; ok {
+					// an address listed by several slices is ready if any of them says so
+					a.Ready = a.Ready || address.Ready
+					continue
+				}
+				a := address
+				a.Ip = ip
+				addresses[ip] = &a
+				value.Addresses = append(value.Addresses, &a)
+			}
+		}
+		for _, p := range slice.Ports {
+			port := model.EndpointPort{}
+			if p.Name != nil {
+				port.Name = *p.Name
+			}
+			if p.Port != nil {
+				port.Port = *p.Port
+			}
+			if p.Protocol != nil {
+				port.Protocol = string(*p.Protocol)
+			}
+			k := portKey{port.Name, port.Port, port.Protocol}
+			if ports[k] {
+				continue
+			}
+			ports[k] = true
+			value.Ports = append(value.Ports, &port)
+		}
+	}
+	sort.Slice(value.Addresses, func(i, j int) bool { return value.Addresses[i].Ip < value.Addresses[j].Ip })
+	sort.Slice(value.Ports, func(i, j int) bool {
+		if value.Ports[i].Name != value.Ports[j].Name {
+			return value.Ports[i].Name < value.Ports[j].Name
+		}
+		return value.Ports[i].Port < value.Ports[j].Port
+	})
+	return &model.KVPair{Key: model.EndpointKey{Namespace: namespace, Name: service}, Value: value}
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package service publishes the services and their backends, so that L7 policies can refer to
+// services by name and agents can resolve them without the Kubernetes API.
+package service
+
+import (
+	"context"
+	"github.com/nimbess/stargazer/pkg/config"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/model"
+	log "github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Service writes the virtual IPs and ports of the services as ServiceKeys.
+type Service struct {
+	etcdClient etcdv3.Client
+	ctx        context.Context
+}
+
+// Init initializes handler configuration
+func (s *Service) Init(c *config.Config, etcdClient etcdv3.Client, ctx context.Context) error {
+	s.etcdClient = etcdClient
+	s.ctx = ctx
+	return nil
+}
+
+// ObjectCreated writes the service to Nimbess etcd
+func (s *Service) ObjectCreated(obj interface{}) error {
+	svc := obj.(*corev1.Service)
+	log.Debugf("Service created: %s/%s", svc.Namespace, svc.Name)
+	return put(s.ctx, s.etcdClient, s.K8sToNimbess(svc))
+}
+
+// ObjectDeleted deletes the service from Nimbess etcd
+func (s *Service) ObjectDeleted(name string) error {
+	log.Debugf("Service deleted: %s", name)
+	namespace, name, err := cache.SplitMetaNamespaceKey(name)
+	if err != nil {
+		log.WithError(err).Errorf("Invalid service key: %s", name)
+		return nil
+	}
+	return remove(s.ctx, s.etcdClient, model.ServiceKey{Namespace: namespace, Name: name})
+}
+
+// ObjectUpdated writes the updated service to Nimbess etcd
+func (s *Service) ObjectUpdated(oldObj, newObj interface{}) error {
+	return s.ObjectCreated(newObj)
+}
+
+// TestHandler tests the handler configuration
+func (s *Service) TestHandler() {
+
+}
+
+// K8sToNimbess translates a K8S service into a Nimbess Key/Value Pair to be written into ETCD
+func (s *Service) K8sToNimbess(svc *corev1.Service) *model.KVPair {
+	value := &model.Service{
+		Namespace:   svc.Namespace,
+		Name:        svc.Name,
+		Type:        string(svc.Spec.Type),
+		ExternalIPs: svc.Spec.ExternalIPs,
+	}
+	if svc.Spec.ClusterIP != corev1.ClusterIPNone {
+		value.ClusterIP = svc.Spec.ClusterIP
+	}
+	for _, p := range svc.Spec.Ports {
+		value.Ports = append(value.Ports, &model.ServicePort{
+			Name:       p.Name,
+			Port:       p.Port,
+			Protocol:   string(p.Protocol),
+			TargetPort: p.TargetPort.String(),
+		})
+	}
+	return &model.KVPair{Key: model.ServiceKey{Namespace: svc.Namespace, Name: svc.Name}, Value: value}
+}
+
+// put creates the key, or replaces its value if it exists.
+func put(ctx context.Context, etcdClient etcdv3.Client, kv *model.KVPair) error {
+	err := etcdClient.Create(ctx, kv)
+	if etcdv3.IsExists(err) {
+		err = etcdClient.Update(ctx, kv)
+	}
+	if err != nil {
+		log.Errorf("Failed to write to Nimbess etcd: %v, error: %v", kv.Key, err)
+		if etcdv3.IsRetriable(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// remove deletes the key, keys already deleted are ignored.
+func remove(ctx context.Context, etcdClient etcdv3.Client, k model.Key) error {
+	err := etcdClient.Delete(ctx, k)
+	if etcdv3.IsNotFound(err) {
+		log.Debugf("Key already deleted from Nimbess etcd: %v", k)
+		return nil
+	}
+	if err != nil {
+		log.Errorf("Failed to delete key from Nimbess etcd: %v, error: %v", k, err)
+		if etcdv3.IsRetriable(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service_test
+
+import (
+	"context"
+	"github.com/nimbess/stargazer/pkg/config"
+	"github.com/nimbess/stargazer/pkg/controller/handlers/service"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/model"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestService_ObjectCreated(t *testing.T) {
+	store := etcdv3.NewMemoryClient()
+	h := &service.Service{}
+	if err := h.Init(config.NewConfig(), store, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeClusterIP,
+			ClusterIP: "10.96.0.10",
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: 80, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromString("web")},
+			},
+		},
+	}
+	// updates of a service replace the existing key
+	for _, ip := range []string{"10.96.0.10", corev1.ClusterIPNone} {
+		svc.Spec.ClusterIP = ip
+		if err := h.ObjectCreated(svc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	kv, err := store.Get(context.Background(), model.ServiceKey{Namespace: "default", Name: "web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := kv.Value.(*model.Service)
+	if got.ClusterIP != "" || got.Type != "ClusterIP" || len(got.Ports) != 1 || got.Ports[0].TargetPort != "web" {
+		t.Errorf("Unexpected value written: %+v", got)
+	}
+
+	if err := h.ObjectDeleted("default/web"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(context.Background(), kv.Key); !etcdv3.IsNotFound(err) {
+		t.Errorf("Expected key to be deleted, got %v", err)
+	}
+}
+
+func newSlice(name, service string, ready bool, ips ...string) *discovery.EndpointSlice {
+	port, protocol := int32(8080), corev1.ProtocolTCP
+	return &discovery.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name,
+			Labels: map[string]string{discovery.LabelServiceName: service}},
+		Endpoints: []discovery.Endpoint{{
+			Addresses:  ips,
+			Conditions: discovery.EndpointConditions{Ready: &ready},
+			Topology:   map[string]string{corev1.LabelHostname: "node1"},
+			TargetRef:  &corev1.ObjectReference{Kind: "Pod", Name: name + "-pod"},
+		}},
+		Ports: []discovery.EndpointPort{{Port: &port, Protocol: &protocol}},
+	}
+}
+
+func TestEndpointSlice(t *testing.T) {
+	store := etcdv3.NewMemoryClient()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	h := &service.EndpointSlice{}
+	if err := h.Init(config.NewConfig(), store, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	h.SetCache(indexer)
+	key := model.EndpointKey{Namespace: "default", Name: "web"}
+
+	slices := []*discovery.EndpointSlice{
+		newSlice("web-b", "web", false, "10.0.0.2"),
+		newSlice("web-a", "web", true, "10.0.0.1", "10.0.0.2"),
+		newSlice("db-a", "db", true, "10.0.1.1"),
+	}
+	for _, s := range slices {
+		indexer.Add(s)
+		if err := h.ObjectCreated(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	kv, err := store.Get(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ips []string
+	for _, a := range kv.Value.(*model.Endpoint).Addresses {
+		ips = append(ips, a.Ip)
+	}
+	if expected := []string{"10.0.0.1", "10.0.0.2"}; !reflect.DeepEqual(ips, expected) {
+		t.Errorf("Expected addresses %v, got %v", expected, ips)
+	}
+	if a := kv.Value.(*model.Endpoint).Addresses; !a[1].Ready || a[1].PodName == "" || a[1].NodeName != "node1" {
+		t.Errorf("Unexpected address: %v", a[1])
+	}
+	if ports := kv.Value.(*model.Endpoint).Ports; len(ports) != 1 || ports[0].Port != 8080 {
+		t.Errorf("Unexpected ports: %v", ports)
+	}
+
+	// the endpoints are removed with the last slice of the service
+	for _, s := range slices[:2] {
+		indexer.Delete(s)
+		if err := h.ObjectDeleted("default/" + s.Name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := store.Get(context.Background(), key); !etcdv3.IsNotFound(err) {
+		t.Errorf("Expected key to be deleted, got %v", err)
+	}
+	if _, err := store.Get(context.Background(), model.EndpointKey{Namespace: "default", Name: "db"}); err != nil {
+		t.Errorf("Expected endpoints of other services to be kept, got %v", err)
+	}
+}
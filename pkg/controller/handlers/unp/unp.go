@@ -16,12 +16,20 @@ package unp
 
 import (
 	"context"
+	"github.com/golang/protobuf/proto"
+	nimbessclientset "github.com/nimbess/stargazer/pkg/client/clientset/versioned"
+	unpinformer "github.com/nimbess/stargazer/pkg/client/informers/externalversions"
+	unplisters "github.com/nimbess/stargazer/pkg/client/listers/unp/v1"
 	"github.com/nimbess/stargazer/pkg/config"
+	"github.com/nimbess/stargazer/pkg/controller/handlers/attachment"
 	unpv1 "github.com/nimbess/stargazer/pkg/crd/api/unp/v1"
 	"github.com/nimbess/stargazer/pkg/etcdv3"
 	"github.com/nimbess/stargazer/pkg/model"
 	log "github.com/sirupsen/logrus"
 	"path"
+
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
 )
 
 // Handler is implemented by any handler.
@@ -29,46 +37,68 @@ import (
 type UNP struct {
 	etcdClient etcdv3.Client
 	ctx        context.Context
+
+	// checkNetworks and checkAttachments are set when the Network and NetworkAttachment controllers
+	// are enabled, UNPs referencing networks that do not exist are then not written.
+	checkNetworks    bool
+	checkAttachments bool
+	networks         unplisters.NetworkLister
+	attachments      cache.GenericLister
 }
 
 // Init initializes handler configuration
 func (u *UNP) Init(c *config.Config, etcdClient etcdv3.Client, ctx context.Context) error {
 	u.etcdClient = etcdClient
 	u.ctx = ctx
+	u.checkNetworks = c.Controllers.Network
+	u.checkAttachments = c.Controllers.NetworkAttachment
 	return nil
 }
 
-// ObjectCreated creates entry in Nimbess DB with translated object
-func (u *UNP) ObjectCreated(obj interface{}) {
-	log.Infof("Created object found by controller: %v", obj)
-	unpConf := obj.(*unpv1.UnifiedNetworkPolicy)
-	kv, err := u.K8sToNimbess(unpConf)
-	if err != nil {
-		log.Errorf("Failed to convert K8S to Nimbess: %v", unpConf)
-		return
+// SetClient sets the lister of the networks referenced by the UNPs.
+func (u *UNP) SetClient(client nimbessclientset.Interface, factory unpinformer.SharedInformerFactory) {
+	if u.checkNetworks {
+		u.networks = factory.Nimbess().V1().Networks().Lister()
 	}
-	err = u.etcdClient.Create(u.ctx, kv)
-	if err != nil {
-		log.Errorf("Failed to write to Nimbess etcd: %v", kv)
+}
+
+// SetDynamic sets the lister of the Multus network attachments referenced by the UNPs.
+func (u *UNP) SetDynamic(factory dynamicinformer.DynamicSharedInformerFactory) {
+	if u.checkAttachments {
+		u.attachments = factory.ForResource(attachment.GroupVersionResource).Lister()
 	}
 }
 
+// ObjectCreated creates entry in Nimbess DB with translated object
+func (u *UNP) ObjectCreated(obj interface{}) error {
+	log.Infof("Created object found by controller: %v", obj)
+	return u.write(obj.(*unpv1.UnifiedNetworkPolicy))
+}
+
 // ObjectDeleted deletes entry in Nimbess DB with translated object
-func (u *UNP) ObjectDeleted(name string) {
+func (u *UNP) ObjectDeleted(name string) error {
 	log.Infof("Deleted object found by controller: %v", name)
 	k := model.UNPKey{
 		Name: name,
 	}
 
 	err := u.etcdClient.Delete(u.ctx, k)
+	if etcdv3.IsNotFound(err) {
+		log.Debugf("Key already deleted from Nimbess etcd: %v", k)
+		return nil
+	}
 	if err != nil {
-		log.Errorf("Failed to delete key from Nimbess etcd: %v", k)
+		log.Errorf("Failed to delete key from Nimbess etcd: %v, error: %v", k, err)
+		if etcdv3.IsRetriable(err) {
+			return err
+		}
 	}
+	return nil
 }
 
 // ObjectUpdated updates entry in Nimbess DB with translated object
-func (u *UNP) ObjectUpdated(oldObj, newObj interface{}) {
-	// TODO(trozet): implement
+func (u *UNP) ObjectUpdated(oldObj, newObj interface{}) error {
+	return u.write(newObj.(*unpv1.UnifiedNetworkPolicy))
 }
 
 // TestHandler tests the handler configuration writing tests objects into DB
@@ -76,13 +106,61 @@ func (u *UNP) TestHandler() {
 
 }
 
+// write writes the UNP to Nimbess etcd, replacing the existing value if it differs, e.g. when a
+// network it refers to now resolves to another name. Invalid UNPs are removed so that agents do
+// not keep enforcing a policy that no longer matches its object. UNPs whose networks could not be
+// looked up are retried and left untouched.
+func (u *UNP) write(unpConf *unpv1.UnifiedNetworkPolicy) error {
+	err := Validate(unpConf)
+	if err == nil {
+		unpConf, err = ResolveNetworks(unpConf, u.networks, u.attachments)
+	}
+	if err != nil && !IsInvalid(err) {
+		log.WithError(err).Errorf("Failed to look up the networks of UNP %s/%s", unpConf.Namespace, unpConf.Name)
+		return err
+	}
+	if err != nil {
+		log.WithError(err).Warnf("Invalid UNP %s/%s, not written to Nimbess etcd", unpConf.Namespace, unpConf.Name)
+		return u.ObjectDeleted(path.Join(unpConf.Namespace, unpConf.Name))
+	}
+	if legacy := unpConf.Spec.Attributes.Legacy; legacy != "" {
+		log.Warnf("UNP %s/%s has string attributes %q, which are no longer supported, written without attributes",
+			unpConf.Namespace, unpConf.Name, legacy)
+	}
+	kv, err := u.K8sToNimbess(unpConf)
+	if err != nil {
+		log.Errorf("Failed to convert K8S to Nimbess: %v", unpConf)
+		return nil
+	}
+	current, err := u.etcdClient.Get(u.ctx, kv.Key)
+	if err == nil {
+		if proto.Equal(current.Value.(*model.Policy), kv.Value.(*model.Policy)) {
+			log.Debugf("Key already up to date in Nimbess etcd: %v", kv.Key)
+			return nil
+		}
+		err = u.etcdClient.Update(u.ctx, kv)
+	} else if etcdv3.IsNotFound(err) {
+		err = u.etcdClient.Create(u.ctx, kv)
+		if etcdv3.IsExists(err) {
+			err = u.etcdClient.Update(u.ctx, kv)
+		}
+	}
+	if err != nil {
+		log.Errorf("Failed to write to Nimbess etcd: %v, error: %v", kv, err)
+		if etcdv3.IsRetriable(err) {
+			return err
+		}
+	}
+	return nil
+}
+
 // K8stoNimbess translates a K8S UNP Config into a Nimbess Key/Value Pair to be written into ETCD
 func (u *UNP) K8sToNimbess(unpConfig *unpv1.UnifiedNetworkPolicy) (*model.KVPair, error) {
 	k := model.UNPKey{
 		Name: path.Join(unpConfig.Namespace, unpConfig.Name),
 	}
 
-	kv := model.KVPair{Key: k, Value: unpConfig}
+	kv := model.KVPair{Key: k, Value: model.NewPolicy(unpConfig)}
 
 	log.WithFields(log.Fields{
 		"k8s":    unpConfig,
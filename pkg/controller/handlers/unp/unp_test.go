@@ -0,0 +1,424 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/nimbess/stargazer/pkg/client/clientset/versioned/fake"
+	unpinformer "github.com/nimbess/stargazer/pkg/client/informers/externalversions"
+	"github.com/nimbess/stargazer/pkg/config"
+	"github.com/nimbess/stargazer/pkg/controller/handlers/attachment"
+	"github.com/nimbess/stargazer/pkg/controller/handlers/unp"
+	unpv1 "github.com/nimbess/stargazer/pkg/crd/api/unp/v1"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/model"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"path"
+	"testing"
+)
+
+func newHandler(t *testing.T) (*unp.UNP, etcdv3.Client) {
+	store := etcdv3.NewMemoryClient()
+	h := &unp.UNP{}
+	if err := h.Init(config.NewConfig(), store, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return h, store
+}
+
+func newPolicy(namespace, name string) *unpv1.UnifiedNetworkPolicy {
+	return &unpv1.UnifiedNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: unpv1.UnifiedNetworkPolicySpec{
+			L7Policies: []unpv1.L7Policy{{Default: unpv1.DefaultPolicy{Action: "allow"}}},
+			Network:    "devNetwork",
+		},
+	}
+}
+
+func TestUNP_ObjectCreated(t *testing.T) {
+	h, store := newHandler(t)
+	policy := newPolicy("kube-system", "testpolicy")
+	if err := h.ObjectCreated(policy); err != nil {
+		t.Fatal(err)
+	}
+	kv, err := store.Get(context.Background(), model.UNPKey{Name: "kube-system/testpolicy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := kv.Value.(*model.Policy); got.Spec.Network != "devNetwork" || got.Metadata.Name != "testpolicy" {
+		t.Errorf("Unexpected value written: %+v", got)
+	}
+	// creating an existing policy is not retried
+	if err := h.ObjectCreated(policy); err != nil {
+		t.Errorf("Expected no error for existing key, got %v", err)
+	}
+	// a policy changed since it was written, e.g. while stargazer was down, is rewritten
+	policy.Spec.Network = "prodNetwork"
+	if err := h.ObjectCreated(policy); err != nil {
+		t.Fatal(err)
+	}
+	kv, err = store.Get(context.Background(), model.UNPKey{Name: "kube-system/testpolicy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := kv.Value.(*model.Policy); got.Spec.Network != "prodNetwork" {
+		t.Errorf("Expected the stale value to be replaced, got %+v", got)
+	}
+}
+
+func TestUNP_ObjectDeleted(t *testing.T) {
+	h, store := newHandler(t)
+	if err := h.ObjectCreated(newPolicy("kube-system", "testpolicy")); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.ObjectDeleted("kube-system/testpolicy"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(context.Background(), model.UNPKey{Name: "kube-system/testpolicy"}); !etcdv3.IsNotFound(err) {
+		t.Errorf("Expected key to be deleted, got %v", err)
+	}
+	// deleting a missing policy is not retried
+	if err := h.ObjectDeleted("kube-system/testpolicy"); err != nil {
+		t.Errorf("Expected no error for missing key, got %v", err)
+	}
+}
+
+type validatetest struct {
+	testName string
+	l7       unpv1.L7Policy
+	valid    bool
+}
+
+var validateTests = []validatetest{
+	// pass: default action
+	{"validate 1", unpv1.L7Policy{Default: unpv1.DefaultPolicy{Action: "allow"}}, true},
+	// pass: URL filter
+	{"validate 2", unpv1.L7Policy{UrlFilter: unpv1.URLFilter{Action: "deny", Urls: []string{"msn.com"}}}, true},
+	// fail: unsupported action
+	{"validate 3", unpv1.L7Policy{Default: unpv1.DefaultPolicy{Action: "block"}}, false},
+	// fail: filter without URLs
+	{"validate 4", unpv1.L7Policy{UrlFilter: unpv1.URLFilter{Action: "deny"}}, false},
+	// fail: URL with spaces
+	{"validate 5", unpv1.L7Policy{UrlFilter: unpv1.URLFilter{Action: "deny", Urls: []string{"a b"}}}, false},
+	// fail: invalid selector
+	{"validate 6", unpv1.L7Policy{UrlFilter: unpv1.URLFilter{PodSelector: metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Foo"}}}}}, false},
+	// pass: log action
+	{"validate 7", unpv1.L7Policy{UrlFilter: unpv1.URLFilter{Action: "log", Urls: []string{"msn.com"}}}, true},
+}
+
+func TestValidate(t *testing.T) {
+	for _, test := range validateTests {
+		policy := newPolicy("default", "testpolicy")
+		policy.Spec.L7Policies = []unpv1.L7Policy{test.l7}
+		if err := unp.Validate(policy); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %v, got %v", test.testName, test.valid, err)
+		}
+	}
+}
+
+func TestUNP_ObjectCreated_Invalid(t *testing.T) {
+	h, store := newHandler(t)
+	policy := newPolicy("kube-system", "testpolicy")
+	policy.Spec.L7Policies[0].Default.Action = "block"
+	if err := h.ObjectCreated(policy); err != nil {
+		t.Errorf("Expected invalid policy not to be retried, got %v", err)
+	}
+	if _, err := store.Get(context.Background(), model.UNPKey{Name: "kube-system/testpolicy"}); !etcdv3.IsNotFound(err) {
+		t.Errorf("Expected invalid policy not to be written, got %v", err)
+	}
+}
+
+func TestUNP_ObjectCreated_MissingNetwork(t *testing.T) {
+	store := etcdv3.NewMemoryClient()
+	conf := config.NewConfig()
+	conf.Controllers.Network = true
+	h := &unp.UNP{}
+	if err := h.Init(conf, store, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	factory := unpinformer.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	networks := factory.Nimbess().V1().Networks().Informer().GetIndexer()
+	h.SetClient(nil, factory)
+
+	policy := newPolicy("kube-system", "testpolicy")
+	key := model.UNPKey{Name: "kube-system/testpolicy"}
+	if err := h.ObjectCreated(policy); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(context.Background(), key); !etcdv3.IsNotFound(err) {
+		t.Errorf("Expected policy with a missing network not to be written, got %v", err)
+	}
+
+	if err := networks.Add(&unpv1.Network{ObjectMeta: metav1.ObjectMeta{Name: "devNetwork"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.ObjectCreated(policy); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(context.Background(), key); err != nil {
+		t.Errorf("Expected policy to be written, got %v", err)
+	}
+
+	if err := networks.Delete(&unpv1.Network{ObjectMeta: metav1.ObjectMeta{Name: "devNetwork"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.ObjectCreated(policy); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(context.Background(), key); !etcdv3.IsNotFound(err) {
+		t.Errorf("Expected policy to be deleted once its network is gone, got %v", err)
+	}
+}
+
+type resolvetest struct {
+	testName string
+	network  string
+	expected string
+	valid    bool
+}
+
+var resolveTests = []resolvetest{
+	// pass: cluster network
+	{"resolve 1", "devNetwork", "devNetwork", true},
+	// pass: attachment in the namespace of the UNP
+	{"resolve 2", "macvlan", "default/macvlan", true},
+	// pass: attachment in another namespace
+	{"resolve 3", "kube-system/vlan42", "kube-system/vlan42", true},
+	// fail: missing attachment
+	{"resolve 4", "kube-system/macvlan", "", false},
+	// fail: neither a network nor an attachment
+	{"resolve 5", "regionA", "", false},
+}
+
+func TestResolveNetworks(t *testing.T) {
+	factory := unpinformer.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	networks := factory.Nimbess().V1().Networks()
+	if err := networks.Informer().GetIndexer().Add(&unpv1.Network{ObjectMeta: metav1.ObjectMeta{Name: "devNetwork"}}); err != nil {
+		t.Fatal(err)
+	}
+	dynFactory := dynamicinformer.NewDynamicSharedInformerFactory(dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()), 0)
+	attachments := dynFactory.ForResource(attachment.GroupVersionResource)
+	for _, nad := range []string{"default/macvlan", "kube-system/vlan42"} {
+		u := &unstructured.Unstructured{}
+		u.SetNamespace(path.Dir(nad))
+		u.SetName(path.Base(nad))
+		if err := attachments.Informer().GetIndexer().Add(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, test := range resolveTests {
+		policy := newPolicy("default", "testpolicy")
+		policy.Spec.Network = test.network
+		resolved, err := unp.ResolveNetworks(policy, networks.Lister(), attachments.Lister())
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %v, got %v", test.testName, test.valid, err)
+			continue
+		}
+		if err == nil && resolved.Spec.Network != test.expected {
+			t.Errorf("%s\nExpected: %s\nGot: %s", test.testName, test.expected, resolved.Spec.Network)
+		}
+	}
+}
+
+type attributestest struct {
+	testName   string
+	attributes string
+	valid      bool
+}
+
+var attributesTests = []attributestest{
+	// pass: all attributes
+	{"attributes 1", `{"qosClass":"burstable","logLevel":"debug","audit":true,"bandwidth":{"ingress":"10M","egress":"1G"}}`, true},
+	// pass: no attributes
+	{"attributes 2", `{}`, true},
+	// pass: empty string of the earlier versions
+	{"attributes 3", `""`, true},
+	// pass: opaque string of the earlier versions, ignored
+	{"attributes 4", `"qos=gold"`, true},
+	// fail: unsupported QoS class
+	{"attributes 5", `{"qosClass":"gold"}`, false},
+	// fail: unsupported logging level
+	{"attributes 6", `{"logLevel":"trace"}`, false},
+	// fail: negative bandwidth
+	{"attributes 7", `{"bandwidth":{"egress":"-1M"}}`, false},
+}
+
+func TestValidate_Attributes(t *testing.T) {
+	for _, test := range attributesTests {
+		policy := newPolicy("default", "testpolicy")
+		if err := json.Unmarshal([]byte(test.attributes), &policy.Spec.Attributes); err != nil {
+			t.Errorf("%s: failed to decode attributes: %v", test.testName, err)
+			continue
+		}
+		if err := unp.Validate(policy); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %v, got %v", test.testName, test.valid, err)
+		}
+	}
+}
+
+func TestUNP_K8sToNimbess_Attributes(t *testing.T) {
+	h, _ := newHandler(t)
+	policy := newPolicy("default", "testpolicy")
+	ingress := resource.MustParse("10M")
+	policy.Spec.Attributes = unpv1.PolicyAttributes{QoSClass: unp.QoSGuaranteed, Audit: true,
+		Bandwidth: &unpv1.BandwidthLimits{Ingress: &ingress}}
+	kv, err := h.K8sToNimbess(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := kv.Value.(*model.Policy).Spec.PolicyAttributes
+	if got.QosClass != unp.QoSGuaranteed || !got.Audit || got.IngressBandwidth != 10000000 || got.EgressBandwidth != 0 {
+		t.Errorf("Unexpected attributes: %+v", got)
+	}
+
+	policy.Spec.Attributes = unpv1.PolicyAttributes{}
+	if kv, _ = h.K8sToNimbess(policy); kv.Value.(*model.Policy).Spec.PolicyAttributes != nil {
+		t.Errorf("Expected no attributes, got %+v", kv.Value.(*model.Policy).Spec.PolicyAttributes)
+	}
+}
+
+type modetest struct {
+	testName string
+	mode     string
+	expected string
+	valid    bool
+}
+
+var modeTests = []modetest{
+	// pass: defaults to enforce
+	{"mode 1", "", "enforce", true},
+	// pass: audit
+	{"mode 2", "audit", "audit", true},
+	// pass: disabled
+	{"mode 3", "disabled", "disabled", true},
+	// fail: unsupported mode
+	{"mode 4", "dry-run", "", false},
+}
+
+func TestUNP_EnforcementMode(t *testing.T) {
+	h, _ := newHandler(t)
+	for _, test := range modeTests {
+		policy := newPolicy("default", "testpolicy")
+		policy.Spec.EnforcementMode = test.mode
+		if err := unp.Validate(policy); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %v, got %v", test.testName, test.valid, err)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		kv, err := h.K8sToNimbess(policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := kv.Value.(*model.Policy).Spec.EnforcementMode; got != test.expected {
+			t.Errorf("%s\nExpected: %s\nGot: %s", test.testName, test.expected, got)
+		}
+	}
+}
+
+func TestUNP_ObjectUpdated_EnforcementMode(t *testing.T) {
+	h, store := newHandler(t)
+	policy := newPolicy("default", "testpolicy")
+	policy.Spec.EnforcementMode = "audit"
+	if err := h.ObjectCreated(policy); err != nil {
+		t.Fatal(err)
+	}
+	// enforce the policy once audited
+	policy.Spec.EnforcementMode = "enforce"
+	if err := h.ObjectUpdated(nil, policy); err != nil {
+		t.Fatal(err)
+	}
+	kv, err := store.Get(context.Background(), model.UNPKey{Name: "default/testpolicy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := kv.Value.(*model.Policy).Spec.EnforcementMode; got != "enforce" {
+		t.Errorf("Expected the updated enforcement mode, got %s", got)
+	}
+}
+
+// failingNetworkLister fails all lookups, like a lister whose cache cannot be read.
+type failingNetworkLister struct{}
+
+func (failingNetworkLister) List(selector labels.Selector) ([]*unpv1.Network, error) {
+	return nil, errors.New("cache unavailable")
+}
+
+func (failingNetworkLister) Get(name string) (*unpv1.Network, error) {
+	return nil, errors.New("cache unavailable")
+}
+
+func TestIsInvalid(t *testing.T) {
+	policy := newPolicy("default", "testpolicy")
+	_, err := unp.ResolveNetworks(policy, failingNetworkLister{}, nil)
+	if err == nil || unp.IsInvalid(err) {
+		t.Errorf("Expected a failed lookup not to be reported as invalid, got %v", err)
+	}
+
+	factory := unpinformer.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	_, err = unp.ResolveNetworks(policy, factory.Nimbess().V1().Networks().Lister(), nil)
+	if !unp.IsInvalid(err) {
+		t.Errorf("Expected a missing network to be reported as invalid, got %v", err)
+	}
+
+	policy.Spec.L7Policies[0].Default.Action = "block"
+	if err := unp.Validate(policy); !unp.IsInvalid(err) {
+		t.Errorf("Expected an unsupported action to be reported as invalid, got %v", err)
+	}
+}
+
+func TestUNP_ObjectCreated_LegacyAttributes(t *testing.T) {
+	// an UNP written before the attributes were structured
+	raw := `{"apiVersion":"nimbess.com/v1","kind":"UnifiedNetworkPolicy",` +
+		`"metadata":{"namespace":"kube-system","name":"testpolicy"},` +
+		`"spec":{"l7Policies":[{"default":{"action":"allow"}}],"network":"devNetwork","attributes":"qos=gold"}}`
+	policy := &unpv1.UnifiedNetworkPolicy{}
+	if err := json.Unmarshal([]byte(raw), policy); err != nil {
+		t.Fatal(err)
+	}
+
+	h, store := newHandler(t)
+	if err := h.ObjectCreated(policy); err != nil {
+		t.Fatal(err)
+	}
+	kv, err := store.Get(context.Background(), model.UNPKey{Name: "kube-system/testpolicy"})
+	if err != nil {
+		t.Fatalf("Expected the policy to be written without attributes, got %v", err)
+	}
+	if got := kv.Value.(*model.Policy).Spec.PolicyAttributes; got != nil {
+		t.Errorf("Expected no attributes, got %+v", got)
+	}
+
+	// the string is kept when the UNP is encoded again
+	data, err := json.Marshal(policy.Spec.Attributes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `"qos=gold"` {
+		t.Errorf("Expected the string attributes to be kept, got %s", data)
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unp
+
+import (
+	"strings"
+
+	unplisters "github.com/nimbess/stargazer/pkg/client/listers/unp/v1"
+	unpv1 "github.com/nimbess/stargazer/pkg/crd/api/unp/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Actions of the L7 policies. ActionLog lets the traffic through and logs it, to observe what a
+// filter matches before enforcing it.
+const (
+	ActionAllow = "allow"
+	ActionDeny  = "deny"
+	ActionLog   = "log"
+)
+
+var supportedActions = []string{ActionAllow, ActionDeny, ActionLog}
+
+var supportedEnforcementModes = []string{unpv1.EnforcementModeEnforce, unpv1.EnforcementModeAudit,
+	unpv1.EnforcementModeDisabled}
+
+// QoS classes of the policy attributes.
+const (
+	QoSBestEffort = "best-effort"
+	QoSBurstable  = "burstable"
+	QoSGuaranteed = "guaranteed"
+)
+
+var supportedQoSClasses = []string{QoSBestEffort, QoSBurstable, QoSGuaranteed}
+
+// Logging levels of the policy attributes.
+const (
+	LogLevelNone  = "none"
+	LogLevelInfo  = "info"
+	LogLevelDebug = "debug"
+)
+
+var supportedLogLevels = []string{LogLevelNone, LogLevelInfo, LogLevelDebug}
+
+// Validate checks that the UNP can be translated into a Nimbess policy. UNPs failing validation
+// are not written to the datastore.
+func Validate(p *unpv1.UnifiedNetworkPolicy) error {
+	var errs field.ErrorList
+	if p.Name == "" {
+		errs = append(errs, field.Required(field.NewPath("metadata", "name"), ""))
+	}
+
+	spec := field.NewPath("spec")
+	errs = append(errs, validateSelector(&p.Spec.PodSelector, spec.Child("podSelector"))...)
+	for i, l7 := range p.Spec.L7Policies {
+		path := spec.Child("l7Policies").Index(i)
+		errs = append(errs, validateAction(l7.Default.Action, path.Child("default", "action"))...)
+
+		filter := l7.UrlFilter
+		filterPath := path.Child("urlFilter")
+		errs = append(errs, validateAction(filter.Action, filterPath.Child("action"))...)
+		if filter.Action != "" && len(filter.Urls) == 0 {
+			errs = append(errs, field.Required(filterPath.Child("urls"), "a filter with an action needs URLs"))
+		}
+		if filter.Action == "" && len(filter.Urls) > 0 {
+			errs = append(errs, field.Required(filterPath.Child("action"), "a filter with URLs needs an action"))
+		}
+		for j, url := range filter.Urls {
+			if url == "" || strings.ContainsAny(url, " \t\n") {
+				errs = append(errs, field.Invalid(filterPath.Child("urls").Index(j), url, "must be a non-empty URL without spaces"))
+			}
+		}
+		errs = append(errs, validateSelector(&filter.PodSelector, filterPath.Child("podSelector"))...)
+	}
+	errs = append(errs, validateAttributes(&p.Spec.Attributes, spec.Child("attributes"))...)
+	errs = append(errs, validateOneOf(p.Spec.EnforcementMode, supportedEnforcementModes, spec.Child("enforcementMode"))...)
+	return errs.ToAggregate()
+}
+
+// Networks returns the names of the networks referenced by the UNP, sorted.
+func Networks(p *unpv1.UnifiedNetworkPolicy) []string {
+	networks := sets.NewString()
+	if p.Spec.Network != "" {
+		networks.Insert(p.Spec.Network)
+	}
+	for _, l7 := range p.Spec.L7Policies {
+		if l7.UrlFilter.Network != "" {
+			networks.Insert(l7.UrlFilter.Network)
+		}
+	}
+	return networks.List()
+}
+
+// ResolveNetworks checks that the networks referenced by the UNP exist and returns a copy of it
+// referring to Multus network attachments by <namespace>/<name>. A name without namespace refers to
+// a Network, or else to an attachment in the namespace of the UNP. The resources whose lister is nil
+// are not watched, names possibly referring to them are left unchecked.
+func ResolveNetworks(p *unpv1.UnifiedNetworkPolicy, networks unplisters.NetworkLister,
+	attachments cache.GenericLister) (*unpv1.UnifiedNetworkPolicy, error) {
+
+	var errs field.ErrorList
+	resolve := func(name string, path *field.Path) string {
+		if name == "" {
+			return name
+		}
+		var err error
+		if namespace, attachment, qualified := splitNetwork(name); qualified {
+			if attachments == nil {
+				return name
+			}
+			_, err = attachments.ByNamespace(namespace).Get(attachment)
+		} else {
+			if networks != nil {
+				if _, err = networks.Get(name); err == nil {
+					return name
+				}
+			}
+			if attachments != nil && (err == nil || apierrors.IsNotFound(err)) {
+				if _, err = attachments.ByNamespace(p.Namespace).Get(name); err == nil {
+					return p.Namespace + "/" + name
+				}
+			}
+			if networks == nil && apierrors.IsNotFound(err) {
+				return name
+			}
+		}
+		if apierrors.IsNotFound(err) {
+			errs = append(errs, field.NotFound(path, name))
+		} else if err != nil {
+			errs = append(errs, field.InternalError(path, err))
+		}
+		return name
+	}
+
+	resolved := p.DeepCopy()
+	spec := field.NewPath("spec")
+	resolved.Spec.Network = resolve(p.Spec.Network, spec.Child("network"))
+	for i, l7 := range p.Spec.L7Policies {
+		resolved.Spec.L7Policies[i].UrlFilter.Network = resolve(l7.UrlFilter.Network,
+			spec.Child("l7Policies").Index(i).Child("urlFilter", "network"))
+	}
+	return resolved, errs.ToAggregate()
+}
+
+// IsInvalid returns true if the error reports an invalid UNP. It returns false when the validation
+// could not complete, e.g. because a lister failed, the UNP must then be validated again.
+func IsInvalid(err error) bool {
+	agg, ok := err.(utilerrors.Aggregate)
+	if !ok {
+		return false
+	}
+	for _, e := range agg.Errors() {
+		if fe, ok := e.(*field.Error); !ok || fe.Type == field.ErrorTypeInternal {
+			return false
+		}
+	}
+	return true
+}
+
+// splitNetwork splits a network attachment reference into its namespace and name.
+func splitNetwork(name string) (string, string, bool) {
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) != 2 {
+		return "", name, false
+	}
+	return parts[0], parts[1], true
+}
+
+func validateAction(action string, path *field.Path) field.ErrorList {
+	return validateOneOf(action, supportedActions, path)
+}
+
+func validateAttributes(a *unpv1.PolicyAttributes, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, validateOneOf(a.QoSClass, supportedQoSClasses, path.Child("qosClass"))...)
+	errs = append(errs, validateOneOf(a.LogLevel, supportedLogLevels, path.Child("logLevel"))...)
+	if a.Bandwidth != nil {
+		errs = append(errs, validateBandwidth(a.Bandwidth.Ingress, path.Child("bandwidth", "ingress"))...)
+		errs = append(errs, validateBandwidth(a.Bandwidth.Egress, path.Child("bandwidth", "egress"))...)
+	}
+	return errs
+}
+
+func validateBandwidth(q *resource.Quantity, path *field.Path) field.ErrorList {
+	if q != nil && q.Sign() <= 0 {
+		return field.ErrorList{field.Invalid(path, q.String(), "must be positive")}
+	}
+	return nil
+}
+
+func validateOneOf(value string, supported []string, path *field.Path) field.ErrorList {
+	if value == "" {
+		return nil
+	}
+	for _, s := range supported {
+		if value == s {
+			return nil
+		}
+	}
+	return field.ErrorList{field.NotSupported(path, value, supported)}
+}
+
+func validateSelector(selector *metav1.LabelSelector, path *field.Path) field.ErrorList {
+	if _, err := metav1.LabelSelectorAsSelector(selector); err != nil {
+		return field.ErrorList{field.Invalid(path, metav1.FormatLabelSelector(selector), err.Error())}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	nimbessclientset "github.com/nimbess/stargazer/pkg/client/clientset/versioned"
+	unpinformer "github.com/nimbess/stargazer/pkg/client/informers/externalversions"
+	"github.com/nimbess/stargazer/pkg/config"
+	"github.com/nimbess/stargazer/pkg/controller/handlers/attachment"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	coreinformer "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Informers holds the informer factories shared by all controllers of a stargazer process.
+// Dynamic watches the resources of other projects, e.g. the Multus NetworkAttachmentDefinitions.
+type Informers struct {
+	Nimbess unpinformer.SharedInformerFactory
+	Core    coreinformer.SharedInformerFactory
+	Dynamic dynamicinformer.DynamicSharedInformerFactory
+
+	// scope filters the watched resources when more than a single namespace is configured or
+	// namespaces are selected by labels.
+	scope    *config.NamespaceScope
+	nsLister corelisters.NamespaceLister
+
+	lock        sync.Mutex
+	dispatchers map[string]*dispatcher
+}
+
+// informerFuncs maps each controller name to the shared informer it watches
+var informerFuncs = map[string]func(*Informers) cache.SharedIndexInformer{
+	"UNP": func(i *Informers) cache.SharedIndexInformer {
+		return i.Nimbess.Nimbess().V1().UnifiedNetworkPolicies().Informer()
+	},
+	"Service": func(i *Informers) cache.SharedIndexInformer {
+		return i.Core.Core().V1().Services().Informer()
+	},
+	"EndpointSlice": func(i *Informers) cache.SharedIndexInformer {
+		return i.Core.Discovery().V1alpha1().EndpointSlices().Informer()
+	},
+	"Ingress": func(i *Informers) cache.SharedIndexInformer {
+		return i.Core.Networking().V1beta1().Ingresses().Informer()
+	},
+	"Network": func(i *Informers) cache.SharedIndexInformer {
+		return i.Nimbess.Nimbess().V1().Networks().Informer()
+	},
+	"NetworkAttachment": func(i *Informers) cache.SharedIndexInformer {
+		return i.Dynamic.ForResource(attachment.GroupVersionResource).Informer()
+	},
+	"PodNetwork": func(i *Informers) cache.SharedIndexInformer {
+		return i.Core.Core().V1().Pods().Informer()
+	},
+}
+
+// NewInformers creates the shared Nimbess, core and dynamic informer factories using the resync
+// period, namespace scoping and label selector from the configuration.
+// The label selector only applies to Nimbess resources.
+func NewInformers(conf *config.Config, kubeClient nimbessclientset.Interface,
+	coreClient kubernetes.Interface, dynClient dynamic.Interface) (*Informers, error) {
+
+	scope, err := conf.NamespaceScope()
+	if err != nil {
+		return nil, err
+	}
+	i := &Informers{scope: scope, dispatchers: map[string]*dispatcher{}}
+
+	// A single namespace is scoped by the factories themselves, anything else
+	// watches all namespaces and filters events with InScope.
+	namespace := scope.Single()
+
+	resync := time.Duration(conf.ResyncPeriod) * time.Second
+	log.WithFields(log.Fields{
+		"resync":            resync,
+		"namespaces":        scope.Namespaces.List(),
+		"namespaceSelector": conf.NamespaceSelector,
+		"labelSelector":     conf.LabelSelector,
+	}).Info("Creating shared informer factories")
+
+	nimbessOpts := []unpinformer.SharedInformerOption{unpinformer.WithNamespace(namespace)}
+	if conf.LabelSelector != "" {
+		if _, err := labels.Parse(conf.LabelSelector); err != nil {
+			return nil, fmt.Errorf("invalid label selector %q: %v", conf.LabelSelector, err)
+		}
+		selector := conf.LabelSelector
+		nimbessOpts = append(nimbessOpts, unpinformer.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = selector
+		}))
+	}
+
+	i.Nimbess = unpinformer.NewSharedInformerFactoryWithOptions(kubeClient, resync, nimbessOpts...)
+	i.Core = coreinformer.NewSharedInformerFactoryWithOptions(coreClient, resync,
+		coreinformer.WithNamespace(namespace))
+	i.Dynamic = dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynClient, resync, namespace, nil)
+	if scope.Selector != nil {
+		namespaces := i.Core.Core().V1().Namespaces()
+		i.nsLister = namespaces.Lister()
+		namespaces.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { i.namespaceChanged(nil, obj) },
+			UpdateFunc: i.namespaceChanged,
+		})
+	}
+	return i, nil
+}
+
+// Start runs all informers requested so far and waits for them to sync. Safe to call multiple times.
+// Core informers are synced first so that namespaces are known before Nimbess events are filtered.
+func (i *Informers) Start(stopCh <-chan struct{}) {
+	i.Core.Start(stopCh)
+	i.Core.WaitForCacheSync(stopCh)
+	i.Nimbess.Start(stopCh)
+	i.Dynamic.Start(stopCh)
+	i.Nimbess.WaitForCacheSync(stopCh)
+	i.Dynamic.WaitForCacheSync(stopCh)
+}
+
+// InScope returns true if the object lives in one of the watched namespaces.
+// Cluster scoped objects are always in scope.
+func (i *Informers) InScope(obj interface{}) bool {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return false
+	}
+	namespace, _, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil || namespace == "" {
+		return err == nil
+	}
+	if !i.scope.Listed(namespace) {
+		return false
+	}
+	if i.scope.Selector == nil {
+		return true
+	}
+	ns, err := i.nsLister.Get(namespace)
+	if err != nil {
+		log.WithError(err).Debugf("Failed to get namespace %s, ignoring object %s", namespace, key)
+		return false
+	}
+	return i.scope.Matches(namespace, ns.Labels)
+}
+
+// namespaceChanged queues the objects of a namespace relabeled into the selector as created, and
+// those of a namespace relabeled out of it as deleted. oldObj is nil for new namespaces, whose
+// objects may have been filtered out if their events came before the namespace was known.
+func (i *Informers) namespaceChanged(oldObj, newObj interface{}) {
+	ns, ok := newObj.(*corev1.Namespace)
+	if !ok {
+		return
+	}
+	wasInScope := false
+	if old, ok := oldObj.(*corev1.Namespace); ok {
+		wasInScope = i.scope.Matches(old.Name, old.Labels)
+	}
+	inScope := i.scope.Matches(ns.Name, ns.Labels)
+	if inScope == wasInScope {
+		return
+	}
+	eventType := "create"
+	if !inScope {
+		eventType = "delete"
+	}
+	log.WithFields(log.Fields{"namespace": ns.Name, "inScope": inScope}).Info(
+		"Namespace relabeled, requeueing its objects")
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	for name, d := range i.dispatchers {
+		objs, err := informerFuncs[name](i).GetIndexer().ByIndex(cache.NamespaceIndex, ns.Name)
+		if err != nil {
+			log.WithError(err).Errorf("Failed to list %s objects in namespace %s", name, ns.Name)
+			continue
+		}
+		d.requeueNamespace(eventType, objs)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	nimbessfake "github.com/nimbess/stargazer/pkg/client/clientset/versioned/fake"
+	"github.com/nimbess/stargazer/pkg/config"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+// testNamespaces are known to the namespace lister of the informers under test.
+var testNamespaces = []*v1.Namespace{
+	{ObjectMeta: metav1.ObjectMeta{Name: "default", Labels: map[string]string{"team": "web"}}},
+	{ObjectMeta: metav1.ObjectMeta{Name: "prod", Labels: map[string]string{"team": "web", "env": "prod"}}},
+	{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+}
+
+// newTestInformers creates informers for the scope, with testNamespaces in the namespace cache.
+func newTestInformers(t *testing.T, namespaces, selector string) *Informers {
+	conf := config.NewConfig()
+	conf.Namespaces = namespaces
+	conf.NamespaceSelector = selector
+	informers, err := NewInformers(conf, nimbessfake.NewSimpleClientset(), fake.NewSimpleClientset(),
+		dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexer := informers.Core.Core().V1().Namespaces().Informer().GetIndexer()
+	for _, ns := range testNamespaces {
+		if err := indexer.Add(ns); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return informers
+}
+
+func service(namespace string) *v1.Service {
+	return &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "web"}}
+}
+
+var inScopeTests = []struct {
+	name       string
+	namespaces string
+	selector   string
+	obj        interface{}
+	expected   bool
+}{
+	{"all namespaces", "", "", service("kube-system"), true},
+	{"listed namespace", "default,prod", "", service("prod"), true},
+	{"unlisted namespace", "default,prod", "", service("kube-system"), false},
+	{"cluster scoped", "default,prod", "", &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}}, true},
+	{"selected namespace", "", "team=web", service("default"), true},
+	{"unselected namespace", "", "team=web", service("kube-system"), false},
+	{"unknown namespace", "", "team=web", service("staging"), false},
+	{"selected but unlisted", "default", "env=prod", service("default"), false},
+	{"selected and listed", "default,prod", "env=prod", service("prod"), true},
+	{"deleted object", "default", "team=web",
+		cache.DeletedFinalStateUnknown{Key: "default/web", Obj: service("default")}, true},
+	{"single namespace", "default", "", service("default"), true},
+	{"outside single namespace", "default", "", service("prod"), false},
+}
+
+func TestInformers_InScope(t *testing.T) {
+	for _, test := range inScopeTests {
+		t.Run(test.name, func(t *testing.T) {
+			informers := newTestInformers(t, test.namespaces, test.selector)
+			if inScope := informers.InScope(test.obj); inScope != test.expected {
+				t.Errorf("Expected InScope to return %v, got %v", test.expected, inScope)
+			}
+		})
+	}
+}
+
+func TestNewInformers_singleNamespace(t *testing.T) {
+	conf := config.NewConfig()
+	conf.Namespaces = "default"
+	coreClient := fake.NewSimpleClientset(service("default"), service("prod"))
+	informers, err := NewInformers(conf, nimbessfake.NewSimpleClientset(), coreClient,
+		dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	informer := informerFuncs["Service"](informers)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	informers.Start(stopCh)
+
+	keys := informer.GetStore().ListKeys()
+	if len(keys) != 1 || keys[0] != "default/web" {
+		t.Errorf("Expected the factory to list default only, got %v", keys)
+	}
+}
+
+func TestInformers_namespaceChanged(t *testing.T) {
+	informers := newTestInformers(t, "", "team=web")
+	informer := informerFuncs["Service"](informers)
+	for _, ns := range []string{"default", "kube-system"} {
+		if err := informer.GetIndexer().Add(service(ns)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := newResourceController(nil, &recordingHandler{}, informer, "service")
+	informers.dispatcher("Service").setController(c)
+
+	expectEvent := func(eventType string) {
+		t.Helper()
+		if c.queue.Len() != 1 {
+			t.Fatalf("Expected one %s event, got %d events", eventType, c.queue.Len())
+		}
+		item, _ := c.queue.Get()
+		c.queue.Done(item)
+		if e := item.(Event); e.key != "kube-system/web" || e.eventType != eventType {
+			t.Errorf("Expected a %s event for kube-system/web, got %+v", eventType, e)
+		}
+	}
+
+	old := testNamespaces[2]
+	relabeled := old.DeepCopy()
+	relabeled.Labels = map[string]string{"team": "web"}
+	informers.namespaceChanged(old, relabeled)
+	expectEvent("create")
+
+	informers.namespaceChanged(relabeled, old)
+	expectEvent("delete")
+
+	informers.namespaceChanged(old, old.DeepCopy())
+	if c.queue.Len() != 0 {
+		t.Errorf("Expected no events if the namespace stays out of scope, got %d", c.queue.Len())
+	}
+	informers.namespaceChanged(nil, relabeled)
+	expectEvent("create")
+}
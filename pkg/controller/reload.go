@@ -0,0 +1,57 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"github.com/nimbess/stargazer/pkg/config"
+	"reflect"
+)
+
+// etcdSettings are the settings of the datastore connection, changing them reconnects to the datastore.
+var etcdSettings = []string{
+	"EtcdEndpoints", "EtcdDialTimeout", "EtcdCAFile", "EtcdCertFile", "EtcdKeyFile", "EtcdServerName",
+	"EtcdUsername", "EtcdPassword", "EtcdLeaseTTL", "EtcdBatchWindow", "EtcdBatchMaxOps",
+}
+
+// informerSettings are the settings of the shared informers, changing them recreates the informers.
+var informerSettings = []string{"ResyncPeriod", "Namespaces", "NamespaceSelector", "LabelSelector"}
+
+// restartSettings are only read at startup, changing them requires a restart of stargazer.
+var restartSettings = []string{
+	"Kubeconfig", "Datastore", "DatastoreNamespace", "Tenant", "EtcdPrefix", "EtcdMigrate",
+	"EtcdValueCodec", "EtcdCompression", "MetricsAddress", "UnpConfigName",
+}
+
+// changedSettings returns the names of the settings that differ between a and b.
+func changedSettings(a, b *config.Config, settings []string) []string {
+	va, vb := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem()
+	var changed []string
+	for _, name := range settings {
+		if va.FieldByName(name).Interface() != vb.FieldByName(name).Interface() {
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
+
+// keepSettings returns a copy of conf with the settings set to those of current.
+func keepSettings(conf, current *config.Config, settings []string) *config.Config {
+	c := *conf
+	dst, src := reflect.ValueOf(&c).Elem(), reflect.ValueOf(current).Elem()
+	for _, name := range settings {
+		dst.FieldByName(name).Set(src.FieldByName(name))
+	}
+	return &c
+}
@@ -0,0 +1,215 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	nimbessfake "github.com/nimbess/stargazer/pkg/client/clientset/versioned/fake"
+	"github.com/nimbess/stargazer/pkg/config"
+	unpv1 "github.com/nimbess/stargazer/pkg/crd/api/unp/v1"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type settingstest struct {
+	testName string
+	change   func(c *config.Config)
+	expected []string
+}
+
+var settingsTests = []settingstest{
+	// no change
+	{"settings 1", func(c *config.Config) {}, nil},
+	// other settings are ignored
+	{"settings 2", func(c *config.Config) {
+		c.LogLevel = "debug"
+		c.ResyncPeriod = 30
+	}, nil},
+	{"settings 3", func(c *config.Config) {
+		c.EtcdEndpoints = "http://etcd:2379"
+	}, []string{"EtcdEndpoints"}},
+	// listed in the order of the settings
+	{"settings 4", func(c *config.Config) {
+		c.EtcdBatchWindow = time.Millisecond
+		c.EtcdDialTimeout = time.Minute
+		c.EtcdLeaseTTL = time.Minute
+	}, []string{"EtcdDialTimeout", "EtcdLeaseTTL", "EtcdBatchWindow"}},
+}
+
+func TestChangedSettings(t *testing.T) {
+	for _, test := range settingsTests {
+		current := config.NewConfig()
+		conf := *current
+		test.change(&conf)
+		changed := changedSettings(current, &conf, etcdSettings)
+		if !reflect.DeepEqual(changed, test.expected) {
+			t.Errorf("%s: expected: %v\nGot: %v", test.testName, test.expected, changed)
+		}
+	}
+}
+
+func TestKeepSettings(t *testing.T) {
+	for _, test := range settingsTests {
+		current := config.NewConfig()
+		conf := *current
+		test.change(&conf)
+		before := conf
+		kept := keepSettings(&conf, current, etcdSettings)
+		if changed := changedSettings(current, kept, etcdSettings); len(changed) > 0 {
+			t.Errorf("%s: expected the etcd settings to be kept, got changes of %v", test.testName, changed)
+		}
+		if kept.LogLevel != conf.LogLevel || kept.ResyncPeriod != conf.ResyncPeriod {
+			t.Errorf("%s: expected the other settings to be applied, got %+v", test.testName, kept)
+		}
+		if !reflect.DeepEqual(conf, before) {
+			t.Errorf("%s: expected the configuration to be left untouched, got %+v", test.testName, conf)
+		}
+	}
+}
+
+type applytest struct {
+	testName  string
+	change    func(c *config.Config)
+	stopped   []string
+	restarted []string
+}
+
+var applyTests = []applytest{
+	// log level only
+	{"apply 1", func(c *config.Config) { c.LogLevel = "debug" }, nil, nil},
+	// the informers are recreated
+	{"apply 2", func(c *config.Config) { c.ResyncPeriod = 30 }, nil, []string{"Service", "EndpointSlice", "Ingress", "PodNetwork"}},
+	// the Ingress controller reads the services
+	{"apply 3", func(c *config.Config) { c.Controllers.Service = false }, []string{"Service"}, []string{"Ingress"}},
+	// settings read at startup are kept
+	{"apply 4", func(c *config.Config) { c.EtcdPrefix = "/other" }, nil, nil},
+	// the datastore is reconnected
+	{"apply 5", func(c *config.Config) { c.EtcdEndpoints = "http://etcd:2379" }, nil, []string{"Service", "EndpointSlice", "Ingress", "PodNetwork"}},
+}
+
+func TestManager_apply(t *testing.T) {
+	for _, test := range applyTests {
+		conf := config.NewConfig()
+		conf.Controllers = config.Controllers{Service: true, EndpointSlice: true, Ingress: true, PodNetwork: true}
+		m := &manager{
+			coreClient:     fake.NewSimpleClientset(),
+			dynClient:      dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()),
+			informerStopCh: make(chan struct{}),
+			controllers:    map[string]*Controller{},
+			etcdClient:     etcdv3.NewMemoryClient(),
+			ctx:            context.Background(),
+			newStore: func(conf *config.Config) (etcdv3.Client, error) {
+				return etcdv3.NewMemoryClient(), nil
+			},
+		}
+		informers, err := NewInformers(conf, nimbessfake.NewSimpleClientset(), m.coreClient, m.dynClient)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.informers = informers
+		if err := m.apply(conf); err != nil {
+			t.Fatalf("%s: %v", test.testName, err)
+		}
+		running := map[string]*Controller{}
+		for name, c := range m.controllers {
+			running[name] = c
+		}
+
+		next := *conf
+		test.change(&next)
+		if err := m.apply(&next); err != nil {
+			t.Errorf("%s: unexpected error: %v", test.testName, err)
+		}
+		for name, c := range running {
+			now, ok := m.controllers[name]
+			switch {
+			case contains(test.stopped, name):
+				if ok {
+					t.Errorf("%s: expected %s to be stopped", test.testName, name)
+				}
+			case contains(test.restarted, name):
+				if !ok || now == c {
+					t.Errorf("%s: expected %s to be restarted", test.testName, name)
+				}
+			case now != c:
+				t.Errorf("%s: expected %s to keep running", test.testName, name)
+			}
+		}
+		if m.conf.EtcdPrefix != conf.EtcdPrefix {
+			t.Errorf("%s: expected the etcd prefix %s to be kept, got %s", test.testName, conf.EtcdPrefix, m.conf.EtcdPrefix)
+		}
+		m.stopAll()
+		close(m.informerStopCh)
+	}
+}
+
+func TestManager_applyUnpConfig(t *testing.T) {
+	unpConfig := &unpv1.UnpConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "stargazer", Generation: 1},
+		Spec:       unpv1.UnpConfigSpec{LogLevel: "debug"},
+	}
+	// created through the typed client, objects passed to NewSimpleClientset are not found
+	client := nimbessfake.NewSimpleClientset()
+	if _, err := client.NimbessV1().UnpConfigs().Create(unpConfig); err != nil {
+		t.Fatal(err)
+	}
+	m := &manager{base: config.NewConfig()}
+	status := func() unpv1.UnpConfigStatus {
+		latest, err := client.NimbessV1().UnpConfigs().Get(unpConfig.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return latest.Status
+	}
+
+	m.applyUnpConfig(client, unpConfig)
+	if m.override == nil || m.override.LogLevel != "debug" {
+		t.Errorf("Expected the UnpConfig to be applied, got %+v", m.override)
+	}
+	if s := status(); s.ObservedGeneration != 1 || s.Error != "" {
+		t.Errorf("Expected the UnpConfig to be reported applied, got %+v", s)
+	}
+
+	invalid := unpConfig.DeepCopy()
+	invalid.Generation = 2
+	invalid.Spec.Etcd = &unpv1.UnpConfigEtcdSpec{DialTimeout: &metav1.Duration{Duration: 0}}
+	if _, err := client.NimbessV1().UnpConfigs().Update(invalid); err != nil {
+		t.Fatal(err)
+	}
+	m.applyUnpConfig(client, invalid)
+	if m.override == nil || m.override.Etcd != nil {
+		t.Errorf("Expected the invalid UnpConfig to be rejected, got %+v", m.override)
+	}
+	if s := status(); s.ObservedGeneration != 2 || !strings.Contains(s.Error, "EtcdDialTimeout") {
+		t.Errorf("Expected the UnpConfig to be reported rejected, got %+v", s)
+	}
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -18,24 +18,35 @@ import (
 	"context"
 	"fmt"
 	nimbessclientset "github.com/nimbess/stargazer/pkg/client/clientset/versioned"
-	unpinformer "github.com/nimbess/stargazer/pkg/client/informers/externalversions"
 	"github.com/nimbess/stargazer/pkg/config"
 	"github.com/nimbess/stargazer/pkg/controller/handlers"
+	unpv1 "github.com/nimbess/stargazer/pkg/crd/api/unp/v1"
 	"github.com/nimbess/stargazer/pkg/etcdv3"
-	"github.com/nimbess/stargazer/pkg/signals"
 	"github.com/nimbess/stargazer/pkg/utils"
 	"reflect"
+	"strings"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 )
 
 const maxRetries = 5
 
+// requeueOn maps each controller to the controllers whose objects it reads. When those are enabled,
+// their creations, deletions and spec changes cause all objects of the controller to be processed
+// again, e.g. UNPs are validated against the existing networks.
+var requeueOn = map[string][]string{
+	"UNP":     {"Network", "NetworkAttachment"},
+	"Network": {"UNP"},
+	"Ingress": {"Service"},
+}
+
 var serverStartTime time.Time
 
 // Event indicate the informerEvent
@@ -54,97 +65,368 @@ type Controller struct {
 	informer     cache.SharedIndexInformer
 	eventHandler handlers.Handler
 	etcdClient   etcdv3.Client
+	resourceType string
+	workers      int
+	wg           sync.WaitGroup
+
+	// inScope filters the objects requeued from the informer cache, nil for all objects
+	inScope func(obj interface{}) bool
 }
 
-// Runs stargazer and then waits for process termination signals
-func Run(conf *config.Config, kubeClient *nimbessclientset.Clientset, etcdClient etcdv3.Client, ctx context.Context) {
-	v := reflect.ValueOf(conf.Controllers)
+// DatastoreFunc returns a datastore client for the configuration, called when the etcd settings
+// are changed by an UnpConfig.
+type DatastoreFunc func(conf *config.Config) (etcdv3.Client, error)
+
+// manager starts and stops the controllers to match the configuration file, overridden by the
+// UnpConfig if any.
+type manager struct {
+	lock       sync.Mutex
+	kubeClient *nimbessclientset.Clientset
+	coreClient kubernetes.Interface
+	dynClient  dynamic.Interface
+	newStore   DatastoreFunc
+	ctx        context.Context
+	cancel     context.CancelFunc
+	stopCh     <-chan struct{}
+
+	base           *config.Config
+	override       *unpv1.UnpConfigSpec
+	conf           *config.Config
+	informers      *Informers
+	informerStopCh chan struct{}
+	etcdClient     etcdv3.Client
+	storeStopCh    chan struct{}
+	controllers    map[string]*Controller
+}
+
+// Runs stargazer until stopCh is closed. The configurations received on reloadCh replace conf,
+// the controllers are started, stopped or restarted to apply them. On stop the controllers process
+// their queued events for the ShutdownGracePeriod, then the datastore operations in flight are
+// cancelled and etcdClient is closed.
+func Run(conf *config.Config, kubeClient *nimbessclientset.Clientset, coreClient kubernetes.Interface,
+	dynClient dynamic.Interface, etcdClient etcdv3.Client, ctx context.Context, newStore DatastoreFunc, reloadCh <-chan *config.Config,
+	stopCh <-chan struct{}) {
 	defer utilruntime.HandleCrash()
-	stopCh := signals.SetupSignalHandler()
+	informers, err := NewInformers(conf, kubeClient, coreClient, dynClient)
+	if err != nil {
+		log.Fatalf("Failed to create informers: %v", err)
+	}
+	m := &manager{
+		kubeClient:     kubeClient,
+		coreClient:     coreClient,
+		dynClient:      dynClient,
+		newStore:       newStore,
+		stopCh:         stopCh,
+		base:           conf,
+		informers:      informers,
+		informerStopCh: make(chan struct{}),
+		controllers:    map[string]*Controller{},
+	}
+	m.ctx, m.cancel = context.WithCancel(ctx)
+	m.setStore(etcdClient)
+	if conf.UnpConfigName != "" {
+		m.watchUnpConfig(kubeClient, conf.UnpConfigName)
+	}
+
+	m.lock.Lock()
+	err = m.update()
+	m.lock.Unlock()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for {
+		select {
+		case conf := <-reloadCh:
+			m.setBase(conf)
+		case <-stopCh:
+			m.shutdown()
+			return
+		}
+	}
+}
+
+// shutdown stops the controllers, cancels the datastore operations still in flight once the
+// ShutdownGracePeriod is over and closes the datastore client.
+func (m *manager) shutdown() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	log.Info("Shutting down")
+	if !m.stopAll() {
+		log.Warn("Controllers not drained, cancelling the datastore operations in flight")
+	}
+	m.cancel()
+	close(m.informerStopCh)
+	close(m.storeStopCh)
+	if err := m.etcdClient.Close(); err != nil {
+		log.WithError(err).Warn("Failed to close datastore client")
+	}
+}
+
+// setBase replaces the configuration file.
+func (m *manager) setBase(conf *config.Config) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	log.Info("Applying reloaded configuration")
+	m.base = conf
+	if err := m.update(); err != nil {
+		log.WithError(err).Error("Failed to apply configuration")
+	}
+}
+
+// setOverride applies the spec of the UnpConfig, nil when there is none. An invalid spec is
+// rejected and the current configuration kept.
+func (m *manager) setOverride(spec *unpv1.UnpConfigSpec) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if spec != nil {
+		if _, err := ApplyUnpConfig(m.base, spec); err != nil {
+			log.WithError(err).Error("Invalid UnpConfig, keeping the current configuration")
+			return err
+		}
+	}
+	m.override = spec
+	if m.conf == nil {
+		// not started yet, applied by Run
+		return nil
+	}
+	if err := m.update(); err != nil {
+		log.WithError(err).Error("Failed to apply configuration")
+	}
+	return nil
+}
+
+// update computes the configuration and applies it. Must be called with the lock held.
+func (m *manager) update() error {
+	conf := m.base
+	if m.override != nil {
+		merged, err := ApplyUnpConfig(m.base, m.override)
+		if err != nil {
+			log.WithError(err).Error("Invalid UnpConfig, ignoring it")
+		} else {
+			conf = merged
+		}
+	}
+	return m.apply(conf)
+}
+
+// apply sets the log level, reconnects to the datastore if the etcd settings changed, recreates
+// the informers if their settings changed and starts, stops or restarts the controllers whose
+// settings changed. Must be called with the lock held.
+func (m *manager) apply(conf *config.Config) error {
+	if level, err := log.ParseLevel(conf.LogLevel); err == nil {
+		log.SetLevel(level)
+	}
+
+	if m.conf != nil {
+		if changed := changedSettings(m.conf, conf, restartSettings); len(changed) > 0 {
+			log.WithField("settings", changed).Warn("Settings changed, restart stargazer to apply them")
+			conf = keepSettings(conf, m.conf, restartSettings)
+		}
+		if changed := changedSettings(m.conf, conf, etcdSettings); len(changed) > 0 {
+			if store, err := m.newStore(conf); err != nil {
+				log.WithError(err).Error("Failed to connect to the datastore, keeping the current etcd settings")
+				conf = keepSettings(conf, m.conf, etcdSettings)
+			} else {
+				log.WithField("settings", changed).Info("etcd settings changed, restarting controllers")
+				m.stopAll()
+				m.setStore(store)
+			}
+		}
+		if changed := changedSettings(m.conf, conf, informerSettings); len(changed) > 0 {
+			if informers, err := NewInformers(conf, m.kubeClient, m.coreClient, m.dynClient); err != nil {
+				log.WithError(err).Error("Failed to create informers, keeping the current informer settings")
+				conf = keepSettings(conf, m.conf, informerSettings)
+			} else {
+				log.WithField("settings", changed).Info("Informer settings changed, restarting controllers")
+				m.stopAll()
+				close(m.informerStopCh)
+				m.informers = informers
+				m.informerStopCh = make(chan struct{})
+			}
+		}
+	}
+
+	v := reflect.ValueOf(conf.Controllers)
 	ctrlType := v.Type()
 	for i := 0; i < v.NumField(); i++ {
-		if v.Field(i).Interface() == true {
-			log.Infof("Enabling controller for: %s", ctrlType.Field(i).Name)
-			// create handler
-			thisHandler, ok := handlers.Map[ctrlType.Field(i).Name]
-			if !ok {
-				log.Fatalf("Unsupported handler for controller: %s", ctrlType.Field(i).Name)
-			}
-			if err := thisHandler.Init(conf, etcdClient, ctx); err != nil {
-				log.Fatalf("Failed to init handler: %s", ctrlType.Field(i).Name)
+		name := ctrlType.Field(i).Name
+		enabled := v.Field(i).Bool()
+		c, running := m.controllers[name]
+		if running && (!enabled || c.workers != conf.Workers(name) || m.dependencyToggled(name, conf)) {
+			m.stop(name)
+			running = false
+		}
+		if !enabled || running {
+			continue
+		}
+		if err := m.start(name, conf); err != nil {
+			if m.conf == nil {
+				return err
 			}
-			c := Start(conf, kubeClient, thisHandler, stopCh)
-			defer c.queue.ShutDown()
-			log.Infof("Controller started: %s", ctrlType.Field(i).Name)
+			log.WithError(err).Error("Failed to start controller")
+		}
+	}
+	m.conf = conf
+	return nil
+}
+
+// start creates a new handler for the controller and starts it. Must be called with the lock held.
+func (m *manager) start(name string, conf *config.Config) error {
+	log.Infof("Enabling controller for: %s", name)
+	// create handler
+	h, ok := handlers.Map[name]
+	if !ok {
+		return fmt.Errorf("unsupported handler for controller: %s", name)
+	}
+	thisHandler := reflect.New(reflect.TypeOf(h).Elem()).Interface().(handlers.Handler)
+	if err := thisHandler.Init(conf, m.etcdClient, m.ctx); err != nil {
+		return fmt.Errorf("failed to init handler: %s: %v", name, err)
+	}
+	c := Start(name, m.kubeClient, m.informers, thisHandler, conf.Workers(name), m.informerStopCh)
+	for _, dep := range requeueOn[name] {
+		if conf.Enabled(dep) {
+			m.informers.dispatcher(dep).addDependent(name, c)
 		}
 	}
+	m.controllers[name] = c
+	log.Infof("Controller started: %s", name)
+	return nil
+}
 
-	<-stopCh
+// dependencyToggled returns true if a controller whose objects the controller reads was enabled
+// or disabled, the controller is then restarted to watch them or stop watching them.
+func (m *manager) dependencyToggled(name string, conf *config.Config) bool {
+	for _, dep := range requeueOn[name] {
+		if m.conf.Enabled(dep) != conf.Enabled(dep) {
+			return true
+		}
+	}
+	return false
 }
 
-// Start prepares a watcher and run corresponding controllers. Non-blocking. Returns new controller object.
-func Start(conf *config.Config, kubeClient *nimbessclientset.Clientset, eventHandler handlers.Handler,
-	stopCh <-chan struct{}) *Controller {
+// stop stops a running controller once it processed its queued events, or after the
+// ShutdownGracePeriod. Must be called with the lock held.
+func (m *manager) stop(name string) {
+	c := m.controllers[name]
+	m.informers.release(name, c)
+	c.ShutDown()
+	if !c.Wait(m.conf.ShutdownGracePeriod) {
+		log.Warnf("Controller not drained within %s: %s", m.conf.ShutdownGracePeriod, name)
+	}
+	delete(m.controllers, name)
+	log.Infof("Controller stopped: %s", name)
+}
 
-	var informer cache.SharedIndexInformer
-	var resType string
+// stopAll stops all running controllers, giving them the ShutdownGracePeriod in total to process
+// their queued events. Returns false if some were not drained. Must be called with the lock held.
+func (m *manager) stopAll() bool {
+	if len(m.controllers) == 0 {
+		return true
+	}
+	for name, c := range m.controllers {
+		m.informers.release(name, c)
+		c.ShutDown()
+	}
+	drained := true
+	deadline := time.Now().Add(m.conf.ShutdownGracePeriod)
+	for name, c := range m.controllers {
+		if !c.Wait(time.Until(deadline)) {
+			log.Warnf("Controller not drained within %s: %s", m.conf.ShutdownGracePeriod, name)
+			drained = false
+		}
+		delete(m.controllers, name)
+		log.Infof("Controller stopped: %s", name)
+	}
+	return drained
+}
 
-	nimbessInformerFactory := unpinformer.NewSharedInformerFactory(kubeClient, time.Second*30)
+// setStore replaces the datastore client, closing the previous one. Must be called with the lock
+// held or before the manager is started.
+func (m *manager) setStore(etcdClient etcdv3.Client) {
+	if m.storeStopCh != nil {
+		close(m.storeStopCh)
+	}
+	if m.etcdClient != nil {
+		if err := m.etcdClient.Close(); err != nil {
+			log.WithError(err).Debug("Failed to close previous datastore client")
+		}
+	}
+	m.etcdClient = etcdClient
+	m.storeStopCh = make(chan struct{})
+	go m.restoreOnLeaseExpiry(etcdClient.LeaseExpired(), m.storeStopCh)
+}
+
+// restoreOnLeaseExpiry writes all watched objects again when the etcd lease holding them was lost.
+func (m *manager) restoreOnLeaseExpiry(expired <-chan struct{}, storeStopCh <-chan struct{}) {
+	if expired == nil {
+		return
+	}
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case <-storeStopCh:
+			return
+		case <-expired:
+			m.lock.Lock()
+			for _, c := range m.controllers {
+				c.requeueAll()
+			}
+			m.lock.Unlock()
+		}
+	}
+}
 
-	if conf.Controllers.UNP {
-		informer = nimbessInformerFactory.Nimbess().V1().UnifiedNetworkPolicies().Informer()
-		resType = "unp"
+// Start prepares a watcher using the shared informers and run corresponding controllers. Non-blocking.
+// Returns new controller object.
+func Start(name string, kubeClient *nimbessclientset.Clientset, informers *Informers, eventHandler handlers.Handler,
+	workers int, stopCh <-chan struct{}) *Controller {
+
+	informerFunc, ok := informerFuncs[name]
+	if !ok {
+		log.Fatalf("Unsupported informer for controller: %s", name)
+	}
+	resType := strings.ToLower(name)
+	informer := informerFunc(informers)
+	if h, ok := eventHandler.(handlers.CacheHandler); ok {
+		h.SetCache(informer.GetIndexer())
+	}
+	if h, ok := eventHandler.(handlers.ClientHandler); ok {
+		h.SetClient(kubeClient, informers.Nimbess)
+	}
+	if h, ok := eventHandler.(handlers.CoreHandler); ok {
+		h.SetCore(informers.Core)
+	}
+	if h, ok := eventHandler.(handlers.DynamicHandler); ok {
+		h.SetDynamic(informers.Dynamic)
 	}
 	c := newResourceController(kubeClient, eventHandler, informer, resType)
+	c.workers = workers
+	c.inScope = informers.InScope
+	informers.dispatcher(name).setController(c)
 
+	// Start the informer registered above, informers already running are left untouched.
+	informers.Start(stopCh)
 	if err := c.Run(stopCh); err != nil {
 		log.Fatalf("Error running controller: %s, error: %v", resType, err)
 	}
+	// Objects added before the controller was dispatched to, e.g. when it is restarted, are queued.
+	c.requeueAll()
 	return c
 }
 
-func newResourceController(client *nimbessclientset.Clientset, eventHandler handlers.Handler, informer cache.SharedIndexInformer, resourceType string) *Controller {
+func newResourceController(client *nimbessclientset.Clientset, eventHandler handlers.Handler, informer cache.SharedIndexInformer,
+	resourceType string) *Controller {
 	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
-	var newEvent Event
-	var err error
-	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc: func(obj interface{}) {
-			newEvent.key, err = cache.MetaNamespaceKeyFunc(obj)
-			if err != nil {
-				utilruntime.HandleError(err)
-			}
-			newEvent.eventType = "create"
-			newEvent.resourceType = resourceType
-			log.WithField("pkg", "stargazer-"+resourceType).Infof("Processing add to %v: %s", resourceType, newEvent.key)
-			queue.Add(newEvent)
-		},
-		UpdateFunc: func(old, new interface{}) {
-			newEvent.key, err = cache.MetaNamespaceKeyFunc(old)
-			newEvent.eventType = "update"
-			newEvent.resourceType = resourceType
-			log.WithField("pkg", "stargazer-"+resourceType).Infof("Processing update to %v: %s", resourceType, newEvent.key)
-			if err == nil {
-				queue.Add(newEvent)
-			}
-		},
-		DeleteFunc: func(obj interface{}) {
-			newEvent.key, err = cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
-			newEvent.eventType = "delete"
-			newEvent.resourceType = resourceType
-			newEvent.namespace = utils.GetObjectMetaData(obj).Namespace
-			log.WithField("pkg", "stargazer-"+resourceType).Infof("Processing delete to %v: %s", resourceType, newEvent.key)
-			if err == nil {
-				queue.Add(newEvent)
-			}
-		},
-	})
-
 	return &Controller{
 		logger:       log.WithField("pkg", "stargazer-"+resourceType),
 		clientset:    client,
 		informer:     informer,
 		queue:        queue,
 		eventHandler: eventHandler,
+		resourceType: resourceType,
+		workers:      1,
 	}
 }
 
@@ -154,8 +436,6 @@ func (c *Controller) Run(stopCh <-chan struct{}) error {
 	c.logger.Info("Starting stargazer controller")
 	//serverStartTime = time.Now().Local()
 
-	go c.informer.Run(stopCh)
-
 	if !cache.WaitForCacheSync(stopCh, c.informer.HasSynced) {
 		utilruntime.HandleError(fmt.Errorf("timed out waiting for caches to sync"))
 		return fmt.Errorf("failed to wait for caches to sync")
@@ -163,11 +443,76 @@ func (c *Controller) Run(stopCh <-chan struct{}) error {
 
 	c.logger.Info("Stargazer controller synced and ready")
 
-	go wait.Until(c.runWorker, time.Second, stopCh)
-	c.logger.Info("Stargazer controller started")
+	c.wg.Add(c.workers)
+	for i := 0; i < c.workers; i++ {
+		go func() {
+			defer c.wg.Done()
+			// runWorker returns once the queue is shut down and drained, a worker that did
+			// not start yet still processes the queued events.
+			c.runWorker()
+		}()
+	}
+	c.logger.WithField("workers", c.workers).Info("Stargazer controller started")
 	return nil
 }
 
+// ShutDown shuts the queue down, the workers stop once they processed the queued events. Events
+// still dispatched to the controller are dropped by the shut down queue.
+func (c *Controller) ShutDown() {
+	c.queue.ShutDown()
+}
+
+// Wait waits for the workers to stop after ShutDown. Returns false if they are still running
+// after the timeout.
+func (c *Controller) Wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// enqueue queues an event for the object.
+func (c *Controller) enqueue(eventType string, obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	e := Event{key: key, eventType: eventType, resourceType: c.resourceType}
+	if eventType == "delete" {
+		e.namespace = utils.GetObjectMetaData(obj).Namespace
+	}
+	c.logger.Infof("Processing %s to %v: %s", eventType, c.resourceType, key)
+	c.queue.Add(e)
+}
+
+// requeueAll queues a create event for every object in the informer cache that is in scope. The
+// informers watch all namespaces when several are watched, the others are left out here as they
+// are by the dispatcher.
+func (c *Controller) requeueAll() {
+	c.logger.Info("Requeueing all objects")
+	for _, obj := range c.informer.GetStore().List() {
+		if c.inScope != nil && !c.inScope(obj) {
+			continue
+		}
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil {
+			utilruntime.HandleError(err)
+			continue
+		}
+		c.queue.Add(Event{key: key, eventType: "create", resourceType: c.resourceType})
+	}
+}
+
 // HasSynced is required for the cache.Controller interface.
 func (c *Controller) HasSynced() bool {
 	return c.informer.HasSynced()
@@ -211,10 +556,14 @@ func (c *Controller) processNextItem() bool {
 }
 
 func (c *Controller) processItem(newEvent Event) error {
-	obj, _, err := c.informer.GetIndexer().GetByKey(newEvent.key)
+	obj, exists, err := c.informer.GetIndexer().GetByKey(newEvent.key)
 	if err != nil {
 		return fmt.Errorf("error fetching object with key %s from store: %v", newEvent.key, err)
 	}
+	if !exists && newEvent.eventType != "delete" {
+		// deleted since, handled by the delete event
+		return nil
+	}
 	// get object's metadata
 	//objectMeta := utils.GetObjectMetaData(obj)
 
@@ -225,23 +574,15 @@ func (c *Controller) processItem(newEvent Event) error {
 		// Could be Replaced by using Delta or DeltaFIFO
 		//if objectMeta.CreationTimestamp.Sub(serverStartTime).Seconds() > 0 {
 		c.logger.Debug("Calling create handler")
-		c.eventHandler.ObjectCreated(obj)
-		return nil
+		return c.eventHandler.ObjectCreated(obj)
 		//}
 	case "update":
-		c.logger.Debug("Inside update handler")
-		/**
-		kbEvent := event.Event{
-			Kind: newEvent.resourceType,
-			Name: newEvent.key,
-		}
-		c.eventHandler.ObjectUpdated(obj, kbEvent)
-		*/
-		return nil
+		// The queue only holds the key, the old object is not known.
+		c.logger.Debug("Calling update handler")
+		return c.eventHandler.ObjectUpdated(nil, obj)
 	case "delete":
 		c.logger.Debug("Inside delete handler")
-		c.eventHandler.ObjectDeleted(newEvent.key)
-		return nil
+		return c.eventHandler.ObjectDeleted(newEvent.key)
 	}
 	return nil
 }
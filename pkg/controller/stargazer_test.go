@@ -0,0 +1,244 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	nimbessfake "github.com/nimbess/stargazer/pkg/client/clientset/versioned/fake"
+	"github.com/nimbess/stargazer/pkg/config"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	log "github.com/sirupsen/logrus"
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+// recordingHandler records the calls of the controller.
+type recordingHandler struct {
+	calls []string
+	objs  []interface{}
+}
+
+func (h *recordingHandler) Init(c *config.Config, etcdClient etcdv3.Client, ctx context.Context) error {
+	return nil
+}
+
+func (h *recordingHandler) ObjectCreated(obj interface{}) error {
+	h.calls = append(h.calls, "created")
+	h.objs = append(h.objs, obj)
+	return nil
+}
+
+func (h *recordingHandler) ObjectDeleted(name string) error {
+	h.calls = append(h.calls, "deleted "+name)
+	h.objs = append(h.objs, nil)
+	return nil
+}
+
+func (h *recordingHandler) ObjectUpdated(oldObj, newObj interface{}) error {
+	if oldObj != nil {
+		h.calls = append(h.calls, "updated with old object")
+	} else {
+		h.calls = append(h.calls, "updated")
+	}
+	h.objs = append(h.objs, newObj)
+	return nil
+}
+
+func (h *recordingHandler) TestHandler() {}
+
+func TestController_processItem(t *testing.T) {
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{}, &v1.Service{}, 0, cache.Indexers{})
+	svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"}}
+	if err := informer.GetIndexer().Add(svc); err != nil {
+		t.Fatal(err)
+	}
+	h := &recordingHandler{}
+	c := &Controller{logger: log.WithField("pkg", "stargazer-test"), informer: informer, eventHandler: h}
+
+	for _, e := range []Event{
+		{key: "default/web", eventType: "create"},
+		{key: "default/web", eventType: "update"},
+		// deleted since, left to the delete event
+		{key: "default/gone", eventType: "update"},
+		{key: "default/gone", eventType: "delete"},
+	} {
+		if err := c.processItem(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := []string{"created", "updated", "deleted default/gone"}
+	if !reflect.DeepEqual(h.calls, expected) {
+		t.Errorf("Expected: %v\nGot: %v", expected, h.calls)
+	}
+	if len(h.objs) == len(expected) && (h.objs[0] != svc || h.objs[1] != svc) {
+		t.Errorf("Expected the cached object, got %v", h.objs)
+	}
+}
+
+func TestInformers_dispatcher(t *testing.T) {
+	informers, err := NewInformers(config.NewConfig(), nimbessfake.NewSimpleClientset(), fake.NewSimpleClientset(),
+		dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := informers.dispatcher("Service")
+	if informers.dispatcher("Service") != d {
+		t.Fatal("Expected the dispatcher to be registered once")
+	}
+	ingressInformer := informerFuncs["Ingress"](informers)
+	ing := &networking.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "shop"}}
+	if err := ingressInformer.GetIndexer().Add(ing); err != nil {
+		t.Fatal(err)
+	}
+	h := &recordingHandler{}
+	services := newResourceController(nil, h, informerFuncs["Service"](informers), "service")
+	ingresses := newResourceController(nil, h, ingressInformer, "ingress")
+	d.setController(services)
+	d.addDependent("Ingress", ingresses)
+
+	svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", ResourceVersion: "1"}}
+	d.OnAdd(svc)
+	if services.queue.Len() != 1 || ingresses.queue.Len() != 1 {
+		t.Errorf("Expected the service and the ingress to be queued, got %d and %d",
+			services.queue.Len(), ingresses.queue.Len())
+	}
+
+	// the restarted controllers replace the stopped ones
+	informers.release("Service", services)
+	informers.release("Ingress", ingresses)
+	restarted := newResourceController(nil, h, informerFuncs["Service"](informers), "service")
+	informers.dispatcher("Service").setController(restarted)
+	updated := svc.DeepCopy()
+	updated.ResourceVersion = "2"
+	d.OnUpdate(svc, updated)
+	if restarted.queue.Len() != 1 {
+		t.Errorf("Expected the update to be queued to the restarted controller, got %d", restarted.queue.Len())
+	}
+	if services.queue.Len() != 1 || ingresses.queue.Len() != 1 {
+		t.Errorf("Expected no events for the stopped controllers, got %d and %d",
+			services.queue.Len(), ingresses.queue.Len())
+	}
+}
+
+// closingClient records whether the datastore client was closed.
+type closingClient struct {
+	*etcdv3.MemoryClient
+	closed bool
+}
+
+func (c *closingClient) Close() error {
+	c.closed = true
+	return c.MemoryClient.Close()
+}
+
+// blockingHandler blocks on the deletion of default/stuck until its context is cancelled.
+type blockingHandler struct {
+	recordingHandler
+	ctx       context.Context
+	cancelled chan struct{}
+}
+
+func (h *blockingHandler) Init(c *config.Config, etcdClient etcdv3.Client, ctx context.Context) error {
+	h.ctx = ctx
+	h.cancelled = make(chan struct{})
+	return nil
+}
+
+func (h *blockingHandler) ObjectDeleted(name string) error {
+	if name != "default/stuck" {
+		return h.recordingHandler.ObjectDeleted(name)
+	}
+	<-h.ctx.Done()
+	close(h.cancelled)
+	return h.ctx.Err()
+}
+
+func TestManager_shutdown(t *testing.T) {
+	conf := config.NewConfig()
+	conf.ShutdownGracePeriod = 100 * time.Millisecond
+	informers, err := NewInformers(conf, nimbessfake.NewSimpleClientset(), fake.NewSimpleClientset(),
+		dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &closingClient{MemoryClient: etcdv3.NewMemoryClient()}
+	m := &manager{conf: conf, informers: informers, informerStopCh: make(chan struct{}), controllers: map[string]*Controller{}}
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	m.setStore(store)
+	h := &blockingHandler{}
+	if err := h.Init(conf, store, m.ctx); err != nil {
+		t.Fatal(err)
+	}
+	c := Start("Service", nil, informers, h, 1, m.informerStopCh)
+	m.controllers["Service"] = c
+	c.queue.Add(Event{key: "default/web", eventType: "delete"})
+	c.queue.Add(Event{key: "default/stuck", eventType: "delete"})
+
+	start := time.Now()
+	m.shutdown()
+	if elapsed := time.Since(start); elapsed < conf.ShutdownGracePeriod {
+		t.Errorf("Expected the stuck handler to be given the grace period, shut down after %s", elapsed)
+	}
+	select {
+	case <-h.cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the stuck handler to be cancelled")
+	}
+	if expected := []string{"deleted default/web"}; !reflect.DeepEqual(h.calls, expected) {
+		t.Errorf("Expected the queued event to be processed, expected: %v\nGot: %v", expected, h.calls)
+	}
+	if !store.closed {
+		t.Error("Expected the datastore client to be closed")
+	}
+	if len(m.controllers) != 0 {
+		t.Errorf("Expected the controllers to be stopped, got %v", m.controllers)
+	}
+}
+
+func TestController_requeueAll(t *testing.T) {
+	conf := config.NewConfig()
+	conf.Namespaces = "default,prod"
+	informers, err := NewInformers(conf, nimbessfake.NewSimpleClientset(), fake.NewSimpleClientset(),
+		dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	informer := informerFuncs["Service"](informers)
+	for _, ns := range []string{"default", "kube-system"} {
+		svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "web"}}
+		if err := informer.GetIndexer().Add(svc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := newResourceController(nil, &recordingHandler{}, informer, "service")
+	c.inScope = informers.InScope
+
+	c.requeueAll()
+	if c.queue.Len() != 1 {
+		t.Fatalf("Expected only the service in scope to be queued, got %d events", c.queue.Len())
+	}
+	if e, _ := c.queue.Get(); e.(Event).key != "default/web" {
+		t.Errorf("Expected default/web to be queued, got %v", e)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	nimbessclientset "github.com/nimbess/stargazer/pkg/client/clientset/versioned"
+	unpinformer "github.com/nimbess/stargazer/pkg/client/informers/externalversions/unp/v1"
+	"github.com/nimbess/stargazer/pkg/config"
+	unpv1 "github.com/nimbess/stargazer/pkg/crd/api/unp/v1"
+	"reflect"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+)
+
+// unpConfigSyncTimeout is how long to wait for the UnpConfig before starting the controllers
+// with the configuration file, e.g. when the UnpConfig CRD is not registered.
+const unpConfigSyncTimeout = 10 * time.Second
+
+// ApplyUnpConfig returns a copy of conf with the settings of the UnpConfig spec applied.
+// Settings left empty in the spec keep the value of conf. Fails if the resulting configuration
+// is invalid.
+func ApplyUnpConfig(conf *config.Config, spec *unpv1.UnpConfigSpec) (*config.Config, error) {
+	c := *conf
+	if spec.LogLevel != "" {
+		if _, err := log.ParseLevel(spec.LogLevel); err != nil {
+			return nil, fmt.Errorf("invalid logLevel: %v", err)
+		}
+		c.LogLevel = spec.LogLevel
+	}
+
+	controllers := reflect.ValueOf(&c.Controllers).Elem()
+	for name, enabled := range spec.Controllers {
+		field, ok := config.ControllerName(name)
+		if !ok {
+			return nil, fmt.Errorf("unsupported controller: %s", name)
+		}
+		controllers.FieldByName(field).SetBool(enabled)
+	}
+	for name, workers := range spec.Workers {
+		field, ok := config.ControllerName(name)
+		if !ok {
+			return nil, fmt.Errorf("unsupported controller: %s", name)
+		}
+		if workers < 1 {
+			return nil, fmt.Errorf("invalid workers for %s: %d", name, workers)
+		}
+		v := reflect.ValueOf(&c).Elem().FieldByName(field + "Workers")
+		if !v.IsValid() {
+			return nil, fmt.Errorf("workers not configurable for controller: %s", name)
+		}
+		v.SetInt(int64(workers))
+	}
+
+	if etcd := spec.Etcd; etcd != nil {
+		if etcd.Endpoints != "" {
+			c.EtcdEndpoints = etcd.Endpoints
+		}
+		durations := []struct {
+			name  string
+			value *metav1.Duration
+			field *time.Duration
+		}{
+			{"dialTimeout", etcd.DialTimeout, &c.EtcdDialTimeout},
+			{"leaseTTL", etcd.LeaseTTL, &c.EtcdLeaseTTL},
+			{"batchWindow", etcd.BatchWindow, &c.EtcdBatchWindow},
+		}
+		for _, d := range durations {
+			if d.value == nil {
+				continue
+			}
+			if d.value.Duration < 0 {
+				return nil, fmt.Errorf("invalid etcd %s: %s", d.name, d.value.Duration)
+			}
+			*d.field = d.value.Duration
+		}
+		if etcd.BatchMaxOps < 0 {
+			return nil, fmt.Errorf("invalid etcd batchMaxOps: %d", etcd.BatchMaxOps)
+		} else if etcd.BatchMaxOps > 0 {
+			c.EtcdBatchMaxOps = etcd.BatchMaxOps
+		}
+	}
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %v", err)
+	}
+	return &c, nil
+}
+
+// watchUnpConfig overrides the configuration of the manager with the UnpConfig of the given name,
+// and waits for a limited time for the initial UnpConfig to be known.
+func (m *manager) watchUnpConfig(kubeClient nimbessclientset.Interface, name string) {
+	informer := unpinformer.NewFilteredUnpConfigInformer(kubeClient, 0, cache.Indexers{},
+		func(opts *metav1.ListOptions) {
+			opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		})
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			m.applyUnpConfig(kubeClient, obj.(*unpv1.UnpConfig))
+		},
+		UpdateFunc: func(old, new interface{}) {
+			// status updates leave the spec unchanged
+			if !reflect.DeepEqual(old.(*unpv1.UnpConfig).Spec, new.(*unpv1.UnpConfig).Spec) {
+				m.applyUnpConfig(kubeClient, new.(*unpv1.UnpConfig))
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			m.setOverride(nil)
+		},
+	})
+	go informer.Run(m.stopCh)
+
+	ctx, cancel := context.WithTimeout(context.Background(), unpConfigSyncTimeout)
+	defer cancel()
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		log.WithField("name", name).Warn("UnpConfig not synced, starting with the configuration file")
+	}
+}
+
+// applyUnpConfig overrides the configuration with the UnpConfig and records in its status whether
+// it was applied or rejected.
+func (m *manager) applyUnpConfig(kubeClient nimbessclientset.Interface, unpConfig *unpv1.UnpConfig) {
+	status := unpv1.UnpConfigStatus{ObservedGeneration: unpConfig.Generation}
+	if err := m.setOverride(unpConfig.Spec.DeepCopy()); err != nil {
+		status.Error = err.Error()
+	}
+	if unpConfig.Status == status {
+		return
+	}
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := kubeClient.NimbessV1().UnpConfigs().Get(unpConfig.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if latest.Generation != unpConfig.Generation {
+			// the spec changed since, its status is set by the next update
+			return nil
+		}
+		latest.Status = status
+		_, err = kubeClient.NimbessV1().UnpConfigs().UpdateStatus(latest)
+		return err
+	})
+	if apierrors.IsNotFound(err) {
+		return
+	}
+	if err != nil {
+		log.WithError(err).Warnf("Failed to update status of UnpConfig %s", unpConfig.Name)
+	}
+}
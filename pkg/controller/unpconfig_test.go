@@ -0,0 +1,92 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller_test
+
+import (
+	"github.com/nimbess/stargazer/pkg/config"
+	"github.com/nimbess/stargazer/pkg/controller"
+	unpv1 "github.com/nimbess/stargazer/pkg/crd/api/unp/v1"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type unpconfigtest struct {
+	testName string
+	spec     unpv1.UnpConfigSpec
+	expected func(c *config.Config)
+}
+
+var unpConfigTests = []unpconfigtest{
+	// pass: empty spec keeps the configuration file
+	{"apply 1", unpv1.UnpConfigSpec{}, func(c *config.Config) {}},
+	// pass: settings override the configuration file
+	{"apply 2", unpv1.UnpConfigSpec{
+		LogLevel:    "debug",
+		Controllers: map[string]bool{"unp": false},
+		Workers:     map[string]int{"UNP": 3},
+		Etcd: &unpv1.UnpConfigEtcdSpec{
+			Endpoints:   "http://etcd:2379",
+			LeaseTTL:    &metav1.Duration{Duration: 0},
+			BatchWindow: &metav1.Duration{Duration: time.Millisecond},
+		},
+	}, func(c *config.Config) {
+		c.LogLevel = "debug"
+		c.Controllers.UNP = false
+		c.UNPWorkers = 3
+		c.EtcdEndpoints = "http://etcd:2379"
+		c.EtcdLeaseTTL = 0
+		c.EtcdBatchWindow = time.Millisecond
+	}},
+	// fail: unsupported controller
+	{"apply 3", unpv1.UnpConfigSpec{Controllers: map[string]bool{"foo": true}}, nil},
+	// fail: invalid workers
+	{"apply 4", unpv1.UnpConfigSpec{Workers: map[string]int{"unp": 0}}, nil},
+	// fail: invalid log level
+	{"apply 5", unpv1.UnpConfigSpec{LogLevel: "loud"}, nil},
+	// fail: negative duration
+	{"apply 6", unpv1.UnpConfigSpec{Etcd: &unpv1.UnpConfigEtcdSpec{
+		DialTimeout: &metav1.Duration{Duration: -time.Second}}}, nil},
+	// fail: invalid configuration once merged
+	{"apply 7", unpv1.UnpConfigSpec{Etcd: &unpv1.UnpConfigEtcdSpec{
+		DialTimeout: &metav1.Duration{Duration: 0}}}, nil},
+}
+
+func TestApplyUnpConfig(t *testing.T) {
+	for _, test := range unpConfigTests {
+		base := config.NewConfig()
+		base.EtcdLeaseTTL = time.Minute
+		conf, err := controller.ApplyUnpConfig(base, &test.spec)
+		if test.expected == nil {
+			if err == nil {
+				t.Errorf("%s: expected error, got %+v", test.testName, conf)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.testName, err)
+			continue
+		}
+		expected := *base
+		test.expected(&expected)
+		if *conf != expected {
+			t.Errorf("%s:\nExpected: %+v\nGot: %+v", test.testName, expected, *conf)
+		}
+		if base.EtcdLeaseTTL != time.Minute || base.LogLevel != "info" {
+			t.Errorf("%s: configuration file modified: %+v", test.testName, *base)
+		}
+	}
+}
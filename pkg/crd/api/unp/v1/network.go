@@ -0,0 +1,87 @@
+package v1
+
+import (
+	log "github.com/sirupsen/logrus"
+	apiextensionv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+)
+
+const (
+	NetworkCRDPlural   string = "networks"
+	FullNetworkCRDName string = NetworkCRDPlural + "." + CRDGroup
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Network is a cluster scoped network, referenced by name by the Network fields of the UNPs.
+type Network struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              NetworkSpec   `json:"spec"`
+	Status            NetworkStatus `json:"status,omitempty"`
+}
+
+// NetworkSpec describes the network. VLAN and VNI are exclusive, a network with neither, both 0, is
+// flat and untagged.
+// The network is attached to the nodes matching NodeSelector, all nodes if empty.
+type NetworkSpec struct {
+	CIDRs        []string             `json:"cidrs,omitempty"`
+	VLAN         int                  `json:"vlan,omitempty"`
+	VNI          int                  `json:"vni,omitempty"`
+	DataPlane    string               `json:"dataPlane,omitempty"`
+	NodeSelector metav1.LabelSelector `json:"nodeSelector,omitempty"`
+}
+
+// NetworkStatus is maintained by stargazer.
+type NetworkStatus struct {
+	// Policies is the number of UNPs referencing the network.
+	Policies int `json:"policies"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type NetworkList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []Network `json:"items"`
+}
+
+// CreateNetworkCRD registers the cluster scoped Network CRD, with a status subresource. An existing
+// CRD is kept along with its objects.
+func CreateNetworkCRD(clientset *clientset.Clientset) error {
+	ver := apiextensionv1beta1.CustomResourceDefinitionVersion{Name: CRDVersion, Served: true, Storage: true}
+	crd := &apiextensionv1beta1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: FullNetworkCRDName},
+		Spec: apiextensionv1beta1.CustomResourceDefinitionSpec{
+			Group:    CRDGroup,
+			Versions: []apiextensionv1beta1.CustomResourceDefinitionVersion{ver},
+			Scope:    apiextensionv1beta1.ClusterScoped,
+			Names: apiextensionv1beta1.CustomResourceDefinitionNames{
+				Plural: NetworkCRDPlural,
+				Kind:   reflect.TypeOf(Network{}).Name(),
+			},
+			Subresources: &apiextensionv1beta1.CustomResourceSubresources{
+				Status: &apiextensionv1beta1.CustomResourceSubresourceStatus{},
+			},
+			AdditionalPrinterColumns: []apiextensionv1beta1.CustomResourceColumnDefinition{
+				{Name: "CIDRs", Type: "string", JSONPath: ".spec.cidrs"},
+				{Name: "DataPlane", Type: "string", JSONPath: ".spec.dataPlane"},
+				{Name: "Policies", Type: "integer", JSONPath: ".status.policies"},
+			},
+		},
+	}
+	_, err := clientset.ApiextensionsV1beta1().CustomResourceDefinitions().Create(crd)
+	if err != nil && apierrors.IsAlreadyExists(err) {
+		log.Info("Network CRD already registered")
+		return nil
+	}
+	if err == nil {
+		log.Info("Network CRD successfully registered")
+	}
+	return err
+}
@@ -0,0 +1,67 @@
+package v1
+
+import (
+	log "github.com/sirupsen/logrus"
+	apiextensionv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+)
+
+const (
+	StateCRDPlural   string = "nimbessstates"
+	FullStateCRDName string = StateCRDPlural + "." + CRDGroup
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NimbessState holds a single key of the Nimbess data model when the Kubernetes API is used
+// as the datastore instead of etcd.
+type NimbessState struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              NimbessStateSpec `json:"spec"`
+}
+
+// NimbessStateSpec holds the datastore path of the key and its serialized value.
+// Values wrapped in a binary envelope are held by Data instead of Value.
+type NimbessStateSpec struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Data  []byte `json:"data,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type NimbessStateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []NimbessState `json:"items"`
+}
+
+// CreateStateCRD registers the NimbessState CRD. An existing CRD is kept along with its objects.
+func CreateStateCRD(clientset *clientset.Clientset) error {
+	ver := apiextensionv1beta1.CustomResourceDefinitionVersion{Name: CRDVersion, Served: true, Storage: true}
+	crd := &apiextensionv1beta1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: FullStateCRDName},
+		Spec: apiextensionv1beta1.CustomResourceDefinitionSpec{
+			Group:    CRDGroup,
+			Versions: []apiextensionv1beta1.CustomResourceDefinitionVersion{ver},
+			Scope:    apiextensionv1beta1.NamespaceScoped,
+			Names: apiextensionv1beta1.CustomResourceDefinitionNames{
+				Plural: StateCRDPlural,
+				Kind:   reflect.TypeOf(NimbessState{}).Name(),
+			},
+		},
+	}
+	_, err := clientset.ApiextensionsV1beta1().CustomResourceDefinitions().Create(crd)
+	if err != nil && apierrors.IsAlreadyExists(err) {
+		log.Info("NimbessState CRD already registered")
+		return nil
+	}
+	if err == nil {
+		log.Info("NimbessState CRD successfully registered")
+	}
+	return err
+}
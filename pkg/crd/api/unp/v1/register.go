@@ -49,6 +49,12 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&UnifiedNetworkPolicy{},
 		&UnifiedNetworkPolicyList{},
+		&NimbessState{},
+		&NimbessStateList{},
+		&Network{},
+		&NetworkList{},
+		&UnpConfig{},
+		&UnpConfigList{},
 	)
 
 	scheme.AddKnownTypes(SchemeGroupVersion,
@@ -1,10 +1,12 @@
 package v1
 
 import (
+	"encoding/json"
 	log "github.com/sirupsen/logrus"
 	apiextensionv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"reflect"
 )
@@ -31,6 +33,8 @@ type DefaultPolicy struct {
 	Action string `json:"action,omitempty"`
 }
 
+// URLFilter applies the action to the URLs. The host of a URL may name an in-cluster service as
+// <name>.<namespace>, which the agents resolve from the services published by stargazer.
 type URLFilter struct {
 	Urls        []string             `json:"urls,omitempty"`
 	Action      string               `json:"action,omitempty"`
@@ -43,11 +47,60 @@ type L7Policy struct {
 	UrlFilter URLFilter     `json:"urlFilter,omitempty"`
 }
 
+// Enforcement modes of the UNPs. In audit mode the agents log the traffic the policy would deny
+// instead of denying it, disabled policies are ignored by the agents.
+const (
+	EnforcementModeEnforce  = "enforce"
+	EnforcementModeAudit    = "audit"
+	EnforcementModeDisabled = "disabled"
+)
+
+// UnifiedNetworkPolicySpec describes the policy. EnforcementMode defaults to enforce.
 type UnifiedNetworkPolicySpec struct {
-	L7Policies  []L7Policy           `json:"l7Policies"`
-	PodSelector metav1.LabelSelector `json:"podSelector"`
-	Network     string               `json:"network"`
-	Attributes  string               `json:"attributes"`
+	L7Policies      []L7Policy           `json:"l7Policies"`
+	PodSelector     metav1.LabelSelector `json:"podSelector"`
+	Network         string               `json:"network"`
+	Attributes      PolicyAttributes     `json:"attributes"`
+	EnforcementMode string               `json:"enforcementMode,omitempty"`
+}
+
+// PolicyAttributes extend the behavior of a policy, unset attributes keep the defaults of the agents.
+type PolicyAttributes struct {
+	QoSClass  string           `json:"qosClass,omitempty"`
+	LogLevel  string           `json:"logLevel,omitempty"`
+	Audit     bool             `json:"audit,omitempty"`
+	Bandwidth *BandwidthLimits `json:"bandwidth,omitempty"`
+	// Legacy holds the opaque string of the UNPs written before the attributes were structured.
+	// Such UNPs still decode and are written without attributes.
+	Legacy string `json:"-"`
+}
+
+// MarshalJSON encodes the attributes, or the opaque string of the earlier versions if set.
+func (a PolicyAttributes) MarshalJSON() ([]byte, error) {
+	if a.Legacy != "" {
+		return json.Marshal(a.Legacy)
+	}
+	// plainAttributes has no MarshalJSON method, avoiding recursion
+	type plainAttributes PolicyAttributes
+	return json.Marshal(plainAttributes(a))
+}
+
+// UnmarshalJSON decodes the attributes, or the opaque string of the earlier versions into Legacy.
+func (a *PolicyAttributes) UnmarshalJSON(data []byte) error {
+	var legacy string
+	if err := json.Unmarshal(data, &legacy); err == nil {
+		*a = PolicyAttributes{Legacy: legacy}
+		return nil
+	}
+	// plainAttributes has no UnmarshalJSON method, avoiding recursion
+	type plainAttributes PolicyAttributes
+	return json.Unmarshal(data, (*plainAttributes)(a))
+}
+
+// BandwidthLimits limit the traffic to and from the selected pods in bits per second, e.g. "10M".
+type BandwidthLimits struct {
+	Ingress *resource.Quantity `json:"ingress,omitempty"`
+	Egress  *resource.Quantity `json:"egress,omitempty"`
 }
 
 type UnifiedNetworkPolicyStatus struct {
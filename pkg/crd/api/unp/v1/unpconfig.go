@@ -0,0 +1,96 @@
+package v1
+
+import (
+	log "github.com/sirupsen/logrus"
+	apiextensionv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+)
+
+const (
+	UnpConfigCRDPlural   string = "unpconfigs"
+	FullUnpConfigCRDName string = UnpConfigCRDPlural + "." + CRDGroup
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// UnpConfig overrides the configuration of the stargazer instances watching it at runtime.
+type UnpConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              UnpConfigSpec   `json:"spec"`
+	Status            UnpConfigStatus `json:"status,omitempty"`
+}
+
+// UnpConfigSpec holds the settings overriding the configuration file. Settings left empty keep
+// the value of the configuration file. Controllers and Workers are keyed by the lower case
+// controller name, e.g. "unp".
+type UnpConfigSpec struct {
+	LogLevel    string             `json:"logLevel,omitempty"`
+	Controllers map[string]bool    `json:"controllers,omitempty"`
+	Workers     map[string]int     `json:"workers,omitempty"`
+	Etcd        *UnpConfigEtcdSpec `json:"etcd,omitempty"`
+}
+
+// UnpConfigEtcdSpec holds the etcd settings overriding the configuration file. Credentials and
+// certificates are only read from the configuration file.
+type UnpConfigEtcdSpec struct {
+	Endpoints   string           `json:"endpoints,omitempty"`
+	DialTimeout *metav1.Duration `json:"dialTimeout,omitempty"`
+	LeaseTTL    *metav1.Duration `json:"leaseTTL,omitempty"`
+	BatchWindow *metav1.Duration `json:"batchWindow,omitempty"`
+	BatchMaxOps int              `json:"batchMaxOps,omitempty"`
+}
+
+// UnpConfigStatus is maintained by stargazer.
+type UnpConfigStatus struct {
+	// ObservedGeneration is the generation of the last spec validated by stargazer.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Error is the reason the spec was rejected, empty if it was applied.
+	Error string `json:"error,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type UnpConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []UnpConfig `json:"items"`
+}
+
+// CreateUnpConfigCRD registers the cluster scoped UnpConfig CRD. An existing CRD is kept along
+// with its objects.
+func CreateUnpConfigCRD(clientset *clientset.Clientset) error {
+	ver := apiextensionv1beta1.CustomResourceDefinitionVersion{Name: CRDVersion, Served: true, Storage: true}
+	crd := &apiextensionv1beta1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: FullUnpConfigCRDName},
+		Spec: apiextensionv1beta1.CustomResourceDefinitionSpec{
+			Group:    CRDGroup,
+			Versions: []apiextensionv1beta1.CustomResourceDefinitionVersion{ver},
+			Scope:    apiextensionv1beta1.ClusterScoped,
+			Names: apiextensionv1beta1.CustomResourceDefinitionNames{
+				Plural: UnpConfigCRDPlural,
+				Kind:   reflect.TypeOf(UnpConfig{}).Name(),
+			},
+			Subresources: &apiextensionv1beta1.CustomResourceSubresources{
+				Status: &apiextensionv1beta1.CustomResourceSubresourceStatus{},
+			},
+			AdditionalPrinterColumns: []apiextensionv1beta1.CustomResourceColumnDefinition{
+				{Name: "Error", Type: "string", JSONPath: ".status.error"},
+			},
+		},
+	}
+	_, err := clientset.ApiextensionsV1beta1().CustomResourceDefinitions().Create(crd)
+	if err != nil && apierrors.IsAlreadyExists(err) {
+		log.Info("UnpConfig CRD already registered")
+		return nil
+	}
+	if err == nil {
+		log.Info("UnpConfig CRD successfully registered")
+	}
+	return err
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package datastore creates the client of the datastore selected in the configuration.
+package datastore
+
+import (
+	"fmt"
+	"github.com/nimbess/stargazer/pkg/config"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/k8sstore"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// New returns the client of the datastore selected in the configuration. The NimbessState CRD
+// must be registered for the kubernetes datastore.
+func New(cfg *config.Config) (etcdv3.Client, error) {
+	switch cfg.Datastore {
+	case config.DatastoreEtcd:
+		c, err := etcdv3.New(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get etcd client: %s", err)
+		}
+		return c, nil
+	case config.DatastoreKubernetes:
+		k8sConfig, err := clientcmd.BuildConfigFromFlags("", cfg.Kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build kubeconfig: %s", err)
+		}
+		dynClient, err := dynamic.NewForConfig(k8sConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build kubernetes dynamic client: %s", err)
+		}
+		if cfg.EtcdLeaseTTL > 0 {
+			log.Warn("Leases are not supported by the kubernetes datastore, ignoring EtcdLeaseTTL")
+		}
+		return k8sstore.New(dynClient, cfg.DatastoreNamespace), nil
+	}
+	return nil, fmt.Errorf("unsupported datastore: %s", cfg.Datastore)
+}
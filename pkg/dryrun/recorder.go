@@ -0,0 +1,160 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dryrun records the changes stargazer would write to the datastore, without writing them.
+package dryrun
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/model"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxOperations is the number of recorded operations kept, older operations are dropped.
+const maxOperations = 1000
+
+// Operations recorded.
+const (
+	OpPut    = "put"
+	OpDelete = "delete"
+)
+
+// Operation is a write to the datastore that was not performed.
+type Operation struct {
+	Time time.Time `json:"time"`
+	Op   string    `json:"op"`
+	Key  string    `json:"key"`
+	Path string    `json:"path"`
+	// Value is the JSON representation of the value of a put.
+	Value json.RawMessage `json:"value,omitempty"`
+	// Diff is the line diff of the JSON representation of the current value and the value of the put,
+	// or of the deleted value.
+	Diff string `json:"diff,omitempty"`
+}
+
+// Recorder is a datastore client reading from the datastore it wraps and recording the writes
+// instead of performing them. Writes return the errors the datastore would have returned for the
+// current content, e.g. an exists error when creating an existing key.
+type Recorder struct {
+	etcdv3.Client
+
+	lock       sync.Mutex
+	operations []Operation
+}
+
+// NewRecorder returns a recorder reading from c.
+func NewRecorder(c etcdv3.Client) *Recorder {
+	return &Recorder{Client: c}
+}
+
+func (r *Recorder) Create(ctx context.Context, d *model.KVPair) error {
+	_, err := r.Client.Get(ctx, d.Key)
+	if err == nil {
+		return etcdv3.NewKeyExistsError(d.Key.String(), 0)
+	}
+	if !etcdv3.IsNotFound(err) {
+		return err
+	}
+	return r.recordPut(nil, d)
+}
+
+func (r *Recorder) Update(ctx context.Context, d *model.KVPair) error {
+	current, err := r.Client.Get(ctx, d.Key)
+	if err != nil {
+		return err
+	}
+	if d.Revision != "" && d.Revision != current.Revision {
+		return etcdv3.NewResourceVersionConflictsError(d.Key.String(), 0)
+	}
+	return r.recordPut(current, d)
+}
+
+func (r *Recorder) Delete(ctx context.Context, k model.Key) error {
+	current, err := r.Client.Get(ctx, k)
+	if err != nil {
+		return err
+	}
+	old, err := json.MarshalIndent(current.Value, "", "  ")
+	if err != nil {
+		return etcdv3.NewInvalidObjError(k.String(), err)
+	}
+	path, _ := model.KeyToDefaultDeletePath(k)
+	r.record(Operation{Op: OpDelete, Key: k.String(), Path: path, Diff: diff(string(old), "")})
+	return nil
+}
+
+// EnsureSchema only checks that the datastore is reachable, the schema is neither written nor migrated.
+func (r *Recorder) EnsureSchema(ctx context.Context, migrate bool) error {
+	_, err := r.Client.Get(ctx, model.SchemaVersionKey{})
+	if etcdv3.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// Operations returns the recorded operations, oldest first.
+func (r *Recorder) Operations() []Operation {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]Operation{}, r.operations...)
+}
+
+// ServeHTTP serves the recorded operations as JSON.
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r.Operations()); err != nil {
+		log.WithError(err).Debug("Failed to serve dry run operations")
+	}
+}
+
+// recordPut records a put of d over the current value, nil if the key doesn't exist.
+func (r *Recorder) recordPut(current *model.KVPair, d *model.KVPair) error {
+	path, err := model.KeyToDefaultPath(d.Key)
+	if err != nil {
+		return etcdv3.NewInvalidObjError(d.Key.String(), err)
+	}
+	value, err := json.MarshalIndent(d.Value, "", "  ")
+	if err != nil {
+		return etcdv3.NewInvalidObjError(path, err)
+	}
+	old := ""
+	if current != nil {
+		data, err := json.MarshalIndent(current.Value, "", "  ")
+		if err != nil {
+			return etcdv3.NewInvalidObjError(path, err)
+		}
+		old = string(data)
+	}
+	r.record(Operation{Op: OpPut, Key: d.Key.String(), Path: path, Value: value, Diff: diff(old, string(value))})
+	return nil
+}
+
+func (r *Recorder) record(op Operation) {
+	op.Time = time.Now().UTC()
+	log.WithFields(log.Fields{"op": op.Op, "key": op.Key, "path": op.Path}).Infof("Dry run, not written:\n%s", op.Diff)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.operations = append(r.operations, op)
+	if len(r.operations) > maxOperations {
+		r.operations = r.operations[len(r.operations)-maxOperations:]
+	}
+}
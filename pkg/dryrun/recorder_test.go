@@ -0,0 +1,77 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dryrun_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/nimbess/stargazer/pkg/dryrun"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/model"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	ctx := context.Background()
+	store := etcdv3.NewMemoryClient()
+	node1 := &model.KVPair{Key: model.NodeKey{Hostname: "node1"}, Value: &model.Node{Name: "node1", PodCIDR: "10.0.0.0/24"}}
+	if err := store.Create(ctx, node1); err != nil {
+		t.Fatal(err)
+	}
+	r := dryrun.NewRecorder(store)
+
+	if err := r.Create(ctx, node1); !etcdv3.IsExists(err) {
+		t.Errorf("Expected exists error, got %v", err)
+	}
+	updated := &model.KVPair{Key: node1.Key, Value: &model.Node{Name: "node1", PodCIDR: "10.0.1.0/24"}}
+	if err := r.Update(ctx, updated); err != nil {
+		t.Fatal(err)
+	}
+	node2 := &model.KVPair{Key: model.NodeKey{Hostname: "node2"}, Value: &model.Node{Name: "node2"}}
+	if err := r.Create(ctx, node2); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Delete(ctx, node1.Key); err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing is written
+	got, err := store.Get(ctx, node1.Key)
+	if err != nil || got.Value.(*model.Node).PodCIDR != "10.0.0.0/24" {
+		t.Errorf("Unexpected write: %+v, %v", got, err)
+	}
+	if _, err := store.Get(ctx, node2.Key); !etcdv3.IsNotFound(err) {
+		t.Errorf("Unexpected write of node2: %v", err)
+	}
+
+	ops := r.Operations()
+	if len(ops) != 3 || ops[0].Op != dryrun.OpPut || ops[1].Op != dryrun.OpPut || ops[2].Op != dryrun.OpDelete {
+		t.Fatalf("Unexpected operations: %+v", ops)
+	}
+	if !strings.Contains(ops[0].Diff, `-  "podCIDR": "10.0.0.0/24"`) ||
+		!strings.Contains(ops[0].Diff, `+  "podCIDR": "10.0.1.0/24"`) ||
+		!strings.Contains(ops[0].Diff, `   "name": "node1"`) {
+		t.Errorf("Unexpected diff:\n%s", ops[0].Diff)
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/dry-run", nil))
+	var served []dryrun.Operation
+	if err := json.Unmarshal(rec.Body.Bytes(), &served); err != nil || len(served) != 3 {
+		t.Errorf("Unexpected served operations: %s, %v", rec.Body.String(), err)
+	}
+}
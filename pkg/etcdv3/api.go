@@ -19,7 +19,51 @@ import (
 	"github.com/nimbess/stargazer/pkg/model"
 )
 
+// Client is the datastore interface used to store the Nimbess model.
+// Revisions are opaque strings, ordered as etcd revisions.
 type Client interface {
+	// Create stores a new key, fails if the key exists.
 	Create(ctx context.Context, object *model.KVPair) error
+	// Update replaces the value of an existing key. If the Revision of the KVPair is set,
+	// the update fails unless it matches the revision of the stored key.
+	Update(ctx context.Context, object *model.KVPair) error
+	// Get returns the value and revision of a key.
+	Get(ctx context.Context, k model.Key) (*model.KVPair, error)
+	// List returns all the keys matching the list options, sorted by path.
+	List(ctx context.Context, l model.ListInterface) (*model.KVPairList, error)
+	// Delete removes a key, fails if the key does not exist.
 	Delete(ctx context.Context, k model.Key) error
+	// Watch returns the changes to the keys matching the list options that happen after the
+	// given revision, or from now if revision is empty. The channel is closed when ctx is done.
+	Watch(ctx context.Context, l model.ListInterface, revision string) (<-chan WatchEvent, error)
+	// EnsureSchema checks the key layout version recorded in the datastore and records the current
+	// one. If migrate is true, keys written with an older layout are moved to their current path
+	// first. Otherwise an older recorded version is an error, and unversioned keys are left in
+	// place without recording the version. Fails if the recorded version is newer than supported.
+	EnsureSchema(ctx context.Context, migrate bool) error
+	// LeaseExpired returns a channel receiving a value each time the lease of the written keys was
+	// lost and a new one granted, the keys must then be written again. The channel is never closed,
+	// and is nil if the keys are not attached to a lease.
+	LeaseExpired() <-chan struct{}
+	// Close releases the connection to the datastore, operations started afterwards fail.
+	Close() error
+}
+
+// WatchEventType is the type of change reported by a WatchEvent.
+type WatchEventType string
+
+const (
+	WatchAdded    WatchEventType = "added"
+	WatchModified WatchEventType = "modified"
+	WatchDeleted  WatchEventType = "deleted"
+	WatchError    WatchEventType = "error"
+)
+
+// WatchEvent is a change of a watched key. Old is set for modified and deleted keys, New for
+// added and modified keys and Error for error events, after which the watch is closed.
+type WatchEvent struct {
+	Type  WatchEventType
+	Old   *model.KVPair
+	New   *model.KVPair
+	Error error
 }
@@ -0,0 +1,298 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdv3
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"github.com/coreos/etcd/clientv3"
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
+	"github.com/nimbess/stargazer/pkg/model"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+// DefaultBatchMaxOps is the default maximum number of operations in a transaction of etcd servers.
+const DefaultBatchMaxOps = 128
+
+// batchTimeout bounds the commit of a batch, which is not bound to the context of any of its writers.
+const batchTimeout = 10 * time.Second
+
+// Batch metrics, exported with expvar.
+var (
+	batchCount = expvar.NewInt("etcd_batches")
+	batchOps   = expvar.NewInt("etcd_batch_ops")
+	// batchSizes counts the batches by size bucket, each bucket holding the batches of up to its size.
+	batchSizes = expvar.NewMap("etcd_batch_sizes")
+)
+
+// batchOp is a write waiting to be committed with a batch.
+type batchOp struct {
+	key string
+	// op is a nested transaction, so that each write keeps its own conditions within the batch.
+	op   clientv3.Op
+	done chan batchResult
+}
+
+// batchResult is the response to an operation of a committed batch.
+type batchResult struct {
+	// resp is the response of the nested transaction of the operation.
+	resp *pb.ResponseOp
+	// rev is the revision of the batch.
+	rev int64
+	err error
+}
+
+// batcher collects the operations submitted within a window into batches. An operation on a key
+// already part of the batch closes it, as etcd rejects transactions writing a key twice and the
+// operations on a key must be applied in order.
+type batcher struct {
+	window time.Duration
+	maxOps int
+	ops    chan *batchOp
+	commit func(batch []*batchOp)
+	// stopCh fails the operations submitted once the client is closed
+	stopCh <-chan struct{}
+	// done is closed when run returned, after committing the last batch
+	done chan struct{}
+}
+
+// run collects and commits batches until stopCh is closed.
+func (b *batcher) run(stopCh <-chan struct{}) {
+	if b.done != nil {
+		defer close(b.done)
+	}
+	for {
+		var first *batchOp
+		select {
+		case first = <-b.ops:
+		case <-stopCh:
+			return
+		}
+
+		batch := []*batchOp{first}
+		keys := map[string]bool{first.key: true}
+		timer := time.NewTimer(b.window)
+	collect:
+		for {
+			select {
+			case op := <-b.ops:
+				if keys[op.key] {
+					b.commit(batch)
+					batch, keys = nil, map[string]bool{}
+				}
+				batch = append(batch, op)
+				keys[op.key] = true
+				if len(batch) >= b.maxOps {
+					break collect
+				}
+			case <-timer.C:
+				break collect
+			case <-stopCh:
+				timer.Stop()
+				b.commit(batch)
+				return
+			}
+		}
+		timer.Stop()
+		b.commit(batch)
+	}
+}
+
+// submit queues the operation and waits for the result of its batch.
+func (b *batcher) submit(ctx context.Context, key string, op clientv3.Op) (*pb.TxnResponse, int64, error) {
+	bop := &batchOp{key: key, op: op, done: make(chan batchResult, 1)}
+	select {
+	case b.ops <- bop:
+	case <-b.stopCh:
+		return nil, 0, NewUnreachableError(key, errClosed)
+	case <-ctx.Done():
+		return nil, 0, toStorageError(key, ctx.Err())
+	}
+	select {
+	case res := <-bop.done:
+		if res.err != nil {
+			return nil, 0, res.err
+		}
+		return res.resp.GetResponseTxn(), res.rev, nil
+	case <-ctx.Done():
+		// The write may still be committed with its batch.
+		return nil, 0, toStorageError(key, ctx.Err())
+	}
+}
+
+// BatchClient is an EtcdV3Client coalescing the writes submitted within a short window into
+// multi-op transactions, which speeds up resyncs writing many keys at once. Reads and watches
+// are not batched.
+type BatchClient struct {
+	*EtcdV3Client
+	batcher *batcher
+}
+
+// NewBatchClient returns a client committing the writes of c in batches of up to maxOps
+// operations, collected for at most window.
+func NewBatchClient(c *EtcdV3Client, window time.Duration, maxOps int) *BatchClient {
+	if maxOps <= 0 {
+		maxOps = DefaultBatchMaxOps
+	}
+	bc := &BatchClient{EtcdV3Client: c}
+	bc.batcher = &batcher{window: window, maxOps: maxOps, ops: make(chan *batchOp), commit: bc.commit,
+		stopCh: c.stopCh, done: make(chan struct{})}
+	go bc.batcher.run(c.stopCh)
+	log.WithFields(log.Fields{"window": window, "maxOps": maxOps}).Info("Batching etcd writes")
+	return bc
+}
+
+// Close commits the pending batch and closes the client.
+func (c *BatchClient) Close() error {
+	if !c.EtcdV3Client.stop() {
+		return nil
+	}
+	<-c.batcher.done
+	return c.client().Close()
+}
+
+func (c *BatchClient) Create(ctx context.Context, d *model.KVPair) error {
+	log.WithFields(log.Fields{"key": d.Key.String(), "value": d.Value}).Debug("Create request")
+
+	key, value, err := getKeyValueStrings(d)
+	if err != nil {
+		return err
+	}
+
+	var putOpts []clientv3.OpOption
+	lease := c.lease()
+	if lease != clientv3.NoLease {
+		putOpts = append(putOpts, clientv3.WithLease(lease))
+	}
+	txResp, rev, err := c.batcher.submit(ctx, key, clientv3.OpTxn(
+		[]clientv3.Cmp{notFound(key)},
+		[]clientv3.Op{clientv3.OpPut(key, value, putOpts...)},
+		[]clientv3.Op{clientv3.OpGet(key)},
+	))
+	if err != nil {
+		return err
+	}
+	if !txResp.Succeeded {
+		existing := txResp.Responses[0].GetResponseRange().Kvs
+		if lease == clientv3.NoLease || len(existing) == 0 || clientv3.LeaseID(existing[0].Lease) == lease {
+			return NewKeyExistsError(key, rev)
+		}
+		rev, err := c.adoptKey(ctx, key, value, existing[0].ModRevision, lease)
+		if err != nil {
+			return err
+		}
+		d.Revision = formatRevision(rev)
+		return nil
+	}
+	d.Revision = formatRevision(rev)
+	return nil
+}
+
+func (c *BatchClient) Update(ctx context.Context, d *model.KVPair) error {
+	log.WithFields(log.Fields{"key": d.Key.String(), "value": d.Value}).Debug("Update request")
+
+	key, value, err := getKeyValueStrings(d)
+	if err != nil {
+		return err
+	}
+
+	cmp := found(key)
+	if d.Revision != "" {
+		rev, err := parseRevision(d.Revision)
+		if err != nil {
+			return NewInvalidObjError(key, err)
+		}
+		cmp = clientv3.Compare(clientv3.ModRevision(key), "=", rev)
+	}
+
+	var putOpts []clientv3.OpOption
+	if lease := c.lease(); lease != clientv3.NoLease {
+		putOpts = append(putOpts, clientv3.WithLease(lease))
+	}
+	txResp, rev, err := c.batcher.submit(ctx, key, clientv3.OpTxn(
+		[]clientv3.Cmp{cmp},
+		[]clientv3.Op{clientv3.OpPut(key, value, putOpts...)},
+		[]clientv3.Op{clientv3.OpGet(key)},
+	))
+	if err != nil {
+		return err
+	}
+	if !txResp.Succeeded {
+		if len(txResp.Responses[0].GetResponseRange().Kvs) == 0 {
+			return NewKeyNotFoundError(key, rev)
+		}
+		return NewResourceVersionConflictsError(key, rev)
+	}
+	d.Revision = formatRevision(rev)
+	return nil
+}
+
+func (c *BatchClient) Delete(ctx context.Context, k model.Key) error {
+	log.WithFields(log.Fields{"key": k.String()}).Debug("Delete request")
+
+	key, err := model.KeyToDefaultDeletePath(k)
+	if err != nil {
+		return NewInvalidObjError(k.String(), err)
+	}
+
+	txResp, rev, err := c.batcher.submit(ctx, key, clientv3.OpTxn(
+		[]clientv3.Cmp{found(key)},
+		[]clientv3.Op{clientv3.OpDelete(key)},
+		nil,
+	))
+	if err != nil {
+		return err
+	}
+	if !txResp.Succeeded {
+		return NewKeyNotFoundError(key, rev)
+	}
+	return nil
+}
+
+// commit writes the batch in a single transaction and hands each operation its result.
+func (c *BatchClient) commit(batch []*batchOp) {
+	if len(batch) == 0 {
+		return
+	}
+	recordBatch(len(batch))
+
+	ops := make([]clientv3.Op, len(batch))
+	for i, op := range batch {
+		ops[i] = op.op
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), batchTimeout)
+	defer cancel()
+	txResp, err := c.client().KV.Txn(ctx).Then(ops...).Commit()
+	for i, op := range batch {
+		if err != nil {
+			op.done <- batchResult{err: toStorageError(op.key, err)}
+			continue
+		}
+		op.done <- batchResult{resp: txResp.Responses[i], rev: txResp.Header.Revision}
+	}
+}
+
+// recordBatch updates the batch metrics.
+func recordBatch(size int) {
+	batchCount.Add(1)
+	batchOps.Add(int64(size))
+	bucket := 1
+	for bucket < size {
+		bucket *= 2
+	}
+	batchSizes.Add(fmt.Sprintf("le_%d", bucket), 1)
+}
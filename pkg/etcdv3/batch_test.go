@@ -0,0 +1,94 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdv3
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/clientv3"
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
+)
+
+type batchtest struct {
+	testName string
+	maxOps   int
+	keys     []string
+	expected [][]string
+}
+
+var batchTests = []batchtest{
+	// pass: distinct keys in a single batch
+	{"batch 1", 10, []string{"a", "b", "c"}, [][]string{{"a", "b", "c"}}},
+	// pass: max ops closes the batch
+	{"batch 2", 2, []string{"a", "b", "c"}, [][]string{{"a", "b"}, {"c"}}},
+	// pass: a key already in the batch closes it, keeping the order of the key
+	{"batch 3", 10, []string{"a", "b", "a", "c"}, [][]string{{"a", "b"}, {"a", "c"}}},
+}
+
+func TestBatcher(t *testing.T) {
+	for _, test := range batchTests {
+		var lock sync.Mutex
+		var got [][]string
+		b := &batcher{window: time.Hour, maxOps: test.maxOps, ops: make(chan *batchOp)}
+		b.commit = func(batch []*batchOp) {
+			var keys []string
+			for _, op := range batch {
+				keys = append(keys, op.key)
+				op.done <- batchResult{resp: &pb.ResponseOp{Response: &pb.ResponseOp_ResponseTxn{
+					ResponseTxn: &pb.TxnResponse{Succeeded: true}}}}
+			}
+			lock.Lock()
+			got = append(got, keys)
+			lock.Unlock()
+		}
+		stopCh := make(chan struct{})
+		go b.run(stopCh)
+
+		// Submit in order, without waiting for the batches to be committed.
+		var wg sync.WaitGroup
+		for _, key := range test.keys {
+			bop := &batchOp{key: key, op: clientv3.OpGet(key), done: make(chan batchResult, 1)}
+			b.ops <- bop
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-bop.done
+			}()
+		}
+		// The last batch is committed when the batcher stops.
+		close(stopCh)
+		wg.Wait()
+
+		lock.Lock()
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s\nExpected: %v\nGot: %v", test.testName, test.expected, got)
+		}
+		lock.Unlock()
+	}
+}
+
+func TestBatcher_ContextDone(t *testing.T) {
+	b := &batcher{window: time.Hour, maxOps: 1, ops: make(chan *batchOp)}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := b.submit(ctx, "a", clientv3.OpGet("a")); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context canceled, got %v", err)
+	}
+}
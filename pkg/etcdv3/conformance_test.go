@@ -0,0 +1,50 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdv3_test
+
+import (
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/etcdv3/storetest"
+	"github.com/nimbess/stargazer/pkg/model"
+	"testing"
+	"time"
+)
+
+func TestMemoryClient_Conformance(t *testing.T) {
+	storetest.Conformance(t, etcdv3.NewMemoryClient(), model.LegacyRoot)
+}
+
+func TestEtcdV3Client_Conformance(t *testing.T) {
+	c, err := etcdv3.New(storetest.EtcdConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	storetest.Conformance(t, c, storetest.Root())
+}
+
+func TestBatchClient_Conformance(t *testing.T) {
+	cfg := storetest.EtcdConfig(t)
+	cfg.EtcdBatchWindow = 5 * time.Millisecond
+	c, err := etcdv3.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if _, ok := c.(*etcdv3.BatchClient); !ok {
+		t.Fatalf("Expected a batch client, got %T", c)
+	}
+	storetest.Conformance(t, c, storetest.Root())
+}
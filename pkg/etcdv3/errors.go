@@ -14,7 +14,14 @@
 
 package etcdv3
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/coreos/etcd/etcdserver/api/v3rpc/rpctypes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
 
 func NewKeyExistsError(key string, rv int64) *StorageError {
 	return &StorageError{
@@ -24,11 +31,48 @@ func NewKeyExistsError(key string, rv int64) *StorageError {
 	}
 }
 
+func NewKeyNotFoundError(key string, rv int64) *StorageError {
+	return &StorageError{
+		Code:            ErrCodeKeyNotFound,
+		Key:             key,
+		ResourceVersion: rv,
+	}
+}
+
+func NewResourceVersionConflictsError(key string, rv int64) *StorageError {
+	return &StorageError{
+		Code:            ErrCodeResourceVersionConflicts,
+		Key:             key,
+		ResourceVersion: rv,
+	}
+}
+
+func NewInvalidObjError(key string, err error) *StorageError {
+	return &StorageError{
+		Code:               ErrCodeInvalidObj,
+		Key:                key,
+		AdditionalErrorMsg: err.Error(),
+		Err:                err,
+	}
+}
+
+func NewUnreachableError(key string, err error) *StorageError {
+	return &StorageError{
+		Code:               ErrCodeUnreachable,
+		Key:                key,
+		AdditionalErrorMsg: err.Error(),
+		Err:                err,
+	}
+}
+
+// StorageError is returned by the datastore for failed operations, Code tells the kind of failure
+// and Err holds the underlying error, if any.
 type StorageError struct {
 	Code               int
 	Key                string
 	ResourceVersion    int64
 	AdditionalErrorMsg string
+	Err                error
 }
 
 const (
@@ -52,6 +96,81 @@ func (e *StorageError) Error() string {
 		errCodeToMessage[e.Code], e.Code, e.Key, e.ResourceVersion, e.AdditionalErrorMsg)
 }
 
+// Unwrap returns the underlying error.
+func (e *StorageError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches any StorageError with the same code, e.g.
+// errors.Is(err, &StorageError{Code: ErrCodeKeyNotFound}).
+func (e *StorageError) Is(target error) bool {
+	t, ok := target.(*StorageError)
+	return ok && t.Code == e.Code
+}
+
+// IsNotFound returns true if the error indicates the key does not exist.
+func IsNotFound(err error) bool {
+	return hasCode(err, ErrCodeKeyNotFound)
+}
+
+// IsExists returns true if the error indicates the key already exists.
+func IsExists(err error) bool {
+	return hasCode(err, ErrCodeKeyExists)
+}
+
+// IsConflict returns true if the error indicates the key was modified concurrently.
+func IsConflict(err error) bool {
+	return hasCode(err, ErrCodeResourceVersionConflicts)
+}
+
+// IsInvalid returns true if the error indicates the key or value can't be stored.
+func IsInvalid(err error) bool {
+	return hasCode(err, ErrCodeInvalidObj)
+}
+
+// IsUnavailable returns true if the error indicates the datastore could not be reached.
+func IsUnavailable(err error) bool {
+	return hasCode(err, ErrCodeUnreachable)
+}
+
+// IsRetriable returns true if the operation may succeed when retried.
+func IsRetriable(err error) bool {
+	return IsUnavailable(err) || IsConflict(err)
+}
+
+func hasCode(err error, code int) bool {
+	var e *StorageError
+	return errors.As(err, &e) && e.Code == code
+}
+
+// toStorageError maps the errors returned by the etcd client to a StorageError.
+// Errors that don't map to a known code are returned unchanged.
+func toStorageError(key string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return NewUnreachableError(key, err)
+	}
+
+	var code codes.Code
+	if e, ok := err.(rpctypes.EtcdError); ok {
+		code = e.Code()
+	} else if s, ok := status.FromError(err); ok {
+		code = s.Code()
+	} else {
+		return err
+	}
+	switch code {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return NewUnreachableError(key, err)
+	case codes.OutOfRange:
+		// the requested revision was compacted
+		return &StorageError{Code: ErrCodeResourceVersionConflicts, Key: key, AdditionalErrorMsg: err.Error(), Err: err}
+	}
+	return err
+}
+
 // Error indicating a problem connecting to the backend.
 type ErrorDatastoreError struct {
 	Err        error
@@ -0,0 +1,55 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdv3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/coreos/etcd/etcdserver/api/v3rpc/rpctypes"
+	"testing"
+)
+
+type errortest struct {
+	testName    string
+	err         error
+	notFound    bool
+	conflict    bool
+	unavailable bool
+}
+
+var errorTests = []errortest{
+	{"not found", NewKeyNotFoundError("/nimbess/unp/a", 1), true, false, false},
+	{"wrapped not found", fmt.Errorf("delete: %w", NewKeyNotFoundError("/nimbess/unp/a", 1)), true, false, false},
+	{"exists", NewKeyExistsError("/nimbess/unp/a", 1), false, false, false},
+	{"conflict", NewResourceVersionConflictsError("/nimbess/unp/a", 1), false, true, false},
+	{"compacted", toStorageError("/nimbess/unp/a", rpctypes.ErrCompacted), false, true, false},
+	{"no leader", toStorageError("/nimbess/unp/a", rpctypes.ErrNoLeader), false, false, true},
+	{"grpc timeout", toStorageError("/nimbess/unp/a", rpctypes.ErrGRPCTimeout), false, false, true},
+	{"deadline", toStorageError("/nimbess/unp/a", context.DeadlineExceeded), false, false, true},
+	{"other", toStorageError("/nimbess/unp/a", errors.New("other")), false, false, false},
+}
+
+func TestErrorHelpers(t *testing.T) {
+	for _, test := range errorTests {
+		if IsNotFound(test.err) != test.notFound || IsConflict(test.err) != test.conflict ||
+			IsUnavailable(test.err) != test.unavailable {
+			t.Errorf("%s: unexpected classification of %v", test.testName, test.err)
+		}
+	}
+	if !errors.Is(NewKeyNotFoundError("a", 1), &StorageError{Code: ErrCodeKeyNotFound}) {
+		t.Error("errors.Is should match on the error code")
+	}
+}
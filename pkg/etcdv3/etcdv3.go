@@ -23,76 +23,337 @@ import (
 	"github.com/nimbess/stargazer/pkg/config"
 	"github.com/nimbess/stargazer/pkg/model"
 	log "github.com/sirupsen/logrus"
-	"strings"
+	"strconv"
+	"sync"
+	"time"
 )
 
+// errClosed is returned for the operations submitted after the client was closed
+var errClosed = errors.New("etcd client closed")
+
+// clientCloseDelay is how long a replaced etcd client is kept open for requests in flight
+const clientCloseDelay = 10 * time.Second
+
 type EtcdV3Client struct {
-	etcdClient *clientv3.Client
+	lock         sync.RWMutex
+	etcdClient   *clientv3.Client
+	stopCh       chan struct{}
+	leaseTTL     time.Duration
+	leaseID      clientv3.LeaseID
+	leaseExpired chan struct{}
 }
 
 func New(config *config.Config) (Client, error) {
 	log.WithField("endpoints", config.EtcdEndpoints).Info("Connecting to etcd...")
-	etcdEndpoints := strings.Split(config.EtcdEndpoints, ",")
-	if len(etcdEndpoints) == 0 {
-		return nil, errors.New("no etcd endpoints specified")
+	etcdConfig, err := newEtcdConfig(config)
+	if err != nil {
+		return nil, err
 	}
 
-	etcdConfig := clientv3.Config{Endpoints: etcdEndpoints, DialTimeout: config.EtcdDialTimeout}
 	etcdClient, err := clientv3.New(etcdConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to etcd: %s", err)
 	}
 
-	return &EtcdV3Client{etcdClient}, nil
+	c := &EtcdV3Client{etcdClient: etcdClient, stopCh: make(chan struct{}), leaseID: clientv3.NoLease}
+	if etcdConfig.TLS != nil {
+		go c.watchCerts(config)
+	}
+	if config.EtcdLeaseTTL > 0 {
+		c.leaseTTL = config.EtcdLeaseTTL
+		c.leaseExpired = make(chan struct{}, 1)
+		ctx, cancel := context.WithTimeout(context.Background(), config.EtcdDialTimeout)
+		defer cancel()
+		if err := c.grantLease(ctx); err != nil {
+			return nil, err
+		}
+		go c.keepLeaseAlive()
+	}
+	if config.EtcdBatchWindow > 0 {
+		return NewBatchClient(c, config.EtcdBatchWindow, config.EtcdBatchMaxOps), nil
+	}
+	return c, nil
+}
+
+// Close stops the lease keep alive and the TLS files watch and closes the connection to etcd.
+// Keys attached to the lease expire with its TTL.
+func (c *EtcdV3Client) Close() error {
+	if !c.stop() {
+		return nil
+	}
+	return c.client().Close()
+}
+
+// stop stops the background tasks of the client, returns false if they were already stopped.
+func (c *EtcdV3Client) stop() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	select {
+	case <-c.stopCh:
+		return false
+	default:
+	}
+	close(c.stopCh)
+	return true
+}
+
+// client returns the current etcd client, which is replaced when the TLS files rotate.
+func (c *EtcdV3Client) client() *clientv3.Client {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.etcdClient
+}
+
+// swapClient replaces the etcd client and closes the previous one once requests in flight
+// had a chance to complete.
+func (c *EtcdV3Client) swapClient(etcdClient *clientv3.Client) {
+	c.lock.Lock()
+	old := c.etcdClient
+	c.etcdClient = etcdClient
+	c.lock.Unlock()
+	time.AfterFunc(clientCloseDelay, func() {
+		if err := old.Close(); err != nil {
+			log.WithError(err).Debug("Failed to close previous etcd client")
+		}
+	})
 }
 
 func (c *EtcdV3Client) Create(ctx context.Context, d *model.KVPair) error {
 	log.WithFields(log.Fields{"key": d.Key.String(), "value": d.Value}).Debug("Create request")
 
 	key, value, err := getKeyValueStrings(d)
+	if err != nil {
+		return err
+	}
 
-	// TODO: add ttl options
 	var putOpts []clientv3.OpOption
-	txResp, err := c.etcdClient.KV.Txn(ctx).If(
+	lease := c.lease()
+	if lease != clientv3.NoLease {
+		putOpts = append(putOpts, clientv3.WithLease(lease))
+	}
+	txResp, err := c.client().KV.Txn(ctx).If(
 		notFound(key),
 	).Then(
 		clientv3.OpPut(key, value, putOpts...),
+	).Else(
+		clientv3.OpGet(key),
 	).Commit()
 
 	if err != nil {
-		return err
+		return toStorageError(key, err)
 	}
 	if !txResp.Succeeded {
-		return NewKeyExistsError(key, 0)
+		existing := txResp.Responses[0].GetResponseRange().Kvs
+		if lease == clientv3.NoLease || len(existing) == 0 || clientv3.LeaseID(existing[0].Lease) == lease {
+			return NewKeyExistsError(key, txResp.Header.Revision)
+		}
+		// The key was written with a previous lease, e.g. before a restart. Attach it to
+		// the current lease so that it doesn't expire with the previous one.
+		rev, err := c.adoptKey(ctx, key, value, existing[0].ModRevision, lease)
+		if err != nil {
+			return err
+		}
+		d.Revision = formatRevision(rev)
+		return nil
 	}
+	d.Revision = formatRevision(txResp.Header.Revision)
 	return nil
 }
 
+// adoptKey rewrites a key attached to a previous lease with the current lease. Returns the
+// revision of the write.
+func (c *EtcdV3Client) adoptKey(ctx context.Context, key string, value string, rev int64,
+	lease clientv3.LeaseID) (int64, error) {
+	txResp, err := c.client().KV.Txn(ctx).If(
+		clientv3.Compare(clientv3.ModRevision(key), "=", rev),
+	).Then(
+		clientv3.OpPut(key, value, clientv3.WithLease(lease)),
+	).Commit()
+	if err != nil {
+		return 0, toStorageError(key, err)
+	}
+	if !txResp.Succeeded {
+		return 0, NewResourceVersionConflictsError(key, rev)
+	}
+	log.WithFields(log.Fields{"key": key, "lease": lease}).Debug("Key attached to current lease")
+	return txResp.Header.Revision, nil
+}
+
 func (c *EtcdV3Client) Delete(ctx context.Context, k model.Key) error {
 	log.WithFields(log.Fields{"key": k.String()}).Debug("Delete request")
 
 	key, err := model.KeyToDefaultDeletePath(k)
 	if err != nil {
-		return err
+		return NewInvalidObjError(k.String(), err)
 	}
 
-	txResp, err := c.etcdClient.KV.Txn(ctx).If(
+	txResp, err := c.client().KV.Txn(ctx).If(
 		found(key),
 	).Then(
 		clientv3.OpDelete(key),
 	).Else(
 		clientv3.OpGet(key),
 	).Commit()
+	if err != nil {
+		return toStorageError(key, err)
+	}
+	if !txResp.Succeeded {
+		return NewKeyNotFoundError(key, txResp.Header.Revision)
+	}
+	return nil
+}
+
+func (c *EtcdV3Client) Update(ctx context.Context, d *model.KVPair) error {
+	log.WithFields(log.Fields{"key": d.Key.String(), "value": d.Value}).Debug("Update request")
+
+	key, value, err := getKeyValueStrings(d)
 	if err != nil {
 		return err
 	}
+
+	cmp := found(key)
+	if d.Revision != "" {
+		rev, err := parseRevision(d.Revision)
+		if err != nil {
+			return NewInvalidObjError(key, err)
+		}
+		cmp = clientv3.Compare(clientv3.ModRevision(key), "=", rev)
+	}
+
+	var putOpts []clientv3.OpOption
+	if lease := c.lease(); lease != clientv3.NoLease {
+		putOpts = append(putOpts, clientv3.WithLease(lease))
+	}
+	txResp, err := c.client().KV.Txn(ctx).If(
+		cmp,
+	).Then(
+		clientv3.OpPut(key, value, putOpts...),
+	).Else(
+		clientv3.OpGet(key),
+	).Commit()
+	if err != nil {
+		return toStorageError(key, err)
+	}
 	if !txResp.Succeeded {
-		// TODO return proper error
-		return NewKeyExistsError(key, 0)
+		if len(txResp.Responses[0].GetResponseRange().Kvs) == 0 {
+			return NewKeyNotFoundError(key, txResp.Header.Revision)
+		}
+		return NewResourceVersionConflictsError(key, txResp.Header.Revision)
 	}
+	d.Revision = formatRevision(txResp.Header.Revision)
 	return nil
 }
 
+func (c *EtcdV3Client) Get(ctx context.Context, k model.Key) (*model.KVPair, error) {
+	log.WithFields(log.Fields{"key": k.String()}).Debug("Get request")
+
+	key, err := model.KeyToDefaultPath(k)
+	if err != nil {
+		return nil, NewInvalidObjError(k.String(), err)
+	}
+	resp, err := c.client().Get(ctx, key)
+	if err != nil {
+		return nil, toStorageError(key, err)
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, NewKeyNotFoundError(key, resp.Header.Revision)
+	}
+	return parseKV(k, resp.Kvs[0].Value, resp.Kvs[0].ModRevision)
+}
+
+func (c *EtcdV3Client) List(ctx context.Context, l model.ListInterface) (*model.KVPairList, error) {
+	prefix := model.ListOptionsToDefaultPathRoot(l)
+	log.WithFields(log.Fields{"prefix": prefix}).Debug("List request")
+
+	resp, err := c.client().Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
+	if err != nil {
+		return nil, toStorageError(prefix, err)
+	}
+	list := &model.KVPairList{Revision: formatRevision(resp.Header.Revision)}
+	for _, kv := range resp.Kvs {
+		k := l.KeyFromDefaultPath(string(kv.Key))
+		if k == nil {
+			continue
+		}
+		d, err := parseKV(k, kv.Value, kv.ModRevision)
+		if err != nil {
+			log.WithError(err).WithField("key", string(kv.Key)).Warn("Failed to parse value, skipping")
+			continue
+		}
+		list.KVPairs = append(list.KVPairs, d)
+	}
+	return list, nil
+}
+
+func (c *EtcdV3Client) Watch(ctx context.Context, l model.ListInterface, revision string) (<-chan WatchEvent, error) {
+	prefix := model.ListOptionsToDefaultPathRoot(l)
+	log.WithFields(log.Fields{"prefix": prefix, "revision": revision}).Debug("Watch request")
+
+	opts := []clientv3.OpOption{clientv3.WithPrefix(), clientv3.WithPrevKV()}
+	if revision != "" {
+		rev, err := parseRevision(revision)
+		if err != nil {
+			return nil, NewInvalidObjError(prefix, err)
+		}
+		opts = append(opts, clientv3.WithRev(rev+1))
+	}
+	wch := c.client().Watch(clientv3.WithRequireLeader(ctx), prefix, opts...)
+
+	events := make(chan WatchEvent)
+	go func() {
+		defer close(events)
+		send := func(e WatchEvent) bool {
+			select {
+			case events <- e:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for resp := range wch {
+			if err := resp.Err(); err != nil {
+				send(WatchEvent{Type: WatchError, Error: toStorageError(prefix, err)})
+				return
+			}
+			for _, ev := range resp.Events {
+				e, ok := toWatchEvent(l, ev)
+				if ok && !send(e) {
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// toWatchEvent converts an etcd event. Returns false for keys that are not part of the list.
+func toWatchEvent(l model.ListInterface, ev *clientv3.Event) (WatchEvent, bool) {
+	k := l.KeyFromDefaultPath(string(ev.Kv.Key))
+	if k == nil {
+		return WatchEvent{}, false
+	}
+	var e WatchEvent
+	var err error
+	if ev.PrevKv != nil {
+		if e.Old, err = parseKV(k, ev.PrevKv.Value, ev.PrevKv.ModRevision); err != nil {
+			return WatchEvent{Type: WatchError, Error: err}, true
+		}
+	}
+	switch ev.Type {
+	case clientv3.EventTypeDelete:
+		e.Type = WatchDeleted
+	default:
+		e.Type = WatchModified
+		if ev.IsCreate() {
+			e.Type = WatchAdded
+		}
+		if e.New, err = parseKV(k, ev.Kv.Value, ev.Kv.ModRevision); err != nil {
+			return WatchEvent{Type: WatchError, Error: err}, true
+		}
+	}
+	return e, true
+}
+
 func notFound(key string) clientv3.Cmp {
 	return clientv3.Compare(clientv3.ModRevision(key), "=", 0)
 }
@@ -108,19 +369,30 @@ func getKeyValueStrings(d *model.KVPair) (string, string, error) {
 	key, err := model.KeyToDefaultPath(d.Key)
 	if err != nil {
 		logCxt.WithError(err).Error("Failed to convert model-etcdKey to etcdv3 etcdKey")
-		return "", "", ErrorDatastoreError{
-			Err:        err,
-			Identifier: d.Key,
-		}
+		return "", "", NewInvalidObjError(d.Key.String(), err)
 	}
 	bytes, err := model.SerializeValue(d)
 	if err != nil {
 		logCxt.WithError(err).Error("Failed to serialize value")
-		return "", "", ErrorDatastoreError{
-			Err:        err,
-			Identifier: d.Key,
-		}
+		return "", "", NewInvalidObjError(key, err)
 	}
 
 	return key, string(bytes), nil
 }
+
+// parseKV returns the KVPair of a key from its serialized value and revision.
+func parseKV(k model.Key, value []byte, rev int64) (*model.KVPair, error) {
+	v, err := model.ParseValue(k, value)
+	if err != nil {
+		return nil, NewInvalidObjError(k.String(), err)
+	}
+	return &model.KVPair{Key: k, Value: v, Revision: formatRevision(rev)}, nil
+}
+
+func formatRevision(rev int64) string {
+	return strconv.FormatInt(rev, 10)
+}
+
+func parseRevision(revision string) (int64, error) {
+	return strconv.ParseInt(revision, 10, 64)
+}
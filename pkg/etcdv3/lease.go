@@ -0,0 +1,99 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdv3
+
+import (
+	"context"
+	"fmt"
+	"github.com/coreos/etcd/clientv3"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+// leaseRetryInterval is how long to wait before retrying to grant a lease
+const leaseRetryInterval = time.Second
+
+// lease returns the lease written keys are attached to, or clientv3.NoLease if leases are disabled.
+func (c *EtcdV3Client) lease() clientv3.LeaseID {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.leaseID
+}
+
+// LeaseExpired returns a channel notified when the lease of the written keys was lost, so the keys
+// need to be written again. Returns nil if leases are disabled.
+func (c *EtcdV3Client) LeaseExpired() <-chan struct{} {
+	return c.leaseExpired
+}
+
+// grantLease grants a new lease and uses it for all following writes.
+func (c *EtcdV3Client) grantLease(ctx context.Context) error {
+	resp, err := c.client().Grant(ctx, int64(c.leaseTTL.Seconds()))
+	if err != nil {
+		return fmt.Errorf("failed to grant etcd lease: %w", toStorageError("", err))
+	}
+	c.lock.Lock()
+	c.leaseID = resp.ID
+	c.lock.Unlock()
+	log.WithFields(log.Fields{"lease": resp.ID, "ttl": resp.TTL}).Info("Granted etcd lease")
+	return nil
+}
+
+// keepLeaseAlive keeps the current lease alive until the client is closed. When the lease is lost,
+// e.g. because etcd could not be reached for longer than the TTL, a new lease is granted and
+// LeaseExpired is notified.
+func (c *EtcdV3Client) keepLeaseAlive() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-c.stopCh
+		cancel()
+	}()
+
+	for {
+		id := c.lease()
+		ch, err := c.client().KeepAlive(ctx, id)
+		if err == nil {
+			for range ch {
+				// the lease is refreshed, wait for the next response
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		// The keep alive also stops when the client is replaced, in which case the lease is still valid.
+		if resp, err := c.client().TimeToLive(ctx, id); err == nil && resp.TTL > 0 {
+			continue
+		}
+
+		log.WithField("lease", id).Warn("etcd lease lost, written keys will be restored")
+		for {
+			if err := c.grantLease(ctx); err == nil {
+				break
+			} else if ctx.Err() != nil {
+				return
+			} else {
+				log.WithError(err).Warn("Failed to renew etcd lease")
+			}
+			time.Sleep(leaseRetryInterval)
+		}
+		select {
+		case c.leaseExpired <- struct{}{}:
+		default:
+			// a restore is already pending
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdv3_test
+
+import (
+	"context"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/etcdv3/storetest"
+	"github.com/nimbess/stargazer/pkg/model"
+	"testing"
+	"time"
+)
+
+func TestEtcdV3Client_Create_AdoptsKey(t *testing.T) {
+	for _, window := range []time.Duration{0, 5 * time.Millisecond} {
+		cfg := storetest.EtcdConfig(t)
+		cfg.EtcdLeaseTTL = 5 * time.Second
+		cfg.EtcdBatchWindow = window
+		if err := model.SetPrefix(storetest.Root(), ""); err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+
+		// a key written with the lease of a previous client, e.g. before a restart
+		previous, err := etcdv3.New(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		node := &model.KVPair{Key: model.NodeKey{Hostname: "node1"}, Value: &model.Node{Name: "node1"}}
+		if err := previous.Create(ctx, node); err != nil {
+			t.Fatal(err)
+		}
+		previous.Close()
+
+		c, err := etcdv3.New(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		adopted := &model.KVPair{Key: node.Key, Value: node.Value}
+		if err := c.Create(ctx, adopted); err != nil {
+			t.Errorf("batch window %s: expected the key to be adopted, got %v", window, err)
+		}
+		got, err := c.Get(ctx, node.Key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if adopted.Revision == "" || adopted.Revision != got.Revision {
+			t.Errorf("batch window %s: expected revision %s, got %q", window, got.Revision, adopted.Revision)
+		}
+		c.Delete(ctx, node.Key)
+		c.Close()
+		cancel()
+	}
+	model.SetPrefix(model.LegacyRoot, "")
+}
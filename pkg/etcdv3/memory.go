@@ -0,0 +1,316 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdv3
+
+import (
+	"context"
+	"fmt"
+	"github.com/nimbess/stargazer/pkg/model"
+	log "github.com/sirupsen/logrus"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// memoryHistory is the number of events kept by MemoryClient to serve watches from a past revision.
+// Older revisions are compacted.
+const memoryHistory = 1000
+
+// memoryValue is a stored key with its revisions.
+type memoryValue struct {
+	value     string
+	createRev int64
+	modRev    int64
+}
+
+// memoryEvent is a change of a key, as recorded in the history.
+type memoryEvent struct {
+	eventType WatchEventType
+	path      string
+	rev       int64
+	old       *memoryValue
+	new       *memoryValue
+}
+
+// MemoryClient is an in-memory implementation of Client, following the etcd semantics for
+// revisions, prefix listing and watches. It is meant for tests and dry runs.
+type MemoryClient struct {
+	lock     sync.Mutex
+	revision int64
+	kvs      map[string]*memoryValue
+	history  []memoryEvent
+	watchers map[*memoryWatcher]struct{}
+}
+
+// NewMemoryClient returns an empty in-memory datastore.
+func NewMemoryClient() *MemoryClient {
+	return &MemoryClient{
+		revision: 1,
+		kvs:      make(map[string]*memoryValue),
+		watchers: make(map[*memoryWatcher]struct{}),
+	}
+}
+
+func (c *MemoryClient) Create(ctx context.Context, d *model.KVPair) error {
+	key, value, err := getKeyValueStrings(d)
+	if err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.kvs[key]; ok {
+		return NewKeyExistsError(key, c.revision)
+	}
+	c.revision++
+	v := &memoryValue{value: value, createRev: c.revision, modRev: c.revision}
+	c.kvs[key] = v
+	c.record(memoryEvent{eventType: WatchAdded, path: key, rev: c.revision, new: v})
+	d.Revision = formatRevision(c.revision)
+	return nil
+}
+
+func (c *MemoryClient) Update(ctx context.Context, d *model.KVPair) error {
+	key, value, err := getKeyValueStrings(d)
+	if err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	old, ok := c.kvs[key]
+	if !ok {
+		return NewKeyNotFoundError(key, c.revision)
+	}
+	if d.Revision != "" {
+		rev, err := parseRevision(d.Revision)
+		if err != nil {
+			return NewInvalidObjError(key, err)
+		}
+		if rev != old.modRev {
+			return NewResourceVersionConflictsError(key, c.revision)
+		}
+	}
+	c.revision++
+	v := &memoryValue{value: value, createRev: old.createRev, modRev: c.revision}
+	c.kvs[key] = v
+	c.record(memoryEvent{eventType: WatchModified, path: key, rev: c.revision, old: old, new: v})
+	d.Revision = formatRevision(c.revision)
+	return nil
+}
+
+func (c *MemoryClient) Get(ctx context.Context, k model.Key) (*model.KVPair, error) {
+	key, err := model.KeyToDefaultPath(k)
+	if err != nil {
+		return nil, NewInvalidObjError(k.String(), err)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	v, ok := c.kvs[key]
+	if !ok {
+		return nil, NewKeyNotFoundError(key, c.revision)
+	}
+	return parseKV(k, []byte(v.value), v.modRev)
+}
+
+func (c *MemoryClient) List(ctx context.Context, l model.ListInterface) (*model.KVPairList, error) {
+	prefix := model.ListOptionsToDefaultPathRoot(l)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var paths []string
+	for path := range c.kvs {
+		if strings.HasPrefix(path, prefix) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	list := &model.KVPairList{Revision: formatRevision(c.revision)}
+	for _, path := range paths {
+		k := l.KeyFromDefaultPath(path)
+		if k == nil {
+			continue
+		}
+		d, err := parseKV(k, []byte(c.kvs[path].value), c.kvs[path].modRev)
+		if err != nil {
+			log.WithError(err).WithField("key", path).Warn("Failed to parse value, skipping")
+			continue
+		}
+		list.KVPairs = append(list.KVPairs, d)
+	}
+	return list, nil
+}
+
+func (c *MemoryClient) Delete(ctx context.Context, k model.Key) error {
+	key, err := model.KeyToDefaultDeletePath(k)
+	if err != nil {
+		return NewInvalidObjError(k.String(), err)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	old, ok := c.kvs[key]
+	if !ok {
+		return NewKeyNotFoundError(key, c.revision)
+	}
+	c.revision++
+	delete(c.kvs, key)
+	c.record(memoryEvent{eventType: WatchDeleted, path: key, rev: c.revision, old: old})
+	return nil
+}
+
+func (c *MemoryClient) Watch(ctx context.Context, l model.ListInterface, revision string) (<-chan WatchEvent, error) {
+	prefix := model.ListOptionsToDefaultPathRoot(l)
+	w := &memoryWatcher{list: l, prefix: prefix, events: make(chan WatchEvent)}
+	w.cond = sync.NewCond(&w.lock)
+
+	c.lock.Lock()
+	if revision != "" {
+		rev, err := parseRevision(revision)
+		if err != nil {
+			c.lock.Unlock()
+			return nil, NewInvalidObjError(prefix, err)
+		}
+		if len(c.history) > 0 && rev+1 < c.history[0].rev {
+			w.queue = append(w.queue, WatchEvent{Type: WatchError, Error: NewResourceVersionConflictsError(prefix, rev)})
+		} else {
+			for _, e := range c.history {
+				if e.rev > rev {
+					w.add(e)
+				}
+			}
+		}
+	}
+	c.watchers[w] = struct{}{}
+	c.lock.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		c.lock.Lock()
+		delete(c.watchers, w)
+		c.lock.Unlock()
+		w.lock.Lock()
+		w.done = true
+		w.cond.Broadcast()
+		w.lock.Unlock()
+	}()
+	go w.run(ctx)
+	return w.events, nil
+}
+
+func (c *MemoryClient) EnsureSchema(ctx context.Context, migrate bool) error {
+	current, err := c.Get(ctx, model.SchemaVersionKey{})
+	if IsNotFound(err) {
+		return c.Create(ctx, &model.KVPair{Key: model.SchemaVersionKey{}, Value: strconv.Itoa(model.SchemaVersion)})
+	}
+	if err != nil {
+		return err
+	}
+	if current.Value.(string) != strconv.Itoa(model.SchemaVersion) {
+		return fmt.Errorf("key layout version %s under %s is not supported by the in-memory datastore",
+			current.Value, model.Prefix())
+	}
+	return nil
+}
+
+// LeaseExpired returns nil, keys written to memory never expire.
+func (c *MemoryClient) LeaseExpired() <-chan struct{} {
+	return nil
+}
+
+// Close does nothing, the keys are kept in memory.
+func (c *MemoryClient) Close() error {
+	return nil
+}
+
+// record adds an event to the history and sends it to the watchers. Must be called with the lock held.
+func (c *MemoryClient) record(e memoryEvent) {
+	c.history = append(c.history, e)
+	if len(c.history) > memoryHistory {
+		c.history = c.history[len(c.history)-memoryHistory:]
+	}
+	for w := range c.watchers {
+		w.add(e)
+	}
+}
+
+// memoryWatcher queues the events of a watch so that writers never block on slow readers.
+type memoryWatcher struct {
+	list   model.ListInterface
+	prefix string
+	events chan WatchEvent
+
+	lock  sync.Mutex
+	cond  *sync.Cond
+	queue []WatchEvent
+	done  bool
+}
+
+// add queues the event if it matches the watched keys.
+func (w *memoryWatcher) add(e memoryEvent) {
+	if !strings.HasPrefix(e.path, w.prefix) {
+		return
+	}
+	k := w.list.KeyFromDefaultPath(e.path)
+	if k == nil {
+		return
+	}
+	ev := WatchEvent{Type: e.eventType}
+	var err error
+	if e.old != nil {
+		ev.Old, err = parseKV(k, []byte(e.old.value), e.old.modRev)
+	}
+	if e.new != nil && err == nil {
+		ev.New, err = parseKV(k, []byte(e.new.value), e.new.modRev)
+	}
+	if err != nil {
+		ev = WatchEvent{Type: WatchError, Error: err}
+	}
+
+	w.lock.Lock()
+	w.queue = append(w.queue, ev)
+	w.cond.Signal()
+	w.lock.Unlock()
+}
+
+// run sends the queued events until the watch is cancelled or an error is sent.
+func (w *memoryWatcher) run(ctx context.Context) {
+	defer close(w.events)
+	for {
+		w.lock.Lock()
+		for len(w.queue) == 0 && !w.done {
+			w.cond.Wait()
+		}
+		if w.done {
+			w.lock.Unlock()
+			return
+		}
+		e := w.queue[0]
+		w.queue = w.queue[1:]
+		w.lock.Unlock()
+
+		select {
+		case w.events <- e:
+		case <-ctx.Done():
+			return
+		}
+		if e.Type == WatchError {
+			return
+		}
+	}
+}
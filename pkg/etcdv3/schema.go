@@ -0,0 +1,155 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdv3
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/coreos/etcd/clientv3"
+	"github.com/nimbess/stargazer/pkg/model"
+	log "github.com/sirupsen/logrus"
+	"strconv"
+)
+
+// EnsureSchema checks the key layout version recorded under the Nimbess prefix, migrates keys
+// written with an older layout and records the current layout version.
+// Keys of an older layout are only migrated if migrate is true, except for unversioned keys
+// that are already under the configured prefix and thus don't need to be moved. The version
+// isn't recorded while unversioned keys are left in place.
+func (c *EtcdV3Client) EnsureSchema(ctx context.Context, migrate bool) error {
+	marker := &model.KVPair{Key: model.SchemaVersionKey{}, Value: strconv.Itoa(model.SchemaVersion)}
+	key, value, err := getKeyValueStrings(marker)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client().Get(ctx, key)
+	if err != nil {
+		return toStorageError(key, err)
+	}
+	version := model.UnversionedSchema
+	if len(resp.Kvs) > 0 {
+		version, err = strconv.Atoi(string(resp.Kvs[0].Value))
+		if err != nil {
+			return fmt.Errorf("invalid key layout version %q in %s: %v", resp.Kvs[0].Value, key, err)
+		}
+	}
+	logCxt := log.WithFields(log.Fields{"prefix": model.Prefix(), "version": version})
+
+	switch {
+	case version == model.SchemaVersion:
+		logCxt.Info("Key layout is up to date")
+		return nil
+	case version > model.SchemaVersion:
+		return fmt.Errorf("key layout version %d under %s is newer than supported version %d",
+			version, model.Prefix(), model.SchemaVersion)
+	case len(resp.Kvs) == 0 && model.Prefix() == model.LegacyRoot:
+		// Unversioned keys are already in place, migrating only converts the values of later
+		// layouts and records the version.
+	case len(resp.Kvs) == 0 && !migrate:
+		pending, err := c.hasUnmigratedKeys(ctx, version)
+		if err != nil {
+			return err
+		}
+		if pending {
+			// Leave the version unrecorded so that the keys are migrated once migration is enabled.
+			logCxt.Warnf("Not migrating unversioned keys from %s, migration is disabled", model.LegacyRoot)
+			return nil
+		}
+		version = model.SchemaVersion
+	case !migrate:
+		return fmt.Errorf("key layout version %d under %s needs migration to version %d, migration is disabled",
+			version, model.Prefix(), model.SchemaVersion)
+	}
+
+	for ; version < model.SchemaVersion; version++ {
+		m, err := model.MigrationFrom(version)
+		if err != nil {
+			return err
+		}
+		if err := c.migrate(ctx, m); err != nil {
+			return fmt.Errorf("failed to migrate key layout from version %d: %v", version, err)
+		}
+	}
+
+	if _, err := c.client().Put(ctx, key, value); err != nil {
+		return toStorageError(key, err)
+	}
+	logCxt.WithField("current", model.SchemaVersion).Info("Key layout version recorded")
+	return nil
+}
+
+// hasUnmigratedKeys reports whether keys of the given layout version are found in place.
+func (c *EtcdV3Client) hasUnmigratedKeys(ctx context.Context, version int) (bool, error) {
+	m, err := model.MigrationFrom(version)
+	if err != nil {
+		return false, err
+	}
+	for _, prefix := range m.Prefixes() {
+		resp, err := c.client().Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithCountOnly())
+		if err != nil {
+			return false, toStorageError(prefix, err)
+		}
+		if resp.Count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// migrate moves all keys of a layout version to their path in the next version and converts
+// their values. Keys whose new path is already in use and values that can't be converted are left
+// in place.
+func (c *EtcdV3Client) migrate(ctx context.Context, m model.Migration) error {
+	for _, prefix := range m.Prefixes() {
+		resp, err := c.client().Get(ctx, prefix, clientv3.WithPrefix())
+		if err != nil {
+			return toStorageError(prefix, err)
+		}
+		for _, kv := range resp.Kvs {
+			oldKey := string(kv.Key)
+			newKey := m.Rewrite(oldKey)
+			logCxt := log.WithFields(log.Fields{"from": oldKey, "to": newKey, "version": m.From})
+			value := kv.Value
+			if m.Convert != nil {
+				if value, err = m.Convert(oldKey, kv.Value); err != nil {
+					logCxt.WithError(err).Warn("Failed to convert value during migration, leaving it in place")
+					continue
+				}
+			}
+			if newKey == oldKey && bytes.Equal(value, kv.Value) {
+				continue
+			}
+
+			cmps := []clientv3.Cmp{clientv3.Compare(clientv3.ModRevision(oldKey), "=", kv.ModRevision)}
+			ops := []clientv3.Op{clientv3.OpPut(newKey, string(value))}
+			if newKey != oldKey {
+				cmps = append(cmps, notFound(newKey))
+				ops = append(ops, clientv3.OpDelete(oldKey))
+			}
+			txResp, err := c.client().KV.Txn(ctx).If(cmps...).Then(ops...).Commit()
+			if err != nil {
+				return toStorageError(oldKey, err)
+			}
+			if !txResp.Succeeded {
+				logCxt.Warn("Key already exists or changed during migration, leaving it in place")
+				continue
+			}
+			logCxt.Debug("Key migrated")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdv3_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/coreos/etcd/clientv3"
+	"github.com/golang/protobuf/proto"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/etcdv3/storetest"
+	"github.com/nimbess/stargazer/pkg/model"
+	"github.com/nimbess/stargazer/pkg/model/node"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEtcdV3Client_EnsureSchema_MigrateLater(t *testing.T) {
+	c, err := etcdv3.New(storetest.EtcdConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// a node written by a version using the unversioned layout
+	node := &model.KVPair{Key: model.NodeKey{Hostname: storetest.Root()[1:]}, Value: &model.Node{Name: "node1"}}
+	if err := c.Create(ctx, node); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		model.SetPrefix(model.LegacyRoot, "")
+		c.Delete(context.Background(), node.Key)
+	}()
+
+	root := storetest.Root()
+	if err := model.SetPrefix(root, ""); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		model.SetPrefix(root, "")
+		c.Delete(context.Background(), node.Key)
+		c.Delete(context.Background(), model.SchemaVersionKey{})
+	}()
+
+	// migration disabled, the node is left in place and the version is not recorded
+	if err := c.EnsureSchema(ctx, false); err != nil {
+		t.Fatalf("Expected no error with migration disabled, got %v", err)
+	}
+	if _, err := c.Get(ctx, model.SchemaVersionKey{}); !etcdv3.IsNotFound(err) {
+		t.Errorf("Expected no version recorded with unmigrated keys, got %v", err)
+	}
+	if _, err := c.Get(ctx, node.Key); !etcdv3.IsNotFound(err) {
+		t.Errorf("Expected the node not to be migrated, got %v", err)
+	}
+
+	// migration enabled later, the node is moved under the prefix
+	if err := c.EnsureSchema(ctx, true); err != nil {
+		t.Fatalf("Expected no error with migration enabled, got %v", err)
+	}
+	if _, err := c.Get(ctx, model.SchemaVersionKey{}); err != nil {
+		t.Errorf("Expected the version to be recorded, got %v", err)
+	}
+	got, err := c.Get(ctx, node.Key)
+	if err != nil {
+		t.Fatalf("Expected the node to be migrated, got %v", err)
+	}
+	if got.Value.(*model.Node).Name != "node1" {
+		t.Errorf("Unexpected migrated node %+v", got.Value)
+	}
+	model.SetPrefix(model.LegacyRoot, "")
+	if _, err := c.Get(ctx, node.Key); !etcdv3.IsNotFound(err) {
+		t.Errorf("Expected the legacy key to be removed, got %v", err)
+	}
+}
+
+func TestEtcdV3Client_EnsureSchema_ConvertPolicies(t *testing.T) {
+	cfg := storetest.EtcdConfig(t)
+	c, err := etcdv3.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	raw, err := clientv3.New(clientv3.Config{Endpoints: []string{cfg.EtcdEndpoints}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer raw.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	root := storetest.Root()
+	if err := model.SetPrefix(root, ""); err != nil {
+		t.Fatal(err)
+	}
+	defer model.SetPrefix(model.LegacyRoot, "")
+
+	// a policy written as the UnifiedNetworkPolicy object by a version using layout version 2
+	key := model.UNPKey{Name: "default/p1"}
+	legacy := `{"kind":"UnifiedNetworkPolicy","metadata":{"name":"p1","namespace":"default"},"spec":{"network":"net1"}}`
+	for _, kv := range []*model.KVPair{
+		{Key: key, Value: json.RawMessage(legacy)},
+		{Key: model.SchemaVersionKey{}, Value: "2"},
+	} {
+		if err := c.Create(ctx, kv); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer func() {
+		c.Delete(context.Background(), key)
+		c.Delete(context.Background(), model.SchemaVersionKey{})
+	}()
+
+	if err := c.EnsureSchema(ctx, false); err == nil {
+		t.Error("Expected an error with migration disabled")
+	}
+	if err := c.EnsureSchema(ctx, true); err != nil {
+		t.Fatalf("Expected no error with migration enabled, got %v", err)
+	}
+	version, err := c.Get(ctx, model.SchemaVersionKey{})
+	if err != nil || version.Value != strconv.Itoa(model.SchemaVersion) {
+		t.Errorf("Expected the version to be recorded, got %+v, %v", version, err)
+	}
+	got, err := c.Get(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &model.Policy{Metadata: &node.ObjectMeta{Namespace: "default", Name: "p1"},
+		Spec: &node.PolicySpec{Network: "net1"}}
+	if !proto.Equal(got.Value.(*model.Policy), expected) {
+		t.Errorf("Expected: %v\nGot: %v", expected, got.Value)
+	}
+	path, _ := model.KeyToDefaultPath(key)
+	resp, err := raw.Get(ctx, path)
+	if err != nil || len(resp.Kvs) != 1 || strings.Contains(string(resp.Kvs[0].Value), "kind") {
+		t.Errorf("Expected the value to be stored as a Policy, got %v, %v", resp, err)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storetest
+
+import (
+	"fmt"
+	"github.com/coreos/etcd/embed"
+	"github.com/coreos/pkg/capnslog"
+	"github.com/nimbess/stargazer/pkg/config"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// etcd is the server started in process by EtcdConfig, shared by the tests of a package.
+var etcd struct {
+	once     sync.Once
+	server   *embed.Etcd
+	dir      string
+	endpoint string
+	err      error
+}
+
+// EtcdConfig returns the configuration of an etcd server started in process for the tests, the
+// server is stopped by Main.
+func EtcdConfig(t *testing.T) *config.Config {
+	etcd.once.Do(startEtcd)
+	if etcd.err != nil {
+		t.Fatalf("Failed to start etcd: %v", etcd.err)
+	}
+	cfg := config.NewConfig()
+	cfg.EtcdEndpoints = etcd.endpoint
+	cfg.EtcdDialTimeout = 5 * time.Second
+	return cfg
+}
+
+// Main runs the tests and stops the etcd server, to be called by the TestMain of the packages using
+// EtcdConfig. Returns the exit code.
+func Main(m *testing.M) int {
+	code := m.Run()
+	if etcd.server != nil {
+		etcd.server.Close()
+	}
+	if etcd.dir != "" {
+		os.RemoveAll(etcd.dir)
+	}
+	return code
+}
+
+// startEtcd starts a single member etcd server listening on free local ports.
+func startEtcd() {
+	capnslog.SetGlobalLogLevel(capnslog.CRITICAL)
+	if etcd.dir, etcd.err = ioutil.TempDir("", "stargazer-etcd"); etcd.err != nil {
+		return
+	}
+	cfg := embed.NewConfig()
+	cfg.Dir = etcd.dir
+	for _, urls := range []*[]url.URL{&cfg.LCUrls, &cfg.LPUrls} {
+		u, err := freeURL()
+		if err != nil {
+			etcd.err = err
+			return
+		}
+		*urls = []url.URL{*u}
+	}
+	cfg.ACUrls, cfg.APUrls = cfg.LCUrls, cfg.LPUrls
+	cfg.InitialCluster = cfg.InitialClusterFromName(cfg.Name)
+
+	server, err := embed.StartEtcd(cfg)
+	if err != nil {
+		etcd.err = err
+		return
+	}
+	select {
+	case <-server.Server.ReadyNotify():
+	case err := <-server.Err():
+		server.Close()
+		etcd.err = err
+		return
+	case <-time.After(time.Minute):
+		server.Close()
+		etcd.err = fmt.Errorf("not ready after a minute")
+		return
+	}
+	etcd.server = server
+	etcd.endpoint = cfg.ACUrls[0].String()
+}
+
+// freeURL returns the URL of a local port nothing listens on.
+func freeURL() (*url.URL, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	defer l.Close()
+	return &url.URL{Scheme: "http", Host: l.Addr().String()}, nil
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package storetest holds the tests shared by the implementations of the datastore client.
+package storetest
+
+import (
+	"context"
+	"fmt"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/model"
+	"testing"
+	"time"
+)
+
+// Root returns a prefix of our own, so that existing keys are left untouched.
+func Root() string {
+	return fmt.Sprintf("/stargazer-test-%d", time.Now().UnixNano())
+}
+
+// Conformance checks that the client follows the etcd semantics, using keys under root. The keys
+// written are deleted.
+func Conformance(t *testing.T, c etcdv3.Client, root string) {
+	if err := model.SetPrefix(root, ""); err != nil {
+		t.Fatal(err)
+	}
+	defer model.SetPrefix(model.LegacyRoot, "")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	node1 := &model.KVPair{Key: model.NodeKey{Hostname: "node1"}, Value: &model.Node{Name: "node1"}}
+	node2 := &model.KVPair{Key: model.NodeKey{Hostname: "node2"}, Value: &model.Node{Name: "node2"}}
+
+	// create
+	if err := c.Create(ctx, node1); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if node1.Revision == "" {
+		t.Error("create: revision not set")
+	}
+	if err := c.Create(ctx, node1); !etcdv3.IsExists(err) {
+		t.Errorf("create existing: expected exists error, got %v", err)
+	}
+
+	// get
+	got, err := c.Get(ctx, node1.Key)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Value.(*model.Node).Name != "node1" || got.Revision != node1.Revision {
+		t.Errorf("get: unexpected %+v", got)
+	}
+	if _, err := c.Get(ctx, node2.Key); !etcdv3.IsNotFound(err) {
+		t.Errorf("get missing: expected not found error, got %v", err)
+	}
+
+	// watch from the revision of the first create
+	watchCtx, stopWatch := context.WithCancel(ctx)
+	defer stopWatch()
+	events, err := c.Watch(watchCtx, model.NodeListOptions{}, node1.Revision)
+	if err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+
+	// update
+	stale := &model.KVPair{Key: node1.Key, Value: &model.Node{Name: "stale"}, Revision: node1.Revision}
+	updated := &model.KVPair{Key: node1.Key, Value: &model.Node{Name: "node1", Hostname: "host1"}, Revision: node1.Revision}
+	if err := c.Update(ctx, updated); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := c.Update(ctx, stale); !etcdv3.IsConflict(err) {
+		t.Errorf("update stale revision: expected conflict error, got %v", err)
+	}
+	if err := c.Update(ctx, &model.KVPair{Key: node2.Key, Value: node2.Value}); !etcdv3.IsNotFound(err) {
+		t.Errorf("update missing: expected not found error, got %v", err)
+	}
+
+	// list
+	if err := c.Create(ctx, node2); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	list, err := c.List(ctx, model.NodeListOptions{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list.KVPairs) != 2 || list.KVPairs[0].Key != node1.Key || list.KVPairs[1].Key != node2.Key {
+		t.Errorf("list: unexpected %+v", list.KVPairs)
+	}
+	if list.Revision != node2.Revision {
+		t.Errorf("list: expected revision %s, got %s", node2.Revision, list.Revision)
+	}
+
+	// delete
+	if err := c.Delete(ctx, node2.Key); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := c.Delete(ctx, node2.Key); !etcdv3.IsNotFound(err) {
+		t.Errorf("delete missing: expected not found error, got %v", err)
+	}
+
+	// the watch sees the changes after the first create, in order
+	expected := []struct {
+		eventType etcdv3.WatchEventType
+		key       model.Key
+	}{
+		{etcdv3.WatchModified, node1.Key},
+		{etcdv3.WatchAdded, node2.Key},
+		{etcdv3.WatchDeleted, node2.Key},
+	}
+	for _, exp := range expected {
+		select {
+		case e := <-events:
+			var k model.Key
+			if e.New != nil {
+				k = e.New.Key
+			} else if e.Old != nil {
+				k = e.Old.Key
+			}
+			if e.Type != exp.eventType || k != exp.key {
+				t.Errorf("watch: expected %s %v, got %+v", exp.eventType, exp.key, e)
+			}
+			if e.Type == etcdv3.WatchModified && e.Old.Value.(*model.Node).Hostname != "" {
+				t.Errorf("watch: unexpected old value %+v", e.Old.Value)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("watch: timed out waiting for %s %v", exp.eventType, exp.key)
+		}
+	}
+	stopWatch()
+	for range events {
+		// drain until closed
+	}
+
+	if err := c.Delete(ctx, node1.Key); err != nil {
+		t.Errorf("delete: %v", err)
+	}
+}
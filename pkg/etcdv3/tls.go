@@ -0,0 +1,161 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdv3
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/coreos/etcd/clientv3"
+	"github.com/nimbess/stargazer/pkg/config"
+	log "github.com/sirupsen/logrus"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// certReloadInterval is how often the TLS files are checked for changes
+const certReloadInterval = 30 * time.Second
+
+// newEtcdConfig builds the etcd client configuration, including TLS and authentication.
+func newEtcdConfig(config *config.Config) (clientv3.Config, error) {
+	etcdEndpoints := strings.Split(config.EtcdEndpoints, ",")
+	if len(etcdEndpoints) == 0 {
+		return clientv3.Config{}, errors.New("no etcd endpoints specified")
+	}
+
+	etcdConfig := clientv3.Config{
+		Endpoints:   etcdEndpoints,
+		DialTimeout: config.EtcdDialTimeout,
+		Username:    config.EtcdUsername,
+		Password:    config.EtcdPassword,
+	}
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return clientv3.Config{}, err
+	}
+	etcdConfig.TLS = tlsConfig
+	return etcdConfig, nil
+}
+
+// newTLSConfig builds the TLS configuration from the CA, certificate and key files.
+// Returns nil if TLS is not configured.
+func newTLSConfig(config *config.Config) (*tls.Config, error) {
+	if config.EtcdCAFile == "" && config.EtcdCertFile == "" && config.EtcdKeyFile == "" {
+		return nil, nil
+	}
+	if (config.EtcdCertFile == "") != (config.EtcdKeyFile == "") {
+		return nil, errors.New("both etcd certificate and key files must be specified")
+	}
+
+	tlsConfig := &tls.Config{ServerName: config.EtcdServerName}
+	if config.EtcdCAFile != "" {
+		ca, err := ioutil.ReadFile(config.EtcdCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read etcd CA file: %s", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in etcd CA file %s", config.EtcdCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if config.EtcdCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.EtcdCertFile, config.EtcdKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load etcd certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// tlsFiles returns the TLS files configured for etcd.
+func tlsFiles(config *config.Config) []string {
+	var files []string
+	for _, f := range []string{config.EtcdCAFile, config.EtcdCertFile, config.EtcdKeyFile} {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// filesDigest returns a digest of the content of the files, so that rotated files are
+// detected whether they are rewritten in place or replaced through a symlink.
+func filesDigest(files []string) ([]byte, error) {
+	h := sha256.New()
+	for _, f := range files {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		h.Write(data)
+	}
+	return h.Sum(nil), nil
+}
+
+// watchCerts reconnects to etcd with the new TLS configuration whenever the TLS files change.
+// Files that can't be read or parsed, e.g. while only part of them was rotated, are retried
+// on the next check.
+func (c *EtcdV3Client) watchCerts(config *config.Config) {
+	files := tlsFiles(config)
+	digest, err := filesDigest(files)
+	if err != nil {
+		log.WithError(err).Warn("Failed to read etcd TLS files")
+	}
+
+	ticker := time.NewTicker(certReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+		}
+		digest = c.reloadCerts(config, files, digest)
+	}
+}
+
+// reloadCerts reconnects to etcd if the content of the TLS files no longer matches the digest,
+// and returns the digest of the files in use. The current connection is kept if the files can't
+// be loaded.
+func (c *EtcdV3Client) reloadCerts(config *config.Config, files []string, digest []byte) []byte {
+	newDigest, err := filesDigest(files)
+	if err != nil {
+		log.WithError(err).Warn("Failed to read etcd TLS files")
+		return digest
+	}
+	if bytes.Equal(digest, newDigest) {
+		return digest
+	}
+
+	log.WithField("files", files).Info("etcd TLS files changed, reconnecting")
+	etcdConfig, err := newEtcdConfig(config)
+	if err != nil {
+		log.WithError(err).Warn("Failed to load etcd TLS files")
+		return digest
+	}
+	etcdClient, err := clientv3.New(etcdConfig)
+	if err != nil {
+		log.WithError(err).Warn("Failed to reconnect to etcd")
+		return digest
+	}
+	c.swapClient(etcdClient)
+	return newDigest
+}
@@ -0,0 +1,220 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdv3
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/nimbess/stargazer/pkg/config"
+)
+
+// testCA issues the certificates of the TLS tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	ca := &testCA{}
+	ca.cert, ca.key, ca.pem = issueCert(t, nil, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "stargazer-test-ca"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	})
+	return ca
+}
+
+// issue returns a certificate and key in PEM format, signed by the CA.
+func (ca *testCA) issue(t *testing.T, template *x509.Certificate) ([]byte, []byte) {
+	_, key, certPEM := issueCert(t, ca, template)
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certPEM, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+// issueCert creates a certificate from the template, self signed if ca is nil.
+func issueCert(t *testing.T, ca *testCA, template *x509.Certificate) (*x509.Certificate, *ecdsa.PrivateKey, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	parent, signer := template, key
+	if ca != nil {
+		parent, signer = ca.cert, ca.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// clientCertRecorder is a TLS server sending the common name of the client certificate of each
+// connection, the connections are closed after the handshake.
+func clientCertRecorder(t *testing.T, ca *testCA) (string, <-chan string) {
+	certPEM, keyPEM := ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "etcd"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+		NextProtos:   []string{"h2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make(chan string, 100)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			tlsConn := conn.(*tls.Conn)
+			if tlsConn.Handshake() == nil {
+				select {
+				case names <- tlsConn.ConnectionState().PeerCertificates[0].Subject.CommonName:
+				default:
+				}
+			}
+			conn.Close()
+		}
+	}()
+	return l.Addr().String(), names
+}
+
+// expectClientCert waits for a connection with the client certificate.
+func expectClientCert(t *testing.T, names <-chan string, name string) {
+	t.Helper()
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case n := <-names:
+			if n == name {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("Expected a connection with the %s certificate", name)
+		}
+	}
+}
+
+func TestEtcdV3Client_reloadCerts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stargazer-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ca := newTestCA(t)
+	addr, names := clientCertRecorder(t, ca)
+
+	conf := config.NewConfig()
+	conf.EtcdEndpoints = "https://" + addr
+	conf.EtcdDialTimeout = 0
+	conf.EtcdCAFile = filepath.Join(dir, "ca.pem")
+	conf.EtcdCertFile = filepath.Join(dir, "cert.pem")
+	conf.EtcdKeyFile = filepath.Join(dir, "key.pem")
+	writeClientCert := func(name string) {
+		certPEM, keyPEM := ca.issue(t, &x509.Certificate{
+			Subject:     pkix.Name{CommonName: name},
+			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		})
+		files := map[string][]byte{conf.EtcdCAFile: ca.pem, conf.EtcdCertFile: certPEM, conf.EtcdKeyFile: keyPEM}
+		for file, data := range files {
+			if err := ioutil.WriteFile(file, data, 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	writeClientCert("first")
+	etcdConfig, err := newEtcdConfig(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	etcdClient, err := clientv3.New(etcdConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &EtcdV3Client{etcdClient: etcdClient, stopCh: make(chan struct{})}
+	defer c.Close()
+	expectClientCert(t, names, "first")
+
+	files := tlsFiles(conf)
+	digest, err := filesDigest(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.reloadCerts(conf, files, digest); c.client() != etcdClient {
+		t.Fatal("Expected the client to be kept while the files are unchanged")
+	}
+
+	writeClientCert("second")
+	digest = c.reloadCerts(conf, files, digest)
+	reloaded := c.client()
+	if reloaded == etcdClient {
+		t.Fatal("Expected the client to be replaced after the files changed")
+	}
+	expectClientCert(t, names, "second")
+
+	// an invalid key fails the reload, the connections made since use the previous certificate
+	if err := ioutil.WriteFile(conf.EtcdKeyFile, []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if newDigest := c.reloadCerts(conf, files, digest); string(newDigest) != string(digest) {
+		t.Error("Expected the digest of the previous files to be kept after a failed reload")
+	}
+	if c.client() != reloaded {
+		t.Fatal("Expected the client to be kept after a failed reload")
+	}
+	for len(names) > 0 {
+		<-names
+	}
+	expectClientCert(t, names, "second")
+}
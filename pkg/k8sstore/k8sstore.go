@@ -0,0 +1,362 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package k8sstore is a datastore storing the Nimbess model as NimbessState objects in the
+// Kubernetes API, for clusters that don't run a dedicated etcd for Nimbess.
+package k8sstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	unpv1 "github.com/nimbess/stargazer/pkg/crd/api/unp/v1"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/model"
+	log "github.com/sirupsen/logrus"
+	"sort"
+	"strconv"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+)
+
+// stateResource is the resource of the NimbessState CRD
+var stateResource = unpv1.SchemeGroupVersion.WithResource(unpv1.StateCRDPlural)
+
+// KubernetesClient implements the datastore Client on top of NimbessState objects. Each key is
+// stored in its own object, named after a hash of the key path, and resource versions are used
+// as revisions. Old values are not reported for modified keys by Watch, as the Kubernetes API
+// does not provide them.
+type KubernetesClient struct {
+	client    dynamic.ResourceInterface
+	namespace string
+}
+
+// New returns a datastore storing the objects in the given namespace.
+func New(client dynamic.Interface, namespace string) etcdv3.Client {
+	log.WithField("namespace", namespace).Info("Using Kubernetes datastore")
+	return &KubernetesClient{
+		client:    client.Resource(stateResource).Namespace(namespace),
+		namespace: namespace,
+	}
+}
+
+// objectName returns the name of the object holding a key.
+func objectName(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	return "nimbess-" + hex.EncodeToString(sum[:16])
+}
+
+func (c *KubernetesClient) Create(ctx context.Context, d *model.KVPair) error {
+	log.WithFields(log.Fields{"key": d.Key.String(), "value": d.Value}).Debug("Create request")
+
+	obj, path, err := c.toObject(d)
+	if err != nil {
+		return err
+	}
+	created, err := c.client.Create(obj, metav1.CreateOptions{})
+	if err != nil {
+		return toStorageError(path, err)
+	}
+	d.Revision = created.GetResourceVersion()
+	return nil
+}
+
+func (c *KubernetesClient) Update(ctx context.Context, d *model.KVPair) error {
+	log.WithFields(log.Fields{"key": d.Key.String(), "value": d.Value}).Debug("Update request")
+
+	obj, path, err := c.toObject(d)
+	if err != nil {
+		return err
+	}
+	obj.SetResourceVersion(d.Revision)
+	if d.Revision == "" {
+		current, err := c.client.Get(obj.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return toStorageError(path, err)
+		}
+		obj.SetResourceVersion(current.GetResourceVersion())
+	}
+	updated, err := c.client.Update(obj, metav1.UpdateOptions{})
+	if err != nil {
+		return toStorageError(path, err)
+	}
+	d.Revision = updated.GetResourceVersion()
+	return nil
+}
+
+func (c *KubernetesClient) Get(ctx context.Context, k model.Key) (*model.KVPair, error) {
+	log.WithFields(log.Fields{"key": k.String()}).Debug("Get request")
+
+	path, err := model.KeyToDefaultPath(k)
+	if err != nil {
+		return nil, etcdv3.NewInvalidObjError(k.String(), err)
+	}
+	obj, err := c.client.Get(objectName(path), metav1.GetOptions{})
+	if err != nil {
+		return nil, toStorageError(path, err)
+	}
+	state, err := toState(obj)
+	if err != nil {
+		return nil, etcdv3.NewInvalidObjError(path, err)
+	}
+	return parseState(k, state)
+}
+
+func (c *KubernetesClient) List(ctx context.Context, l model.ListInterface) (*model.KVPairList, error) {
+	prefix := model.ListOptionsToDefaultPathRoot(l)
+	log.WithFields(log.Fields{"prefix": prefix}).Debug("List request")
+
+	objs, err := c.client.List(metav1.ListOptions{})
+	if err != nil {
+		return nil, toStorageError(prefix, err)
+	}
+	var states []*unpv1.NimbessState
+	for i := range objs.Items {
+		state, err := toState(&objs.Items[i])
+		if err == nil && strings.HasPrefix(state.Spec.Key, prefix) {
+			states = append(states, state)
+		}
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Spec.Key < states[j].Spec.Key
+	})
+
+	list := &model.KVPairList{Revision: objs.GetResourceVersion()}
+	for _, state := range states {
+		k := l.KeyFromDefaultPath(state.Spec.Key)
+		if k == nil {
+			continue
+		}
+		d, err := parseState(k, state)
+		if err != nil {
+			log.WithError(err).WithField("key", state.Spec.Key).Warn("Failed to parse value, skipping")
+			continue
+		}
+		list.KVPairs = append(list.KVPairs, d)
+	}
+	return list, nil
+}
+
+func (c *KubernetesClient) Delete(ctx context.Context, k model.Key) error {
+	log.WithFields(log.Fields{"key": k.String()}).Debug("Delete request")
+
+	path, err := model.KeyToDefaultDeletePath(k)
+	if err != nil {
+		return etcdv3.NewInvalidObjError(k.String(), err)
+	}
+	if err := c.client.Delete(objectName(path), &metav1.DeleteOptions{}); err != nil {
+		return toStorageError(path, err)
+	}
+	return nil
+}
+
+func (c *KubernetesClient) Watch(ctx context.Context, l model.ListInterface, revision string) (<-chan etcdv3.WatchEvent, error) {
+	prefix := model.ListOptionsToDefaultPathRoot(l)
+	log.WithFields(log.Fields{"prefix": prefix, "revision": revision}).Debug("Watch request")
+
+	// The watch events only hold the new objects, the current values are listed to set the old ones.
+	current, err := c.List(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+	if revision == "" {
+		// An empty resource version would replay the existing objects, start from the current one instead.
+		revision = current.Revision
+	}
+	previous := map[model.Key]*model.KVPair{}
+	for _, d := range current.KVPairs {
+		previous[d.Key] = d
+	}
+	w, err := c.client.Watch(metav1.ListOptions{ResourceVersion: revision})
+	if err != nil {
+		return nil, toStorageError(prefix, err)
+	}
+
+	events := make(chan etcdv3.WatchEvent)
+	go func() {
+		defer close(events)
+		defer w.Stop()
+		for {
+			var ev watch.Event
+			var ok bool
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok = <-w.ResultChan():
+				if !ok {
+					return
+				}
+			}
+			e, ok := toWatchEvent(l, prefix, ev)
+			if !ok {
+				continue
+			}
+			switch e.Type {
+			case etcdv3.WatchAdded:
+				previous[e.New.Key] = e.New
+			case etcdv3.WatchModified:
+				e.Old = previous[e.New.Key]
+				previous[e.New.Key] = e.New
+			case etcdv3.WatchDeleted:
+				delete(previous, e.Old.Key)
+			}
+			select {
+			case events <- e:
+			case <-ctx.Done():
+				return
+			}
+			if e.Type == etcdv3.WatchError {
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+func (c *KubernetesClient) EnsureSchema(ctx context.Context, migrate bool) error {
+	current, err := c.Get(ctx, model.SchemaVersionKey{})
+	if etcdv3.IsNotFound(err) {
+		return c.Create(ctx, &model.KVPair{Key: model.SchemaVersionKey{}, Value: strconv.Itoa(model.SchemaVersion)})
+	}
+	if err != nil {
+		return err
+	}
+	if current.Value.(string) != strconv.Itoa(model.SchemaVersion) {
+		return fmt.Errorf("key layout version %s under %s is not supported by the Kubernetes datastore",
+			current.Value, model.Prefix())
+	}
+	return nil
+}
+
+// LeaseExpired returns nil, leases are not supported by the Kubernetes datastore.
+func (c *KubernetesClient) LeaseExpired() <-chan struct{} {
+	return nil
+}
+
+// Close does nothing, the Kubernetes client has no connection to release.
+func (c *KubernetesClient) Close() error {
+	return nil
+}
+
+// toObject converts a KVPair to the object holding it, also returns the path of the key.
+func (c *KubernetesClient) toObject(d *model.KVPair) (*unstructured.Unstructured, string, error) {
+	path, err := model.KeyToDefaultPath(d.Key)
+	if err != nil {
+		return nil, "", etcdv3.NewInvalidObjError(d.Key.String(), err)
+	}
+	value, err := model.SerializeValue(d)
+	if err != nil {
+		return nil, "", etcdv3.NewInvalidObjError(path, err)
+	}
+	state := &unpv1.NimbessState{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: unpv1.SchemeGroupVersion.String(),
+			Kind:       "NimbessState",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: objectName(path), Namespace: c.namespace},
+		Spec:       unpv1.NimbessStateSpec{Key: path},
+	}
+	// Enveloped values may be binary, which can't be held by a JSON string.
+	if model.IsEnveloped(value) {
+		state.Spec.Data = value
+	} else {
+		state.Spec.Value = string(value)
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(state)
+	if err != nil {
+		return nil, "", etcdv3.NewInvalidObjError(path, err)
+	}
+	return &unstructured.Unstructured{Object: content}, path, nil
+}
+
+// toState converts an object to a NimbessState.
+func toState(obj *unstructured.Unstructured) (*unpv1.NimbessState, error) {
+	state := &unpv1.NimbessState{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// parseState returns the KVPair held by a NimbessState.
+func parseState(k model.Key, state *unpv1.NimbessState) (*model.KVPair, error) {
+	value := []byte(state.Spec.Value)
+	if len(state.Spec.Data) > 0 {
+		value = state.Spec.Data
+	}
+	v, err := model.ParseValue(k, value)
+	if err != nil {
+		return nil, etcdv3.NewInvalidObjError(state.Spec.Key, err)
+	}
+	return &model.KVPair{Key: k, Value: v, Revision: state.ResourceVersion}, nil
+}
+
+// toWatchEvent converts a Kubernetes watch event. Returns false for keys that are not part of the list.
+func toWatchEvent(l model.ListInterface, prefix string, ev watch.Event) (etcdv3.WatchEvent, bool) {
+	if ev.Type == watch.Error {
+		return etcdv3.WatchEvent{Type: etcdv3.WatchError, Error: toStorageError(prefix, apierrors.FromObject(ev.Object))}, true
+	}
+	obj, ok := ev.Object.(*unstructured.Unstructured)
+	if !ok {
+		return etcdv3.WatchEvent{}, false
+	}
+	state, err := toState(obj)
+	if err != nil || !strings.HasPrefix(state.Spec.Key, prefix) {
+		return etcdv3.WatchEvent{}, false
+	}
+	k := l.KeyFromDefaultPath(state.Spec.Key)
+	if k == nil {
+		return etcdv3.WatchEvent{}, false
+	}
+	d, err := parseState(k, state)
+	if err != nil {
+		return etcdv3.WatchEvent{Type: etcdv3.WatchError, Error: err}, true
+	}
+
+	switch ev.Type {
+	case watch.Added:
+		return etcdv3.WatchEvent{Type: etcdv3.WatchAdded, New: d}, true
+	case watch.Modified:
+		return etcdv3.WatchEvent{Type: etcdv3.WatchModified, New: d}, true
+	case watch.Deleted:
+		return etcdv3.WatchEvent{Type: etcdv3.WatchDeleted, Old: d}, true
+	}
+	return etcdv3.WatchEvent{}, false
+}
+
+// toStorageError maps the errors returned by the Kubernetes API to a StorageError.
+func toStorageError(key string, err error) error {
+	switch {
+	case apierrors.IsNotFound(err):
+		return etcdv3.NewKeyNotFoundError(key, 0)
+	case apierrors.IsAlreadyExists(err):
+		return etcdv3.NewKeyExistsError(key, 0)
+	case apierrors.IsConflict(err), apierrors.IsGone(err), apierrors.IsResourceExpired(err):
+		return &etcdv3.StorageError{Code: etcdv3.ErrCodeResourceVersionConflicts, Key: key,
+			AdditionalErrorMsg: err.Error(), Err: err}
+	case apierrors.IsInvalid(err), apierrors.IsBadRequest(err):
+		return etcdv3.NewInvalidObjError(key, err)
+	case apierrors.IsServerTimeout(err), apierrors.IsTimeout(err), apierrors.IsServiceUnavailable(err),
+		apierrors.IsTooManyRequests(err), apierrors.IsInternalError(err):
+		return etcdv3.NewUnreachableError(key, err)
+	}
+	return err
+}
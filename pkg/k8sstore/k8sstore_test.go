@@ -0,0 +1,134 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sstore_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/etcdv3/storetest"
+	"github.com/nimbess/stargazer/pkg/k8sstore"
+	"github.com/nimbess/stargazer/pkg/model"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestKubernetesClient(t *testing.T) {
+	ctx := context.Background()
+	c := k8sstore.New(fake.NewSimpleDynamicClient(runtime.NewScheme()), "kube-system")
+
+	node1 := &model.KVPair{Key: model.NodeKey{Hostname: "node1"}, Value: &model.Node{Name: "node1"}}
+	node2 := &model.KVPair{Key: model.NodeKey{Hostname: "node2"}, Value: &model.Node{Name: "node2"}}
+	for _, kv := range []*model.KVPair{node2, node1} {
+		if err := c.Create(ctx, kv); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	if err := c.Create(ctx, node1); !etcdv3.IsExists(err) {
+		t.Errorf("create existing: expected exists error, got %v", err)
+	}
+
+	got, err := c.Get(ctx, node1.Key)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Value.(*model.Node).Name != "node1" {
+		t.Errorf("get: unexpected %+v", got.Value)
+	}
+
+	list, err := c.List(ctx, model.NodeListOptions{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list.KVPairs) != 2 || list.KVPairs[0].Key != node1.Key || list.KVPairs[1].Key != node2.Key {
+		t.Errorf("list: unexpected %+v", list.KVPairs)
+	}
+
+	if err := c.Delete(ctx, node1.Key); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := c.Get(ctx, node1.Key); !etcdv3.IsNotFound(err) {
+		t.Errorf("get deleted: expected not found error, got %v", err)
+	}
+	if err := c.Delete(ctx, node1.Key); !etcdv3.IsNotFound(err) {
+		t.Errorf("delete missing: expected not found error, got %v", err)
+	}
+}
+
+// newVersionedClient returns a fake dynamic client versioning the objects like the API server:
+// each write gets a new resource version, lists return the current one and updates of a stale
+// version conflict.
+func newVersionedClient() dynamic.Interface {
+	scheme := runtime.NewScheme()
+	client := fake.NewSimpleDynamicClient(scheme)
+	tracker := k8stesting.NewObjectTracker(scheme, serializer.NewCodecFactory(scheme).UniversalDecoder())
+	reaction := k8stesting.ObjectReaction(tracker)
+
+	var lock sync.Mutex
+	version := 0
+	client.PrependReactor("*", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		switch a := action.(type) {
+		case k8stesting.CreateActionImpl:
+			version++
+			a.Object = a.Object.DeepCopyObject()
+			a.Object.(*unstructured.Unstructured).SetResourceVersion(strconv.Itoa(version))
+			action = a
+		case k8stesting.UpdateActionImpl:
+			obj := a.Object.DeepCopyObject().(*unstructured.Unstructured)
+			current, err := tracker.Get(a.GetResource(), a.GetNamespace(), obj.GetName())
+			if err != nil {
+				return true, nil, err
+			}
+			if m, _ := meta.Accessor(current); m.GetResourceVersion() != obj.GetResourceVersion() {
+				return true, nil, apierrors.NewConflict(a.GetResource().GroupResource(), obj.GetName(),
+					fmt.Errorf("stale resource version %s", obj.GetResourceVersion()))
+			}
+			version++
+			obj.SetResourceVersion(strconv.Itoa(version))
+			a.Object = obj
+			action = a
+		case k8stesting.DeleteActionImpl:
+			version++
+		case k8stesting.ListActionImpl:
+			handled, obj, err := reaction(action)
+			if list, ok := obj.(*unstructured.UnstructuredList); ok {
+				list.SetResourceVersion(strconv.Itoa(version))
+			}
+			return handled, obj, err
+		}
+		return reaction(action)
+	})
+	client.PrependWatchReactor("*", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w, err := tracker.Watch(action.GetResource(), action.GetNamespace())
+		return true, w, err
+	})
+	return client
+}
+
+func TestKubernetesClient_Conformance(t *testing.T) {
+	storetest.Conformance(t, k8sstore.New(newVersionedClient(), "kube-system"), storetest.Root())
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"github.com/nimbess/stargazer/pkg/errors"
+	"github.com/nimbess/stargazer/pkg/model/node"
+	"reflect"
+	"strings"
+)
+
+// Directories holding the Multus network attachments and the pod interfaces under the Nimbess prefix
+const (
+	attachmentDir = "attachment"
+	interfaceDir  = "interface"
+)
+
+var (
+	typePodInterface = reflect.TypeOf(PodInterface{})
+)
+
+// PodInterface is the value of a PodInterfaceKey, generated from node/attachment.proto.
+type PodInterface = node.PodInterface
+
+// NetworkAttachmentKey is the key of a Multus NetworkAttachmentDefinition, its value is a Network
+// named <namespace>/<name>.
+type NetworkAttachmentKey struct {
+	Namespace string
+	Name      string
+}
+
+func (key NetworkAttachmentKey) defaultDeletePath() (string, error) {
+	return key.defaultPath()
+}
+
+func (key NetworkAttachmentKey) defaultPath() (string, error) {
+	if key.Namespace == "" {
+		return "", errors.ErrorInsufficientIdentifiers{Name: "namespace"}
+	}
+	if key.Name == "" {
+		return "", errors.ErrorInsufficientIdentifiers{Name: "name"}
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", Prefix(), attachmentDir, key.Namespace, key.Name), nil
+}
+
+func (key NetworkAttachmentKey) valueType() (reflect.Type, error) {
+	return typeNetwork, nil
+}
+
+func (key NetworkAttachmentKey) String() string {
+	return fmt.Sprintf("NetworkAttachment(namespace=%s, name=%s)", key.Namespace, key.Name)
+}
+
+// NetworkAttachmentListOptions lists the network attachments, optionally restricted to a namespace.
+type NetworkAttachmentListOptions struct {
+	Namespace string
+}
+
+func (options NetworkAttachmentListOptions) defaultPathRoot() string {
+	root := fmt.Sprintf("%s/%s/", Prefix(), attachmentDir)
+	if options.Namespace == "" {
+		return root
+	}
+	return root + options.Namespace + "/"
+}
+
+func (options NetworkAttachmentListOptions) KeyFromDefaultPath(path string) Key {
+	if !strings.HasPrefix(path, options.defaultPathRoot()) {
+		return nil
+	}
+	parts := strings.Split(strings.TrimPrefix(path, fmt.Sprintf("%s/%s/", Prefix(), attachmentDir)), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil
+	}
+	return NetworkAttachmentKey{Namespace: parts[0], Name: parts[1]}
+}
+
+// PodInterfaceKey is the key of an interface of a pod.
+type PodInterfaceKey struct {
+	Namespace string
+	Pod       string
+	Interface string
+}
+
+func (key PodInterfaceKey) defaultDeletePath() (string, error) {
+	return key.defaultPath()
+}
+
+func (key PodInterfaceKey) defaultPath() (string, error) {
+	if key.Namespace == "" {
+		return "", errors.ErrorInsufficientIdentifiers{Name: "namespace"}
+	}
+	if key.Pod == "" {
+		return "", errors.ErrorInsufficientIdentifiers{Name: "pod"}
+	}
+	if key.Interface == "" {
+		return "", errors.ErrorInsufficientIdentifiers{Name: "interface"}
+	}
+	return fmt.Sprintf("%s/%s/%s/%s/%s", Prefix(), interfaceDir, key.Namespace, key.Pod, key.Interface), nil
+}
+
+func (key PodInterfaceKey) valueType() (reflect.Type, error) {
+	return typePodInterface, nil
+}
+
+func (key PodInterfaceKey) String() string {
+	return fmt.Sprintf("PodInterface(namespace=%s, pod=%s, interface=%s)", key.Namespace, key.Pod, key.Interface)
+}
+
+// PodInterfaceListOptions lists the pod interfaces, optionally restricted to a namespace or to
+// the pod of a namespace.
+type PodInterfaceListOptions struct {
+	Namespace string
+	Pod       string
+}
+
+func (options PodInterfaceListOptions) defaultPathRoot() string {
+	root := fmt.Sprintf("%s/%s/", Prefix(), interfaceDir)
+	if options.Namespace == "" {
+		return root
+	}
+	root += options.Namespace + "/"
+	if options.Pod == "" {
+		return root
+	}
+	return root + options.Pod + "/"
+}
+
+func (options PodInterfaceListOptions) KeyFromDefaultPath(path string) Key {
+	if !strings.HasPrefix(path, options.defaultPathRoot()) {
+		return nil
+	}
+	parts := strings.Split(strings.TrimPrefix(path, fmt.Sprintf("%s/%s/", Prefix(), interfaceDir)), "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return nil
+	}
+	return PodInterfaceKey{Namespace: parts[0], Pod: parts[1], Interface: parts[2]}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	"io/ioutil"
+)
+
+// Values are written either as bare JSON, as in the first versions of stargazer, or wrapped in an
+// envelope starting with a header byte. Header bytes are taken from the control characters
+// 0x10-0x1f, which never start a JSON document, so that bare JSON values still parse.
+//
+// The high nibble of the header is envelopeMagic, the low nibble holds the envelope version and
+// the flags describing the payload following the header:
+//
+//	bit 0: the payload is gzip compressed
+//	bit 1: the payload is protobuf instead of JSON
+//	bits 2-3: envelope version - 1
+const (
+	envelopeMagic    byte = 0x10
+	envelopeMask     byte = 0xf0
+	envelopeGzip     byte = 0x01
+	envelopeProtobuf byte = 0x02
+	envelopeVersion  byte = 0x0c
+	envelopeV1       byte = 0x00
+)
+
+// Codecs of the values.
+const (
+	CodecJSON     = "json"
+	CodecProtobuf = "protobuf"
+)
+
+// Compressions of the values.
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+)
+
+// compressMinSize is the size of the smallest values worth compressing.
+const compressMinSize = 1024
+
+// valueEncoding is the encoding of the values written, see SetValueEncoding.
+var valueEncoding struct {
+	protobuf bool
+	gzip     bool
+}
+
+// SetValueEncoding sets the encoding of the written values. The protobuf codec applies to the values
+// implementing proto.Message, the others are still encoded as JSON. Compression applies to the values
+// of at least 1KiB. Values are written as bare JSON when neither protobuf nor compression is used,
+// which older versions of stargazer and other readers of the datastore expect.
+// Should be called once at startup, values of any encoding are parsed regardless of this setting.
+func SetValueEncoding(codec string, compression string) error {
+	switch codec {
+	case CodecJSON, "":
+		valueEncoding.protobuf = false
+	case CodecProtobuf:
+		valueEncoding.protobuf = true
+	default:
+		return fmt.Errorf("unsupported value codec %q", codec)
+	}
+	switch compression {
+	case CompressionNone, "":
+		valueEncoding.gzip = false
+	case CompressionGzip:
+		valueEncoding.gzip = true
+	default:
+		return fmt.Errorf("unsupported value compression %q", compression)
+	}
+	return nil
+}
+
+// IsEnveloped returns true if the serialized value is wrapped in an envelope, which may hold binary data.
+func IsEnveloped(data []byte) bool {
+	return len(data) > 0 && data[0]&envelopeMask == envelopeMagic
+}
+
+// marshal encodes a value using the configured encoding.
+func marshal(v interface{}) ([]byte, error) {
+	var header byte
+	var payload []byte
+	var err error
+	if msg, ok := v.(proto.Message); ok && valueEncoding.protobuf {
+		header |= envelopeProtobuf
+		payload, err = proto.Marshal(msg)
+	} else {
+		payload, err = json.Marshal(v)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if valueEncoding.gzip && len(payload) >= compressMinSize {
+		header |= envelopeGzip
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(payload); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		payload = buf.Bytes()
+	}
+
+	if header == 0 {
+		return payload, nil
+	}
+	return append([]byte{envelopeMagic | envelopeV1 | header}, payload...), nil
+}
+
+// unmarshal decodes data written by marshal, or bare JSON, into v.
+func unmarshal(data []byte, v interface{}) error {
+	if !IsEnveloped(data) {
+		return json.Unmarshal(data, v)
+	}
+	header, payload := data[0], data[1:]
+	if header&envelopeVersion != envelopeV1 {
+		return fmt.Errorf("unsupported value envelope version %d", (header&envelopeVersion)>>2+1)
+	}
+
+	if header&envelopeGzip != 0 {
+		r, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("failed to decompress value: %v", err)
+		}
+		defer r.Close()
+		if payload, err = ioutil.ReadAll(r); err != nil {
+			return fmt.Errorf("failed to decompress value: %v", err)
+		}
+	}
+
+	if header&envelopeProtobuf != 0 {
+		msg, ok := v.(proto.Message)
+		if !ok {
+			return fmt.Errorf("protobuf value found for %T, which is not a protobuf message", v)
+		}
+		return proto.Unmarshal(payload, msg)
+	}
+	return json.Unmarshal(payload, v)
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"github.com/nimbess/stargazer/pkg/model"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type encodingtest struct {
+	testName    string
+	compression string
+	node        *model.Node
+	enveloped   bool
+}
+
+var bigLabels = map[string]string{"description": strings.Repeat("x", 4096)}
+
+var encodingTests = []encodingtest{
+	// pass: bare JSON
+	{"encoding 1", model.CompressionNone, &model.Node{Name: "node1", Labels: bigLabels}, false},
+	// pass: small values are not compressed
+	{"encoding 2", model.CompressionGzip, &model.Node{Name: "node1"}, false},
+	// pass: large values are compressed
+	{"encoding 3", model.CompressionGzip, &model.Node{Name: "node1", Labels: bigLabels}, true},
+}
+
+func TestSerializeValue_Envelope(t *testing.T) {
+	defer model.SetValueEncoding(model.CodecJSON, model.CompressionNone)
+	for _, test := range encodingTests {
+		if err := model.SetValueEncoding(model.CodecJSON, test.compression); err != nil {
+			t.Fatal(err)
+		}
+		key := model.NodeKey{Hostname: test.node.Name}
+		data, err := model.SerializeValue(&model.KVPair{Key: key, Value: test.node})
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.testName, err)
+			continue
+		}
+		if model.IsEnveloped(data) != test.enveloped {
+			t.Errorf("%s: expected enveloped %v", test.testName, test.enveloped)
+		}
+		// Values are parsed regardless of the encoding used for writes.
+		if err := model.SetValueEncoding(model.CodecJSON, model.CompressionNone); err != nil {
+			t.Fatal(err)
+		}
+		got, err := model.ParseValue(key, data)
+		if err != nil || !reflect.DeepEqual(got, test.node) {
+			t.Errorf("%s\nExpected: %+v\nGot: %+v, %v", test.testName, test.node, got, err)
+		}
+	}
+}
+
+func TestParseValue_UnsupportedEnvelope(t *testing.T) {
+	if _, err := model.ParseValue(model.NodeKey{Hostname: "node1"}, []byte{0x1c, '{', '}'}); err == nil {
+		t.Error("Expected error for an unsupported envelope version")
+	}
+	if _, err := model.ParseValue(model.NodeKey{Hostname: "node1"}, []byte{0x12, 0x0a, 0x01}); err == nil {
+		t.Error("Expected error for a protobuf value of a type that is not a protobuf message")
+	}
+}
+
+func TestSetValueEncoding_Invalid(t *testing.T) {
+	defer model.SetValueEncoding(model.CodecJSON, model.CompressionNone)
+	if err := model.SetValueEncoding("xml", model.CompressionNone); err == nil {
+		t.Error("Expected error for an unsupported codec")
+	}
+	if err := model.SetValueEncoding(model.CodecJSON, "zstd"); err == nil {
+		t.Error("Expected error for an unsupported compression")
+	}
+}
+
+func TestParseValue_LegacyPolicy(t *testing.T) {
+	// UNPs were written as the full Kubernetes object by earlier versions.
+	legacy := `{"kind":"UnifiedNetworkPolicy","apiVersion":"nimbess.com/v1",` +
+		`"metadata":{"name":"p1","namespace":"default","uid":"1234","creationTimestamp":null},` +
+		`"spec":{"l7Policies":[{"default":{"action":"allow"},"urlFilter":{"urls":["example.com"],` +
+		`"podSelector":{"matchLabels":{"app":"web"}}}}],"podSelector":{},"network":"net1","attributes":""},` +
+		`"status":{}}`
+	got, err := model.ParseValue(model.UNPKey{Name: "default/p1"}, []byte(legacy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := got.(*model.Policy)
+	if p.Metadata.Name != "p1" || p.Metadata.Uid != "1234" || p.Spec.Network != "net1" ||
+		p.Spec.L7Policies[0].UrlFilter.Urls[0] != "example.com" ||
+		p.Spec.L7Policies[0].UrlFilter.PodSelector.MatchLabels["app"] != "web" {
+		t.Errorf("Unexpected policy: %+v", p)
+	}
+}
+
+func TestSerializeValue_Protobuf(t *testing.T) {
+	defer model.SetValueEncoding(model.CodecJSON, model.CompressionNone)
+	if err := model.SetValueEncoding(model.CodecProtobuf, model.CompressionNone); err != nil {
+		t.Fatal(err)
+	}
+	n := &model.Node{Name: "node1", Internalip: "10.0.0.1"}
+	key := model.NodeKey{Hostname: "node1"}
+	data, err := model.SerializeValue(&model.KVPair{Key: key, Value: n})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The wire format is shared with the data plane agents, field numbers must not change.
+	expected := []byte{0x12, 0x12, 0x05, 'n', 'o', 'd', 'e', '1', 0x1a, 0x08, '1', '0', '.', '0', '.', '0', '.', '1'}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("Expected: %x\nGot: %x", expected, data)
+	}
+	got, err := model.ParseValue(key, data)
+	if err != nil || got.(*model.Node).Name != "node1" || got.(*model.Node).Internalip != "10.0.0.1" {
+		t.Errorf("Unexpected node: %+v, %v", got, err)
+	}
+}
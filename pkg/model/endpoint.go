@@ -0,0 +1,91 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"github.com/nimbess/stargazer/pkg/errors"
+	"github.com/nimbess/stargazer/pkg/model/node"
+	"reflect"
+	"strings"
+)
+
+// endpointDir is the directory holding the endpoints under the Nimbess prefix
+const endpointDir = "endpoint"
+
+var (
+	typeEndpoint = reflect.TypeOf(Endpoint{})
+)
+
+// Endpoint is the value of an EndpointKey, generated from node/endpoint.proto.
+type Endpoint = node.Endpoint
+
+// EndpointAddress is an address of an Endpoint.
+type EndpointAddress = node.EndpointAddress
+
+// EndpointPort is a port of an Endpoint.
+type EndpointPort = node.EndpointPort
+
+// EndpointKey is the key of the endpoints of a service.
+type EndpointKey struct {
+	Namespace string
+	Name      string
+}
+
+func (key EndpointKey) defaultDeletePath() (string, error) {
+	return key.defaultPath()
+}
+
+func (key EndpointKey) defaultPath() (string, error) {
+	if key.Namespace == "" {
+		return "", errors.ErrorInsufficientIdentifiers{Name: "namespace"}
+	}
+	if key.Name == "" {
+		return "", errors.ErrorInsufficientIdentifiers{Name: "name"}
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", Prefix(), endpointDir, key.Namespace, key.Name), nil
+}
+
+func (key EndpointKey) valueType() (reflect.Type, error) {
+	return typeEndpoint, nil
+}
+
+func (key EndpointKey) String() string {
+	return fmt.Sprintf("Endpoint(namespace=%s, name=%s)", key.Namespace, key.Name)
+}
+
+// EndpointListOptions lists the endpoints, optionally restricted to a namespace.
+type EndpointListOptions struct {
+	Namespace string
+}
+
+func (options EndpointListOptions) defaultPathRoot() string {
+	root := fmt.Sprintf("%s/%s/", Prefix(), endpointDir)
+	if options.Namespace == "" {
+		return root
+	}
+	return root + options.Namespace + "/"
+}
+
+func (options EndpointListOptions) KeyFromDefaultPath(path string) Key {
+	if !strings.HasPrefix(path, options.defaultPathRoot()) {
+		return nil
+	}
+	parts := strings.Split(strings.TrimPrefix(path, fmt.Sprintf("%s/%s/", Prefix(), endpointDir)), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil
+	}
+	return EndpointKey{Namespace: parts[0], Name: parts[1]}
+}
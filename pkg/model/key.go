@@ -18,7 +18,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"k8s.io/apimachinery/pkg/types"
+	"path"
 	"reflect"
+	"strings"
 	"time"
 )
 
@@ -29,6 +31,42 @@ type rawBool bool
 var rawStringType = reflect.TypeOf(rawString(""))
 var rawBoolType = reflect.TypeOf(rawBool(true))
 
+// LegacyRoot is the fixed root path used by the first, unversioned, key layout.
+const LegacyRoot = "/nimbess"
+
+// root is the common prefix of all Nimbess keys
+var root = LegacyRoot
+
+// tenant is an optional path segment following the root path. It allows several
+// stargazer instances to share a single etcd.
+var tenant string
+
+// SetPrefix sets the root path and the tenant path segment used by the default paths of all keys.
+// Should be called once at startup, before any key is converted to a path.
+func SetPrefix(r string, t string) error {
+	if !strings.HasPrefix(r, "/") || path.Clean(r) != r || r == "/" {
+		return fmt.Errorf("invalid root path %q: must be an absolute path without trailing '/'", r)
+	}
+	if strings.Contains(t, "/") {
+		return fmt.Errorf("invalid tenant %q: must not contain '/'", t)
+	}
+	if t == unpDir || t == nodeDir || t == endpointDir || t == serviceDir || t == networkDir ||
+		t == attachmentDir || t == interfaceDir {
+		return fmt.Errorf("invalid tenant %q: reserved name", t)
+	}
+	root = r
+	tenant = t
+	return nil
+}
+
+// Prefix returns the root path of all keys, including the tenant if any.
+func Prefix() string {
+	if tenant == "" {
+		return root
+	}
+	return path.Join(root, tenant)
+}
+
 type Key interface {
 	defaultPath() (string, error)
 
@@ -95,7 +133,7 @@ type KVPair struct {
 // ParseValue parses the default JSON representation of our data into one of
 // our value structs, according to the type of key.  I.e. if passed a
 // PolicyKey as the first parameter, it will try to parse rawData into a
-// Policy struct.
+// Policy struct. Values wrapped in an envelope are decoded first, see SetValueEncoding.
 func ParseValue(key Key, rawData []byte) (interface{}, error) {
 	valueType, err := key.valueType()
 	if err != nil {
@@ -115,7 +153,7 @@ func ParseValue(key Key, rawData []byte) (interface{}, error) {
 		}
 	}
 	iface := value.Interface()
-	err = json.Unmarshal(rawData, iface)
+	err = unmarshal(rawData, iface)
 	if err != nil {
 		//log.Warningf("Failed to unmarshal %#v into value %#v",
 		//	string(rawData), value)
@@ -145,5 +183,5 @@ func SerializeValue(d *KVPair) ([]byte, error) {
 	if valueType == rawBoolType {
 		return []byte(fmt.Sprint(d.Value)), nil
 	}
-	return json.Marshal(d.Value)
+	return marshal(d.Value)
 }
@@ -0,0 +1,110 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"github.com/nimbess/stargazer/pkg/model"
+	"testing"
+)
+
+type pathtest struct {
+	testName string
+	root     string
+	tenant   string
+	key      model.Key
+	expected string
+}
+
+var pathTests = []pathtest{
+	// pass: default root
+	{"path 1", "/nimbess", "", model.UNPKey{Name: "default/policy"}, "/nimbess/unp/default/policy"},
+	// pass: default root with tenant
+	{"path 2", "/nimbess", "tenant1", model.NodeKey{Hostname: "node1"}, "/nimbess/tenant1/host/node1"},
+	// pass: custom root
+	{"path 3", "/prod/nimbess", "", model.UNPKey{Name: "default/policy"}, "/prod/nimbess/unp/default/policy"},
+	// pass: schema version marker
+	{"path 4", "/prod", "tenant1", model.SchemaVersionKey{}, "/prod/tenant1/schema-version"},
+	// pass: endpoints
+	{"path 5", "/nimbess", "", model.EndpointKey{Namespace: "default", Name: "web"}, "/nimbess/endpoint/default/web"},
+	// pass: services
+	{"path 6", "/nimbess", "tenant1", model.ServiceKey{Namespace: "default", Name: "web"}, "/nimbess/tenant1/service/default/web"},
+	// pass: networks
+	{"path 7", "/nimbess", "", model.NetworkKey{Name: "devNetwork"}, "/nimbess/network/devNetwork"},
+	// pass: network attachments
+	{"path 8", "/nimbess", "", model.NetworkAttachmentKey{Namespace: "default", Name: "macvlan"},
+		"/nimbess/attachment/default/macvlan"},
+	// pass: pod interfaces
+	{"path 9", "/nimbess", "", model.PodInterfaceKey{Namespace: "default", Pod: "web-0", Interface: "net1"},
+		"/nimbess/interface/default/web-0/net1"},
+}
+
+func TestKeyToDefaultPath(t *testing.T) {
+	defer model.SetPrefix(model.LegacyRoot, "")
+	for _, test := range pathTests {
+		if err := model.SetPrefix(test.root, test.tenant); err != nil {
+			t.Errorf("%s: unexpected error: %v", test.testName, err)
+			continue
+		}
+		got, err := model.KeyToDefaultPath(test.key)
+		if err != nil || got != test.expected {
+			t.Errorf("%s\nExpected: %s\nGot: %s, %v", test.testName, test.expected, got, err)
+		}
+	}
+}
+
+func TestSetPrefix_Invalid(t *testing.T) {
+	defer model.SetPrefix(model.LegacyRoot, "")
+	for _, in := range [][2]string{{"nimbess", ""}, {"/nimbess/", ""}, {"/", ""}, {"/nimbess", "a/b"}, {"/nimbess", "unp"}} {
+		if err := model.SetPrefix(in[0], in[1]); err == nil {
+			t.Errorf("Expected error for root %q and tenant %q", in[0], in[1])
+		}
+	}
+}
+
+func TestMigrationFrom_Unversioned(t *testing.T) {
+	defer model.SetPrefix(model.LegacyRoot, "")
+	if err := model.SetPrefix("/nimbess", "tenant1"); err != nil {
+		t.Fatal(err)
+	}
+	m, err := model.MigrationFrom(model.UnversionedSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Rewrite("/nimbess/unp/default/policy"); got != "/nimbess/tenant1/unp/default/policy" {
+		t.Errorf("Unexpected rewrite: %s", got)
+	}
+	if _, err := model.MigrationFrom(model.SchemaVersion); err == nil {
+		t.Error("Expected no migration from the current version")
+	}
+}
+
+func TestMigrationFrom_Policy(t *testing.T) {
+	m, err := model.MigrationFrom(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy := `{"kind":"UnifiedNetworkPolicy","apiVersion":"nimbess.com/v1",` +
+		`"metadata":{"name":"p1","namespace":"default"},"spec":{"network":"net1"}}`
+	got, err := m.Convert("/nimbess/unp/default/p1", []byte(legacy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"metadata":{"namespace":"default","name":"p1"},"spec":{"network":"net1"}}`; string(got) != expected {
+		t.Errorf("Expected: %s\nGot: %s", expected, got)
+	}
+	if _, err := m.Convert("/nimbess/unp/default/p2", []byte("{")); err == nil {
+		t.Error("Expected error for an invalid value")
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+// ListInterface describes a set of keys sharing a common path prefix, used to list or watch them.
+type ListInterface interface {
+	// defaultPathRoot returns the common path prefix of all the keys in the list.
+	defaultPathRoot() string
+
+	// KeyFromDefaultPath parses the default path representation of a key into a Key
+	// of this list. Returns nil if the path does not belong to the list.
+	KeyFromDefaultPath(path string) Key
+}
+
+// ListOptionsToDefaultPathRoot returns the common path prefix of the keys matched by the list,
+// suitable for a prefix query on a hierarchical key/value datastore such as etcd v3.
+func ListOptionsToDefaultPathRoot(listOptions ListInterface) string {
+	return listOptions.defaultPathRoot()
+}
+
+// KVPairList holds a list of KVPairs and the datastore revision at which they were listed.
+type KVPairList struct {
+	KVPairs  []*KVPair
+	Revision string
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"github.com/nimbess/stargazer/pkg/crd/api/unp/v1"
+	"github.com/nimbess/stargazer/pkg/errors"
+	"github.com/nimbess/stargazer/pkg/model/node"
+	"reflect"
+	"strings"
+)
+
+// networkDir is the directory holding the networks under the Nimbess prefix
+const networkDir = "network"
+
+var (
+	typeNetwork = reflect.TypeOf(Network{})
+)
+
+// Network is the value of a NetworkKey, generated from node/network.proto.
+type Network = node.Network
+
+// NewNetwork converts a Network to the Network stored in the datastore.
+func NewNetwork(n *v1.Network) *Network {
+	return &Network{
+		Name:         n.Name,
+		Cidrs:        n.Spec.CIDRs,
+		Vlan:         int32(n.Spec.VLAN),
+		Vni:          int32(n.Spec.VNI),
+		DataPlane:    n.Spec.DataPlane,
+		NodeSelector: newLabelSelector(n.Spec.NodeSelector),
+	}
+}
+
+// NetworkKey is the key of a cluster scoped network.
+type NetworkKey struct {
+	Name string
+}
+
+func (key NetworkKey) defaultDeletePath() (string, error) {
+	return key.defaultPath()
+}
+
+func (key NetworkKey) defaultPath() (string, error) {
+	if key.Name == "" {
+		return "", errors.ErrorInsufficientIdentifiers{Name: "name"}
+	}
+	return fmt.Sprintf("%s/%s/%s", Prefix(), networkDir, key.Name), nil
+}
+
+func (key NetworkKey) valueType() (reflect.Type, error) {
+	return typeNetwork, nil
+}
+
+func (key NetworkKey) String() string {
+	return fmt.Sprintf("Network(name=%s)", key.Name)
+}
+
+// NetworkListOptions lists the networks.
+type NetworkListOptions struct{}
+
+func (options NetworkListOptions) defaultPathRoot() string {
+	return fmt.Sprintf("%s/%s/", Prefix(), networkDir)
+}
+
+func (options NetworkListOptions) KeyFromDefaultPath(path string) Key {
+	name := strings.TrimPrefix(path, options.defaultPathRoot())
+	if name == path || name == "" || strings.Contains(name, "/") {
+		return nil
+	}
+	return NetworkKey{Name: name}
+}
@@ -17,23 +17,20 @@ package model
 import (
 	"fmt"
 	"github.com/nimbess/stargazer/pkg/errors"
-	"k8s.io/apimachinery/pkg/types"
+	"github.com/nimbess/stargazer/pkg/model/node"
 	"reflect"
+	"strings"
 )
 
+// nodeDir is the directory holding the nodes under the Nimbess prefix
+const nodeDir = "host"
+
 var (
 	typeNode = reflect.TypeOf(Node{})
 )
 
-type Node struct {
-	Namespace  string            `json:"namespace,omitempty"`
-	Name       string            `json:"name,omitempty"`
-	InternalIP string            `json:"internalip,omitempty"`
-	Hostname   string            `json:"hostname,omitempty"`
-	PodCIDR    string            `json:"podCIDR,omitempty"`
-	Labels     map[string]string `json:"labels,omitempty"`
-	UID        types.UID         `json:"uid,omitempty"`
-}
+// Node is the value of a NodeKey, generated from node/node.proto.
+type Node = node.Node
 
 type NodeKey struct {
 	Hostname string
@@ -47,7 +44,7 @@ func (key NodeKey) defaultPath() (string, error) {
 	if key.Hostname == "" {
 		return "", errors.ErrorInsufficientIdentifiers{Name: "name"}
 	}
-	return fmt.Sprintf("/nimbess/host/%s", key.Hostname), nil
+	return fmt.Sprintf("%s/%s/%s", Prefix(), nodeDir, key.Hostname), nil
 }
 
 func (key NodeKey) valueType() (reflect.Type, error) {
@@ -61,3 +58,18 @@ func (key NodeKey) String() string {
 func (key NodeKey) KeyToDefaultDeletePath() (string, error) {
 	return key.defaultPath()
 }
+
+// NodeListOptions lists the nodes.
+type NodeListOptions struct{}
+
+func (options NodeListOptions) defaultPathRoot() string {
+	return fmt.Sprintf("%s/%s/", Prefix(), nodeDir)
+}
+
+func (options NodeListOptions) KeyFromDefaultPath(path string) Key {
+	hostname := strings.TrimPrefix(path, options.defaultPathRoot())
+	if hostname == path || hostname == "" || strings.Contains(hostname, "/") {
+		return nil
+	}
+	return NodeKey{Hostname: hostname}
+}
@@ -0,0 +1,136 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: pkg/model/node/attachment.proto
+
+package node
+
+import (
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// PodInterface is the value of the keys under <prefix>/interface/, converted from the Multus
+// network-status annotation of a pod. Each interface of the pod has its own key.
+type PodInterface struct {
+	Namespace string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Pod       string `protobuf:"bytes,2,opt,name=pod,proto3" json:"pod,omitempty"`
+	// network is the <namespace>/<name> of the NetworkAttachmentDefinition, or the name of the
+	// cluster network for the default interface.
+	Network              string   `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	Interface            string   `protobuf:"bytes,4,opt,name=interface,proto3" json:"interface,omitempty"`
+	Ips                  []string `protobuf:"bytes,5,rep,name=ips,proto3" json:"ips,omitempty"`
+	Mac                  string   `protobuf:"bytes,6,opt,name=mac,proto3" json:"mac,omitempty"`
+	Default              bool     `protobuf:"varint,7,opt,name=default,proto3" json:"default,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PodInterface) Reset()         { *m = PodInterface{} }
+func (m *PodInterface) String() string { return proto.CompactTextString(m) }
+func (*PodInterface) ProtoMessage()    {}
+func (*PodInterface) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bd9c2e6fa0d5cd46, []int{0}
+}
+
+func (m *PodInterface) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PodInterface.Unmarshal(m, b)
+}
+func (m *PodInterface) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PodInterface.Marshal(b, m, deterministic)
+}
+func (m *PodInterface) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PodInterface.Merge(m, src)
+}
+func (m *PodInterface) XXX_Size() int {
+	return xxx_messageInfo_PodInterface.Size(m)
+}
+func (m *PodInterface) XXX_DiscardUnknown() {
+	xxx_messageInfo_PodInterface.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PodInterface proto.InternalMessageInfo
+
+func (m *PodInterface) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *PodInterface) GetPod() string {
+	if m != nil {
+		return m.Pod
+	}
+	return ""
+}
+
+func (m *PodInterface) GetNetwork() string {
+	if m != nil {
+		return m.Network
+	}
+	return ""
+}
+
+func (m *PodInterface) GetInterface() string {
+	if m != nil {
+		return m.Interface
+	}
+	return ""
+}
+
+func (m *PodInterface) GetIps() []string {
+	if m != nil {
+		return m.Ips
+	}
+	return nil
+}
+
+func (m *PodInterface) GetMac() string {
+	if m != nil {
+		return m.Mac
+	}
+	return ""
+}
+
+func (m *PodInterface) GetDefault() bool {
+	if m != nil {
+		return m.Default
+	}
+	return false
+}
+
+func init() {
+	proto.RegisterType((*PodInterface)(nil), "nimbess.model.PodInterface")
+}
+
+func init() { proto.RegisterFile("pkg/model/node/attachment.proto", fileDescriptor_bd9c2e6fa0d5cd46) }
+
+var fileDescriptor_bd9c2e6fa0d5cd46 = []byte{
+	// 220 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x90, 0xb1, 0x4a, 0x04, 0x31,
+	0x10, 0x86, 0x59, 0x57, 0xef, 0xbc, 0xa0, 0x20, 0x5b, 0x4d, 0x21, 0xb8, 0x58, 0x5d, 0xb5, 0x11,
+	0x2d, 0xed, 0xec, 0xec, 0xe4, 0x4a, 0xbb, 0xd9, 0x64, 0x6e, 0x2f, 0xdc, 0x25, 0x13, 0x92, 0x39,
+	0x04, 0x9f, 0xcb, 0x07, 0x94, 0xe4, 0x5c, 0xc5, 0x66, 0x98, 0xff, 0x63, 0xf8, 0x60, 0x7e, 0x75,
+	0x17, 0xf7, 0x93, 0xf6, 0x6c, 0xe9, 0xa0, 0x03, 0x5b, 0xd2, 0x28, 0x82, 0x66, 0xe7, 0x29, 0xc8,
+	0x10, 0x13, 0x0b, 0x77, 0xd7, 0xc1, 0xf9, 0x91, 0x72, 0x1e, 0xea, 0xd1, 0xfd, 0x57, 0xa3, 0xae,
+	0xde, 0xd8, 0xbe, 0x06, 0xa1, 0xb4, 0x45, 0x43, 0xdd, 0xad, 0x5a, 0x05, 0xf4, 0x94, 0x23, 0x1a,
+	0x82, 0xa6, 0x6f, 0xd6, 0xab, 0xcd, 0x1f, 0xe8, 0x6e, 0x54, 0x1b, 0xd9, 0xc2, 0x59, 0xe5, 0x65,
+	0xed, 0x40, 0x2d, 0x03, 0xc9, 0x07, 0xa7, 0x3d, 0xb4, 0x95, 0xce, 0xb1, 0x98, 0xdc, 0xac, 0x85,
+	0xf3, 0x93, 0xe9, 0x17, 0x14, 0x93, 0x8b, 0x19, 0x2e, 0xfa, 0xb6, 0x98, 0x5c, 0xcc, 0x85, 0x78,
+	0x34, 0xb0, 0x38, 0xb9, 0x3d, 0x9a, 0xe2, 0xb6, 0xb4, 0xc5, 0xe3, 0x41, 0x60, 0xd9, 0x37, 0xeb,
+	0xcb, 0xcd, 0x1c, 0x5f, 0x1e, 0xdf, 0x1f, 0x26, 0x27, 0xbb, 0xe3, 0x38, 0x18, 0xf6, 0xfa, 0xe7,
+	0x25, 0x9d, 0x05, 0xd3, 0x84, 0x9f, 0x94, 0xf4, 0xff, 0x16, 0x9e, 0xcb, 0x18, 0x17, 0xb5, 0x80,
+	0xa7, 0xef, 0x01, 0x00, 0x7d, 0x63, 0xab, 0x93, 0x23, 0x01, 0x00, 0x00,
+}
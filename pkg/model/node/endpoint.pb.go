@@ -0,0 +1,233 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: pkg/model/node/endpoint.proto
+
+package node
+
+import (
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// Endpoint holds the addresses and ports backing a service.
+type Endpoint struct {
+	Namespace            string             `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name                 string             `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Addresses            []*EndpointAddress `protobuf:"bytes,3,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	Ports                []*EndpointPort    `protobuf:"bytes,4,rep,name=ports,proto3" json:"ports,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *Endpoint) Reset()         { *m = Endpoint{} }
+func (m *Endpoint) String() string { return proto.CompactTextString(m) }
+func (*Endpoint) ProtoMessage()    {}
+func (*Endpoint) Descriptor() ([]byte, []int) {
+	return fileDescriptor_161ccc805ef66a94, []int{0}
+}
+
+func (m *Endpoint) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Endpoint.Unmarshal(m, b)
+}
+func (m *Endpoint) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Endpoint.Marshal(b, m, deterministic)
+}
+func (m *Endpoint) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Endpoint.Merge(m, src)
+}
+func (m *Endpoint) XXX_Size() int {
+	return xxx_messageInfo_Endpoint.Size(m)
+}
+func (m *Endpoint) XXX_DiscardUnknown() {
+	xxx_messageInfo_Endpoint.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Endpoint proto.InternalMessageInfo
+
+func (m *Endpoint) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *Endpoint) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Endpoint) GetAddresses() []*EndpointAddress {
+	if m != nil {
+		return m.Addresses
+	}
+	return nil
+}
+
+func (m *Endpoint) GetPorts() []*EndpointPort {
+	if m != nil {
+		return m.Ports
+	}
+	return nil
+}
+
+type EndpointAddress struct {
+	Ip                   string   `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	NodeName             string   `protobuf:"bytes,2,opt,name=nodeName,proto3" json:"nodeName,omitempty"`
+	PodName              string   `protobuf:"bytes,3,opt,name=podName,proto3" json:"podName,omitempty"`
+	Ready                bool     `protobuf:"varint,4,opt,name=ready,proto3" json:"ready,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EndpointAddress) Reset()         { *m = EndpointAddress{} }
+func (m *EndpointAddress) String() string { return proto.CompactTextString(m) }
+func (*EndpointAddress) ProtoMessage()    {}
+func (*EndpointAddress) Descriptor() ([]byte, []int) {
+	return fileDescriptor_161ccc805ef66a94, []int{1}
+}
+
+func (m *EndpointAddress) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EndpointAddress.Unmarshal(m, b)
+}
+func (m *EndpointAddress) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EndpointAddress.Marshal(b, m, deterministic)
+}
+func (m *EndpointAddress) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EndpointAddress.Merge(m, src)
+}
+func (m *EndpointAddress) XXX_Size() int {
+	return xxx_messageInfo_EndpointAddress.Size(m)
+}
+func (m *EndpointAddress) XXX_DiscardUnknown() {
+	xxx_messageInfo_EndpointAddress.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EndpointAddress proto.InternalMessageInfo
+
+func (m *EndpointAddress) GetIp() string {
+	if m != nil {
+		return m.Ip
+	}
+	return ""
+}
+
+func (m *EndpointAddress) GetNodeName() string {
+	if m != nil {
+		return m.NodeName
+	}
+	return ""
+}
+
+func (m *EndpointAddress) GetPodName() string {
+	if m != nil {
+		return m.PodName
+	}
+	return ""
+}
+
+func (m *EndpointAddress) GetReady() bool {
+	if m != nil {
+		return m.Ready
+	}
+	return false
+}
+
+type EndpointPort struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Port                 int32    `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+	Protocol             string   `protobuf:"bytes,3,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EndpointPort) Reset()         { *m = EndpointPort{} }
+func (m *EndpointPort) String() string { return proto.CompactTextString(m) }
+func (*EndpointPort) ProtoMessage()    {}
+func (*EndpointPort) Descriptor() ([]byte, []int) {
+	return fileDescriptor_161ccc805ef66a94, []int{2}
+}
+
+func (m *EndpointPort) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EndpointPort.Unmarshal(m, b)
+}
+func (m *EndpointPort) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EndpointPort.Marshal(b, m, deterministic)
+}
+func (m *EndpointPort) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EndpointPort.Merge(m, src)
+}
+func (m *EndpointPort) XXX_Size() int {
+	return xxx_messageInfo_EndpointPort.Size(m)
+}
+func (m *EndpointPort) XXX_DiscardUnknown() {
+	xxx_messageInfo_EndpointPort.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EndpointPort proto.InternalMessageInfo
+
+func (m *EndpointPort) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *EndpointPort) GetPort() int32 {
+	if m != nil {
+		return m.Port
+	}
+	return 0
+}
+
+func (m *EndpointPort) GetProtocol() string {
+	if m != nil {
+		return m.Protocol
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*Endpoint)(nil), "nimbess.model.Endpoint")
+	proto.RegisterType((*EndpointAddress)(nil), "nimbess.model.EndpointAddress")
+	proto.RegisterType((*EndpointPort)(nil), "nimbess.model.EndpointPort")
+}
+
+func init() { proto.RegisterFile("pkg/model/node/endpoint.proto", fileDescriptor_161ccc805ef66a94) }
+
+var fileDescriptor_161ccc805ef66a94 = []byte{
+	// 283 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x51, 0x4f, 0x4f, 0xbc, 0x30,
+	0x10, 0x0d, 0x0b, 0xfc, 0x7e, 0x30, 0xfe, 0x4b, 0x26, 0x1e, 0x1a, 0xff, 0x85, 0x70, 0xe2, 0x44,
+	0x75, 0x3d, 0xea, 0x45, 0x13, 0xaf, 0xc6, 0xf4, 0xe8, 0xad, 0xd0, 0x06, 0x89, 0x0b, 0x6d, 0xda,
+	0x7a, 0xd0, 0xef, 0xe4, 0x77, 0x34, 0x14, 0x16, 0x76, 0x4d, 0xbc, 0x90, 0x79, 0xcc, 0x7b, 0x33,
+	0x6f, 0x5e, 0xe1, 0x52, 0xbf, 0x37, 0xb4, 0x53, 0x42, 0x6e, 0x68, 0xaf, 0x84, 0xa4, 0xb2, 0x17,
+	0x5a, 0xb5, 0xbd, 0x2b, 0xb5, 0x51, 0x4e, 0xe1, 0x51, 0xdf, 0x76, 0x95, 0xb4, 0xb6, 0xf4, 0x94,
+	0xfc, 0x3b, 0x80, 0xe4, 0x69, 0x62, 0xe0, 0x05, 0xa4, 0x3d, 0xef, 0xa4, 0xd5, 0xbc, 0x96, 0x24,
+	0xc8, 0x82, 0x22, 0x65, 0xcb, 0x0f, 0x44, 0x88, 0x06, 0x40, 0x56, 0xbe, 0xe1, 0x6b, 0xbc, 0x87,
+	0x94, 0x0b, 0x61, 0xa4, 0xb5, 0xd2, 0x92, 0x30, 0x0b, 0x8b, 0x83, 0xf5, 0x55, 0xb9, 0xb7, 0xa1,
+	0xdc, 0x4e, 0x7f, 0x18, 0x79, 0x6c, 0x11, 0xe0, 0x0d, 0xc4, 0x5a, 0x19, 0x67, 0x49, 0xe4, 0x95,
+	0xe7, 0x7f, 0x28, 0x5f, 0x94, 0x71, 0x6c, 0x64, 0xe6, 0x1d, 0x9c, 0xfc, 0x1a, 0x88, 0xc7, 0xb0,
+	0x6a, 0xf5, 0x64, 0x77, 0xd5, 0x6a, 0x3c, 0x83, 0x64, 0x38, 0xfc, 0x79, 0xf1, 0x3a, 0x63, 0x24,
+	0xf0, 0x5f, 0x2b, 0xe1, 0x5b, 0xa1, 0x6f, 0x6d, 0x21, 0x9e, 0x42, 0x6c, 0x24, 0x17, 0x9f, 0x24,
+	0xca, 0x82, 0x22, 0x61, 0x23, 0xc8, 0x19, 0x1c, 0xee, 0xba, 0x98, 0x33, 0x08, 0x76, 0x32, 0x40,
+	0x88, 0x06, 0x6f, 0x7e, 0x57, 0xcc, 0x7c, 0x3d, 0x78, 0xf0, 0x71, 0xd7, 0x6a, 0x33, 0x2d, 0x9a,
+	0xf1, 0xe3, 0xfa, 0xf5, 0xba, 0x69, 0xdd, 0xdb, 0x47, 0x55, 0xd6, 0xaa, 0xa3, 0xd3, 0xc9, 0xd4,
+	0x3a, 0x6e, 0x1a, 0xfe, 0x25, 0x0d, 0xdd, 0x7f, 0xbf, 0xbb, 0xe1, 0x53, 0xfd, 0xf3, 0xea, 0xdb,
+	0x9f, 0x01, 0x00, 0x6d, 0x50, 0xdb, 0x81, 0xdd, 0x01, 0x00, 0x00,
+}
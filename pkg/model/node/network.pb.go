@@ -0,0 +1,129 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: pkg/model/node/network.proto
+
+package node
+
+import (
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// Network is the value of the keys under <prefix>/network/, converted from a Network. The
+// policies refer to networks by name.
+type Network struct {
+	Name  string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Cidrs []string `protobuf:"bytes,2,rep,name=cidrs,proto3" json:"cidrs,omitempty"`
+	// vlan and vni are 0 when not set, a network with neither is flat.
+	Vlan      int32  `protobuf:"varint,3,opt,name=vlan,proto3" json:"vlan,omitempty"`
+	Vni       int32  `protobuf:"varint,4,opt,name=vni,proto3" json:"vni,omitempty"`
+	DataPlane string `protobuf:"bytes,5,opt,name=dataPlane,proto3" json:"dataPlane,omitempty"`
+	// nodeSelector selects the nodes the network is attached to, all nodes if empty.
+	NodeSelector         *LabelSelector `protobuf:"bytes,6,opt,name=nodeSelector,proto3" json:"nodeSelector,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *Network) Reset()         { *m = Network{} }
+func (m *Network) String() string { return proto.CompactTextString(m) }
+func (*Network) ProtoMessage()    {}
+func (*Network) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6004ff05dd7624ae, []int{0}
+}
+
+func (m *Network) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Network.Unmarshal(m, b)
+}
+func (m *Network) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Network.Marshal(b, m, deterministic)
+}
+func (m *Network) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Network.Merge(m, src)
+}
+func (m *Network) XXX_Size() int {
+	return xxx_messageInfo_Network.Size(m)
+}
+func (m *Network) XXX_DiscardUnknown() {
+	xxx_messageInfo_Network.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Network proto.InternalMessageInfo
+
+func (m *Network) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Network) GetCidrs() []string {
+	if m != nil {
+		return m.Cidrs
+	}
+	return nil
+}
+
+func (m *Network) GetVlan() int32 {
+	if m != nil {
+		return m.Vlan
+	}
+	return 0
+}
+
+func (m *Network) GetVni() int32 {
+	if m != nil {
+		return m.Vni
+	}
+	return 0
+}
+
+func (m *Network) GetDataPlane() string {
+	if m != nil {
+		return m.DataPlane
+	}
+	return ""
+}
+
+func (m *Network) GetNodeSelector() *LabelSelector {
+	if m != nil {
+		return m.NodeSelector
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Network)(nil), "nimbess.model.Network")
+}
+
+func init() { proto.RegisterFile("pkg/model/node/network.proto", fileDescriptor_6004ff05dd7624ae) }
+
+var fileDescriptor_6004ff05dd7624ae = []byte{
+	// 233 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x5c, 0x8f, 0x31, 0x4b, 0xc5, 0x30,
+	0x14, 0x85, 0x89, 0x7d, 0x7d, 0xd2, 0xa8, 0x20, 0xc1, 0x21, 0x68, 0x87, 0xe2, 0xd4, 0x29, 0x91,
+	0xe7, 0xe8, 0x22, 0xce, 0x22, 0x52, 0x37, 0xb7, 0xdb, 0xf6, 0x52, 0xc3, 0x4b, 0x73, 0x4b, 0x1a,
+	0x2b, 0xfa, 0xcf, 0xfc, 0x77, 0xd2, 0x54, 0x91, 0xbe, 0x25, 0x9c, 0x1c, 0xbe, 0x73, 0x39, 0x87,
+	0xe7, 0xc3, 0xbe, 0xd3, 0x3d, 0xb5, 0x68, 0xb5, 0xa3, 0x16, 0xb5, 0xc3, 0xf0, 0x41, 0x7e, 0xaf,
+	0x06, 0x4f, 0x81, 0xc4, 0x99, 0x33, 0x7d, 0x8d, 0xe3, 0xa8, 0x22, 0x71, 0x79, 0x75, 0x00, 0x0f,
+	0x64, 0x4d, 0xf3, 0xb9, 0xb0, 0xd7, 0xdf, 0x8c, 0x1f, 0x3f, 0x2d, 0x69, 0x21, 0xf8, 0xc6, 0x41,
+	0x8f, 0x92, 0x15, 0xac, 0xcc, 0xaa, 0xa8, 0xc5, 0x05, 0x4f, 0x1b, 0xd3, 0xfa, 0x51, 0x1e, 0x15,
+	0x49, 0x99, 0x55, 0xcb, 0x67, 0x26, 0x27, 0x0b, 0x4e, 0x26, 0x05, 0x2b, 0xd3, 0x2a, 0x6a, 0x71,
+	0xce, 0x93, 0xc9, 0x19, 0xb9, 0x89, 0xd6, 0x2c, 0x45, 0xce, 0xb3, 0x16, 0x02, 0x3c, 0x5b, 0x70,
+	0x28, 0xd3, 0x78, 0xf4, 0xdf, 0x10, 0xf7, 0xfc, 0x74, 0xae, 0xf3, 0x82, 0x16, 0x9b, 0x40, 0x5e,
+	0x6e, 0x0b, 0x56, 0x9e, 0xec, 0x72, 0xb5, 0x2a, 0xaf, 0x1e, 0xa1, 0x46, 0xfb, 0xc7, 0x54, 0xab,
+	0xc4, 0xc3, 0xee, 0xf5, 0xa6, 0x33, 0xe1, 0xed, 0xbd, 0x56, 0x0d, 0xf5, 0xfa, 0x37, 0xa7, 0xc7,
+	0x00, 0xbe, 0x83, 0x2f, 0xf4, 0x7a, 0xbd, 0xfb, 0x6e, 0x7e, 0xea, 0x6d, 0x9c, 0x7d, 0xfb, 0x33,
+	0x00, 0xd2, 0xdf, 0x96, 0xa4, 0x42, 0x01, 0x00, 0x00,
+}
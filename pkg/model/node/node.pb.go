@@ -0,0 +1,137 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: pkg/model/node/node.proto
+
+package node
+
+import (
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// Node is the value of the keys under <prefix>/host/.
+type Node struct {
+	Namespace            string            `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name                 string            `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Internalip           string            `protobuf:"bytes,3,opt,name=internalip,proto3" json:"internalip,omitempty"`
+	Hostname             string            `protobuf:"bytes,4,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	PodCIDR              string            `protobuf:"bytes,5,opt,name=podCIDR,proto3" json:"podCIDR,omitempty"`
+	Labels               map[string]string `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Uid                  string            `protobuf:"bytes,7,opt,name=uid,proto3" json:"uid,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *Node) Reset()         { *m = Node{} }
+func (m *Node) String() string { return proto.CompactTextString(m) }
+func (*Node) ProtoMessage()    {}
+func (*Node) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6acf6d2e4b297f2b, []int{0}
+}
+
+func (m *Node) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Node.Unmarshal(m, b)
+}
+func (m *Node) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Node.Marshal(b, m, deterministic)
+}
+func (m *Node) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Node.Merge(m, src)
+}
+func (m *Node) XXX_Size() int {
+	return xxx_messageInfo_Node.Size(m)
+}
+func (m *Node) XXX_DiscardUnknown() {
+	xxx_messageInfo_Node.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Node proto.InternalMessageInfo
+
+func (m *Node) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *Node) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Node) GetInternalip() string {
+	if m != nil {
+		return m.Internalip
+	}
+	return ""
+}
+
+func (m *Node) GetHostname() string {
+	if m != nil {
+		return m.Hostname
+	}
+	return ""
+}
+
+func (m *Node) GetPodCIDR() string {
+	if m != nil {
+		return m.PodCIDR
+	}
+	return ""
+}
+
+func (m *Node) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *Node) GetUid() string {
+	if m != nil {
+		return m.Uid
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*Node)(nil), "nimbess.model.Node")
+	proto.RegisterMapType((map[string]string)(nil), "nimbess.model.Node.LabelsEntry")
+}
+
+func init() { proto.RegisterFile("pkg/model/node/node.proto", fileDescriptor_6acf6d2e4b297f2b) }
+
+var fileDescriptor_6acf6d2e4b297f2b = []byte{
+	// 265 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x90, 0x4d, 0x4b, 0xfb, 0x40,
+	0x10, 0xc6, 0xc9, 0x4b, 0xd3, 0x7f, 0xa7, 0xfc, 0x41, 0x06, 0x0f, 0x6b, 0x11, 0x2d, 0x9e, 0x7a,
+	0xda, 0x48, 0x3d, 0xf8, 0x76, 0xf3, 0xe5, 0x20, 0x88, 0x87, 0x1c, 0xbd, 0x6d, 0x9a, 0x21, 0x0d,
+	0x4d, 0x76, 0x43, 0x76, 0x23, 0xd4, 0xcf, 0xe1, 0x07, 0x96, 0x1d, 0x53, 0x6d, 0x2f, 0xc3, 0x3c,
+	0xf3, 0x7b, 0x76, 0x79, 0x66, 0xe0, 0xa4, 0xdd, 0x94, 0x69, 0x63, 0x0a, 0xaa, 0x53, 0x6d, 0x0a,
+	0xe2, 0x22, 0xdb, 0xce, 0x38, 0x83, 0xff, 0x75, 0xd5, 0xe4, 0x64, 0xad, 0x64, 0x7c, 0xf1, 0x15,
+	0x42, 0xfc, 0x66, 0x0a, 0xc2, 0x53, 0x98, 0x68, 0xd5, 0x90, 0x6d, 0xd5, 0x8a, 0x44, 0x30, 0x0f,
+	0x16, 0x93, 0xec, 0x6f, 0x80, 0x08, 0xb1, 0x17, 0x22, 0x64, 0xc0, 0x3d, 0x9e, 0x01, 0x54, 0xda,
+	0x51, 0xa7, 0x55, 0x5d, 0xb5, 0x22, 0x62, 0xb2, 0x37, 0xc1, 0x19, 0xfc, 0x5b, 0x1b, 0xeb, 0xf8,
+	0x5d, 0xcc, 0xf4, 0x57, 0xa3, 0x80, 0x71, 0x6b, 0x8a, 0xc7, 0x97, 0xa7, 0x4c, 0x8c, 0x18, 0xed,
+	0x24, 0x5e, 0x43, 0x52, 0xab, 0x9c, 0x6a, 0x2b, 0x92, 0x79, 0xb4, 0x98, 0x2e, 0xcf, 0xe5, 0x41,
+	0x60, 0xe9, 0xc3, 0xca, 0x57, 0x76, 0x3c, 0x6b, 0xd7, 0x6d, 0xb3, 0xc1, 0x8e, 0x47, 0x10, 0xf5,
+	0x55, 0x21, 0xc6, 0xfc, 0x9d, 0x6f, 0x67, 0xb7, 0x30, 0xdd, 0x33, 0x7a, 0xc3, 0x86, 0xb6, 0xc3,
+	0x6e, 0xbe, 0xc5, 0x63, 0x18, 0x7d, 0xa8, 0xba, 0xdf, 0xad, 0xf5, 0x23, 0xee, 0xc2, 0x9b, 0xe0,
+	0x61, 0xf9, 0x7e, 0x59, 0x56, 0x6e, 0xdd, 0xe7, 0x72, 0x65, 0x9a, 0x74, 0x48, 0x90, 0x5a, 0xa7,
+	0xba, 0x52, 0x7d, 0x52, 0x97, 0x1e, 0xde, 0xf7, 0xde, 0x97, 0x3c, 0xe1, 0x03, 0x5f, 0x7d, 0x0f,
+	0x00, 0x2b, 0x0b, 0x54, 0x1b, 0x7d, 0x01, 0x00, 0x00,
+}
@@ -0,0 +1,607 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: pkg/model/node/policy.proto
+
+package node
+
+import (
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// Policy is the value of the keys under <prefix>/unp/, converted from a UnifiedNetworkPolicy.
+// The layout follows the Kubernetes object, so that the values written as the full object by
+// earlier versions of stargazer still decode.
+type Policy struct {
+	Metadata             *ObjectMeta `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Spec                 *PolicySpec `protobuf:"bytes,2,opt,name=spec,proto3" json:"spec,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *Policy) Reset()         { *m = Policy{} }
+func (m *Policy) String() string { return proto.CompactTextString(m) }
+func (*Policy) ProtoMessage()    {}
+func (*Policy) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e86ff1316b3100d3, []int{0}
+}
+
+func (m *Policy) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Policy.Unmarshal(m, b)
+}
+func (m *Policy) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Policy.Marshal(b, m, deterministic)
+}
+func (m *Policy) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Policy.Merge(m, src)
+}
+func (m *Policy) XXX_Size() int {
+	return xxx_messageInfo_Policy.Size(m)
+}
+func (m *Policy) XXX_DiscardUnknown() {
+	xxx_messageInfo_Policy.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Policy proto.InternalMessageInfo
+
+func (m *Policy) GetMetadata() *ObjectMeta {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *Policy) GetSpec() *PolicySpec {
+	if m != nil {
+		return m.Spec
+	}
+	return nil
+}
+
+// ObjectMeta holds the identity of the Kubernetes object a value was converted from.
+type ObjectMeta struct {
+	Namespace string            `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name      string            `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Uid       string            `protobuf:"bytes,3,opt,name=uid,proto3" json:"uid,omitempty"`
+	Labels    map[string]string `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// origin is the kind of object the value was derived from when it isn't a
+	// UnifiedNetworkPolicy, e.g. "ingress".
+	Origin               string   `protobuf:"bytes,5,opt,name=origin,proto3" json:"origin,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ObjectMeta) Reset()         { *m = ObjectMeta{} }
+func (m *ObjectMeta) String() string { return proto.CompactTextString(m) }
+func (*ObjectMeta) ProtoMessage()    {}
+func (*ObjectMeta) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e86ff1316b3100d3, []int{1}
+}
+
+func (m *ObjectMeta) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ObjectMeta.Unmarshal(m, b)
+}
+func (m *ObjectMeta) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ObjectMeta.Marshal(b, m, deterministic)
+}
+func (m *ObjectMeta) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ObjectMeta.Merge(m, src)
+}
+func (m *ObjectMeta) XXX_Size() int {
+	return xxx_messageInfo_ObjectMeta.Size(m)
+}
+func (m *ObjectMeta) XXX_DiscardUnknown() {
+	xxx_messageInfo_ObjectMeta.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ObjectMeta proto.InternalMessageInfo
+
+func (m *ObjectMeta) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *ObjectMeta) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ObjectMeta) GetUid() string {
+	if m != nil {
+		return m.Uid
+	}
+	return ""
+}
+
+func (m *ObjectMeta) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *ObjectMeta) GetOrigin() string {
+	if m != nil {
+		return m.Origin
+	}
+	return ""
+}
+
+type PolicySpec struct {
+	L7Policies       []*L7Policy       `protobuf:"bytes,1,rep,name=l7Policies,proto3" json:"l7Policies,omitempty"`
+	PodSelector      *LabelSelector    `protobuf:"bytes,2,opt,name=podSelector,proto3" json:"podSelector,omitempty"`
+	Network          string            `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	PolicyAttributes *PolicyAttributes `protobuf:"bytes,5,opt,name=policyAttributes,proto3" json:"policyAttributes,omitempty"`
+	// enforcementMode is "enforce", "audit" to only log the traffic the policy would deny, or
+	// "disabled".
+	EnforcementMode      string   `protobuf:"bytes,6,opt,name=enforcementMode,proto3" json:"enforcementMode,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PolicySpec) Reset()         { *m = PolicySpec{} }
+func (m *PolicySpec) String() string { return proto.CompactTextString(m) }
+func (*PolicySpec) ProtoMessage()    {}
+func (*PolicySpec) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e86ff1316b3100d3, []int{2}
+}
+
+func (m *PolicySpec) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PolicySpec.Unmarshal(m, b)
+}
+func (m *PolicySpec) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PolicySpec.Marshal(b, m, deterministic)
+}
+func (m *PolicySpec) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PolicySpec.Merge(m, src)
+}
+func (m *PolicySpec) XXX_Size() int {
+	return xxx_messageInfo_PolicySpec.Size(m)
+}
+func (m *PolicySpec) XXX_DiscardUnknown() {
+	xxx_messageInfo_PolicySpec.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PolicySpec proto.InternalMessageInfo
+
+func (m *PolicySpec) GetL7Policies() []*L7Policy {
+	if m != nil {
+		return m.L7Policies
+	}
+	return nil
+}
+
+func (m *PolicySpec) GetPodSelector() *LabelSelector {
+	if m != nil {
+		return m.PodSelector
+	}
+	return nil
+}
+
+func (m *PolicySpec) GetNetwork() string {
+	if m != nil {
+		return m.Network
+	}
+	return ""
+}
+
+func (m *PolicySpec) GetPolicyAttributes() *PolicyAttributes {
+	if m != nil {
+		return m.PolicyAttributes
+	}
+	return nil
+}
+
+func (m *PolicySpec) GetEnforcementMode() string {
+	if m != nil {
+		return m.EnforcementMode
+	}
+	return ""
+}
+
+// PolicyAttributes extend the behavior of a policy, unset attributes keep the defaults of the agents.
+type PolicyAttributes struct {
+	QosClass string `protobuf:"bytes,1,opt,name=qosClass,proto3" json:"qosClass,omitempty"`
+	LogLevel string `protobuf:"bytes,2,opt,name=logLevel,proto3" json:"logLevel,omitempty"`
+	Audit    bool   `protobuf:"varint,3,opt,name=audit,proto3" json:"audit,omitempty"`
+	// ingressBandwidth and egressBandwidth are in bits per second, 0 when unlimited.
+	IngressBandwidth     int64    `protobuf:"varint,4,opt,name=ingressBandwidth,proto3" json:"ingressBandwidth,omitempty"`
+	EgressBandwidth      int64    `protobuf:"varint,5,opt,name=egressBandwidth,proto3" json:"egressBandwidth,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PolicyAttributes) Reset()         { *m = PolicyAttributes{} }
+func (m *PolicyAttributes) String() string { return proto.CompactTextString(m) }
+func (*PolicyAttributes) ProtoMessage()    {}
+func (*PolicyAttributes) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e86ff1316b3100d3, []int{3}
+}
+
+func (m *PolicyAttributes) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PolicyAttributes.Unmarshal(m, b)
+}
+func (m *PolicyAttributes) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PolicyAttributes.Marshal(b, m, deterministic)
+}
+func (m *PolicyAttributes) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PolicyAttributes.Merge(m, src)
+}
+func (m *PolicyAttributes) XXX_Size() int {
+	return xxx_messageInfo_PolicyAttributes.Size(m)
+}
+func (m *PolicyAttributes) XXX_DiscardUnknown() {
+	xxx_messageInfo_PolicyAttributes.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PolicyAttributes proto.InternalMessageInfo
+
+func (m *PolicyAttributes) GetQosClass() string {
+	if m != nil {
+		return m.QosClass
+	}
+	return ""
+}
+
+func (m *PolicyAttributes) GetLogLevel() string {
+	if m != nil {
+		return m.LogLevel
+	}
+	return ""
+}
+
+func (m *PolicyAttributes) GetAudit() bool {
+	if m != nil {
+		return m.Audit
+	}
+	return false
+}
+
+func (m *PolicyAttributes) GetIngressBandwidth() int64 {
+	if m != nil {
+		return m.IngressBandwidth
+	}
+	return 0
+}
+
+func (m *PolicyAttributes) GetEgressBandwidth() int64 {
+	if m != nil {
+		return m.EgressBandwidth
+	}
+	return 0
+}
+
+type L7Policy struct {
+	Default              *DefaultPolicy `protobuf:"bytes,1,opt,name=default,proto3" json:"default,omitempty"`
+	UrlFilter            *URLFilter     `protobuf:"bytes,2,opt,name=urlFilter,proto3" json:"urlFilter,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *L7Policy) Reset()         { *m = L7Policy{} }
+func (m *L7Policy) String() string { return proto.CompactTextString(m) }
+func (*L7Policy) ProtoMessage()    {}
+func (*L7Policy) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e86ff1316b3100d3, []int{4}
+}
+
+func (m *L7Policy) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_L7Policy.Unmarshal(m, b)
+}
+func (m *L7Policy) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_L7Policy.Marshal(b, m, deterministic)
+}
+func (m *L7Policy) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_L7Policy.Merge(m, src)
+}
+func (m *L7Policy) XXX_Size() int {
+	return xxx_messageInfo_L7Policy.Size(m)
+}
+func (m *L7Policy) XXX_DiscardUnknown() {
+	xxx_messageInfo_L7Policy.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_L7Policy proto.InternalMessageInfo
+
+func (m *L7Policy) GetDefault() *DefaultPolicy {
+	if m != nil {
+		return m.Default
+	}
+	return nil
+}
+
+func (m *L7Policy) GetUrlFilter() *URLFilter {
+	if m != nil {
+		return m.UrlFilter
+	}
+	return nil
+}
+
+// The actions are "allow", "deny" or "log" to let the traffic through and log it.
+type DefaultPolicy struct {
+	Action               string   `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DefaultPolicy) Reset()         { *m = DefaultPolicy{} }
+func (m *DefaultPolicy) String() string { return proto.CompactTextString(m) }
+func (*DefaultPolicy) ProtoMessage()    {}
+func (*DefaultPolicy) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e86ff1316b3100d3, []int{5}
+}
+
+func (m *DefaultPolicy) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DefaultPolicy.Unmarshal(m, b)
+}
+func (m *DefaultPolicy) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DefaultPolicy.Marshal(b, m, deterministic)
+}
+func (m *DefaultPolicy) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DefaultPolicy.Merge(m, src)
+}
+func (m *DefaultPolicy) XXX_Size() int {
+	return xxx_messageInfo_DefaultPolicy.Size(m)
+}
+func (m *DefaultPolicy) XXX_DiscardUnknown() {
+	xxx_messageInfo_DefaultPolicy.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DefaultPolicy proto.InternalMessageInfo
+
+func (m *DefaultPolicy) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+type URLFilter struct {
+	Urls                 []string       `protobuf:"bytes,1,rep,name=urls,proto3" json:"urls,omitempty"`
+	Action               string         `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	PodSelector          *LabelSelector `protobuf:"bytes,3,opt,name=podSelector,proto3" json:"podSelector,omitempty"`
+	Network              string         `protobuf:"bytes,4,opt,name=network,proto3" json:"network,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *URLFilter) Reset()         { *m = URLFilter{} }
+func (m *URLFilter) String() string { return proto.CompactTextString(m) }
+func (*URLFilter) ProtoMessage()    {}
+func (*URLFilter) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e86ff1316b3100d3, []int{6}
+}
+
+func (m *URLFilter) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_URLFilter.Unmarshal(m, b)
+}
+func (m *URLFilter) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_URLFilter.Marshal(b, m, deterministic)
+}
+func (m *URLFilter) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_URLFilter.Merge(m, src)
+}
+func (m *URLFilter) XXX_Size() int {
+	return xxx_messageInfo_URLFilter.Size(m)
+}
+func (m *URLFilter) XXX_DiscardUnknown() {
+	xxx_messageInfo_URLFilter.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_URLFilter proto.InternalMessageInfo
+
+func (m *URLFilter) GetUrls() []string {
+	if m != nil {
+		return m.Urls
+	}
+	return nil
+}
+
+func (m *URLFilter) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+func (m *URLFilter) GetPodSelector() *LabelSelector {
+	if m != nil {
+		return m.PodSelector
+	}
+	return nil
+}
+
+func (m *URLFilter) GetNetwork() string {
+	if m != nil {
+		return m.Network
+	}
+	return ""
+}
+
+// LabelSelector follows the Kubernetes label selector.
+type LabelSelector struct {
+	MatchLabels          map[string]string           `protobuf:"bytes,1,rep,name=matchLabels,proto3" json:"matchLabels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	MatchExpressions     []*LabelSelectorRequirement `protobuf:"bytes,2,rep,name=matchExpressions,proto3" json:"matchExpressions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                    `json:"-"`
+	XXX_unrecognized     []byte                      `json:"-"`
+	XXX_sizecache        int32                       `json:"-"`
+}
+
+func (m *LabelSelector) Reset()         { *m = LabelSelector{} }
+func (m *LabelSelector) String() string { return proto.CompactTextString(m) }
+func (*LabelSelector) ProtoMessage()    {}
+func (*LabelSelector) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e86ff1316b3100d3, []int{7}
+}
+
+func (m *LabelSelector) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LabelSelector.Unmarshal(m, b)
+}
+func (m *LabelSelector) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LabelSelector.Marshal(b, m, deterministic)
+}
+func (m *LabelSelector) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LabelSelector.Merge(m, src)
+}
+func (m *LabelSelector) XXX_Size() int {
+	return xxx_messageInfo_LabelSelector.Size(m)
+}
+func (m *LabelSelector) XXX_DiscardUnknown() {
+	xxx_messageInfo_LabelSelector.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LabelSelector proto.InternalMessageInfo
+
+func (m *LabelSelector) GetMatchLabels() map[string]string {
+	if m != nil {
+		return m.MatchLabels
+	}
+	return nil
+}
+
+func (m *LabelSelector) GetMatchExpressions() []*LabelSelectorRequirement {
+	if m != nil {
+		return m.MatchExpressions
+	}
+	return nil
+}
+
+type LabelSelectorRequirement struct {
+	Key                  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Operator             string   `protobuf:"bytes,2,opt,name=operator,proto3" json:"operator,omitempty"`
+	Values               []string `protobuf:"bytes,3,rep,name=values,proto3" json:"values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LabelSelectorRequirement) Reset()         { *m = LabelSelectorRequirement{} }
+func (m *LabelSelectorRequirement) String() string { return proto.CompactTextString(m) }
+func (*LabelSelectorRequirement) ProtoMessage()    {}
+func (*LabelSelectorRequirement) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e86ff1316b3100d3, []int{8}
+}
+
+func (m *LabelSelectorRequirement) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LabelSelectorRequirement.Unmarshal(m, b)
+}
+func (m *LabelSelectorRequirement) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LabelSelectorRequirement.Marshal(b, m, deterministic)
+}
+func (m *LabelSelectorRequirement) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LabelSelectorRequirement.Merge(m, src)
+}
+func (m *LabelSelectorRequirement) XXX_Size() int {
+	return xxx_messageInfo_LabelSelectorRequirement.Size(m)
+}
+func (m *LabelSelectorRequirement) XXX_DiscardUnknown() {
+	xxx_messageInfo_LabelSelectorRequirement.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LabelSelectorRequirement proto.InternalMessageInfo
+
+func (m *LabelSelectorRequirement) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *LabelSelectorRequirement) GetOperator() string {
+	if m != nil {
+		return m.Operator
+	}
+	return ""
+}
+
+func (m *LabelSelectorRequirement) GetValues() []string {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Policy)(nil), "nimbess.model.Policy")
+	proto.RegisterType((*ObjectMeta)(nil), "nimbess.model.ObjectMeta")
+	proto.RegisterMapType((map[string]string)(nil), "nimbess.model.ObjectMeta.LabelsEntry")
+	proto.RegisterType((*PolicySpec)(nil), "nimbess.model.PolicySpec")
+	proto.RegisterType((*PolicyAttributes)(nil), "nimbess.model.PolicyAttributes")
+	proto.RegisterType((*L7Policy)(nil), "nimbess.model.L7Policy")
+	proto.RegisterType((*DefaultPolicy)(nil), "nimbess.model.DefaultPolicy")
+	proto.RegisterType((*URLFilter)(nil), "nimbess.model.URLFilter")
+	proto.RegisterType((*LabelSelector)(nil), "nimbess.model.LabelSelector")
+	proto.RegisterMapType((map[string]string)(nil), "nimbess.model.LabelSelector.MatchLabelsEntry")
+	proto.RegisterType((*LabelSelectorRequirement)(nil), "nimbess.model.LabelSelectorRequirement")
+}
+
+func init() { proto.RegisterFile("pkg/model/node/policy.proto", fileDescriptor_e86ff1316b3100d3) }
+
+var fileDescriptor_e86ff1316b3100d3 = []byte{
+	// 677 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x55, 0xdd, 0x6e, 0x13, 0x3d,
+	0x10, 0xd5, 0xe6, 0xaf, 0xc9, 0x44, 0xd5, 0x17, 0x59, 0x9f, 0xc0, 0x84, 0x4a, 0x54, 0x2b, 0xa1,
+	0x46, 0x48, 0x4d, 0x50, 0x10, 0x2d, 0x3f, 0xa2, 0x12, 0x85, 0x72, 0x01, 0xa9, 0x8a, 0x5c, 0x71,
+	0xc3, 0x15, 0xce, 0xee, 0x34, 0x35, 0x75, 0xd6, 0x5b, 0xdb, 0xdb, 0x92, 0xbe, 0x05, 0x6f, 0xc1,
+	0x13, 0xf0, 0x40, 0xbc, 0x03, 0xf7, 0x68, 0xbd, 0x9b, 0xbf, 0x6d, 0x5a, 0x09, 0x71, 0x13, 0xf9,
+	0xd8, 0xe7, 0x9c, 0x99, 0xf1, 0x8c, 0xb3, 0x70, 0x3f, 0x3e, 0x1b, 0xf5, 0xc6, 0x2a, 0x44, 0xd9,
+	0x8b, 0x54, 0x88, 0xbd, 0x58, 0x49, 0x11, 0x4c, 0xba, 0xb1, 0x56, 0x56, 0x91, 0xf5, 0x48, 0x8c,
+	0x87, 0x68, 0x4c, 0xd7, 0x11, 0xfc, 0x08, 0x6a, 0x1f, 0xdd, 0x31, 0x79, 0x0a, 0xf5, 0x31, 0x5a,
+	0x1e, 0x72, 0xcb, 0xa9, 0xb7, 0xe9, 0x75, 0x9a, 0xfd, 0x7b, 0xdd, 0x25, 0x6e, 0xf7, 0x68, 0xf8,
+	0x15, 0x03, 0x7b, 0x88, 0x96, 0xb3, 0x19, 0x95, 0x6c, 0x43, 0xc5, 0xc4, 0x18, 0xd0, 0xd2, 0x4a,
+	0x49, 0xe6, 0x7d, 0x1c, 0x63, 0xc0, 0x1c, 0xcd, 0xff, 0xe5, 0x01, 0xcc, 0x7d, 0xc8, 0x06, 0x34,
+	0x22, 0x3e, 0x46, 0x13, 0xf3, 0x00, 0x5d, 0xd4, 0x06, 0x9b, 0x6f, 0x10, 0x02, 0x95, 0x14, 0x38,
+	0xef, 0x06, 0x73, 0x6b, 0xd2, 0x82, 0x72, 0x22, 0x42, 0x5a, 0x76, 0x5b, 0xe9, 0x92, 0xbc, 0x82,
+	0x9a, 0xe4, 0x43, 0x94, 0x86, 0x56, 0x36, 0xcb, 0x9d, 0x66, 0xff, 0xe1, 0x8d, 0x69, 0x77, 0x07,
+	0x8e, 0x77, 0x10, 0x59, 0x3d, 0x61, 0xb9, 0x88, 0xdc, 0x81, 0x9a, 0xd2, 0x62, 0x24, 0x22, 0x5a,
+	0x75, 0x9e, 0x39, 0x6a, 0x3f, 0x87, 0xe6, 0x02, 0x3d, 0x8d, 0x7b, 0x86, 0x93, 0x3c, 0xc7, 0x74,
+	0x49, 0xfe, 0x87, 0xea, 0x05, 0x97, 0xc9, 0x34, 0xbd, 0x0c, 0xbc, 0x28, 0x3d, 0xf3, 0xfc, 0x1f,
+	0x25, 0x80, 0x79, 0xe5, 0x64, 0x17, 0x40, 0xee, 0x3a, 0x2c, 0xd0, 0x50, 0xcf, 0x25, 0x79, 0xb7,
+	0x90, 0xe4, 0x20, 0x23, 0x4c, 0xd8, 0x02, 0x95, 0xec, 0x41, 0x33, 0x56, 0xe1, 0x31, 0x4a, 0x0c,
+	0xac, 0xd2, 0xf9, 0x15, 0x6f, 0x14, 0x95, 0x69, 0x92, 0x53, 0x0e, 0x5b, 0x14, 0x10, 0x0a, 0x6b,
+	0x11, 0xda, 0x4b, 0xa5, 0xcf, 0xf2, 0xfb, 0x9a, 0x42, 0xf2, 0x01, 0x5a, 0xd9, 0x54, 0xbc, 0xb6,
+	0x56, 0x8b, 0x61, 0x62, 0xd1, 0xb8, 0xf2, 0x9b, 0xfd, 0x07, 0x2b, 0x3b, 0x38, 0xa7, 0xb1, 0x6b,
+	0x42, 0xd2, 0x81, 0xff, 0x30, 0x3a, 0x51, 0x3a, 0xc0, 0x31, 0x46, 0xf6, 0x50, 0x85, 0x48, 0x6b,
+	0x2e, 0x5c, 0x71, 0xfb, 0x7d, 0xa5, 0x5e, 0x69, 0x55, 0x19, 0xf0, 0x99, 0xd6, 0xff, 0xe9, 0x41,
+	0xab, 0x18, 0x82, 0xb4, 0xa1, 0x7e, 0xae, 0xcc, 0x1b, 0xc9, 0x8d, 0xc9, 0x2f, 0x7c, 0x86, 0xd3,
+	0x33, 0xa9, 0x46, 0x03, 0xbc, 0x40, 0x99, 0x5f, 0xfc, 0x0c, 0xa7, 0x1d, 0xe1, 0x49, 0x28, 0xac,
+	0xab, 0xb6, 0xce, 0x32, 0x40, 0x1e, 0x41, 0x4b, 0x44, 0x23, 0x8d, 0xc6, 0xec, 0xf3, 0x28, 0xbc,
+	0x14, 0xa1, 0x3d, 0xa5, 0x95, 0x4d, 0xaf, 0x53, 0x66, 0xd7, 0xf6, 0x5d, 0x29, 0x05, 0x6a, 0xd5,
+	0x51, 0x8b, 0xdb, 0xfe, 0x15, 0xd4, 0xa7, 0x3d, 0x23, 0x3b, 0xb0, 0x16, 0xe2, 0x09, 0x4f, 0xa4,
+	0xa5, 0xde, 0xca, 0x1e, 0xbd, 0xcd, 0x4e, 0xf3, 0x16, 0x4f, 0xc9, 0x64, 0x07, 0x1a, 0x89, 0x96,
+	0xef, 0x84, 0xb4, 0x38, 0xed, 0x2e, 0x2d, 0x28, 0x3f, 0xb1, 0x41, 0x76, 0xce, 0xe6, 0x54, 0x7f,
+	0x0b, 0xd6, 0x97, 0x1c, 0xd3, 0x19, 0xe6, 0x81, 0x15, 0x2a, 0xca, 0xaf, 0x2b, 0x47, 0xfe, 0x77,
+	0x0f, 0x1a, 0x33, 0x87, 0xf4, 0x39, 0x25, 0x5a, 0x66, 0x13, 0xd8, 0x60, 0x6e, 0xbd, 0xa0, 0x2c,
+	0x2d, 0x2a, 0x8b, 0xa3, 0x57, 0xfe, 0x87, 0xd1, 0xab, 0x2c, 0x8d, 0x9e, 0xff, 0xdb, 0x83, 0xf5,
+	0x25, 0x21, 0x39, 0x82, 0xe6, 0x98, 0xdb, 0xe0, 0x74, 0x90, 0xbd, 0xe2, 0xec, 0x81, 0x6c, 0xdf,
+	0x16, 0xab, 0x7b, 0x38, 0xe7, 0x67, 0xaf, 0x79, 0xd1, 0x81, 0x1c, 0x43, 0xcb, 0xc1, 0x83, 0x6f,
+	0x71, 0xda, 0x34, 0xa1, 0x22, 0x43, 0x4b, 0xce, 0x75, 0xeb, 0xd6, 0x0a, 0xf0, 0x3c, 0x11, 0xda,
+	0x4d, 0x2b, 0xbb, 0x66, 0xd0, 0xde, 0x83, 0x56, 0x31, 0xea, 0x5f, 0xfd, 0x29, 0x7c, 0x01, 0x7a,
+	0x53, 0xb4, 0x15, 0x3e, 0x6d, 0xa8, 0xab, 0x18, 0x35, 0x9f, 0xbe, 0xfb, 0x06, 0x9b, 0xe1, 0xb4,
+	0x67, 0xce, 0xd6, 0xd0, 0xb2, 0xeb, 0x64, 0x8e, 0xf6, 0xfb, 0x9f, 0x1f, 0x8f, 0x84, 0x3d, 0x4d,
+	0x86, 0xdd, 0x40, 0x8d, 0x7b, 0x79, 0xa1, 0x3d, 0x63, 0xb9, 0x1e, 0xf1, 0x2b, 0xd4, 0xbd, 0xe5,
+	0xcf, 0xc2, 0xcb, 0xf4, 0x67, 0x58, 0x73, 0x5f, 0x85, 0x27, 0x7f, 0x06, 0x00, 0x82, 0xac, 0xbf,
+	0x3c, 0x34, 0x06, 0x00, 0x00,
+}
@@ -0,0 +1,195 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: pkg/model/node/service.proto
+
+package node
+
+import (
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// Service holds the virtual IPs and ports of a service, its backends are held by the Endpoint
+// of the same namespace and name.
+type Service struct {
+	Namespace string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name      string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Type      string `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	// clusterIP is empty for headless services.
+	ClusterIP            string         `protobuf:"bytes,4,opt,name=clusterIP,proto3" json:"clusterIP,omitempty"`
+	ExternalIPs          []string       `protobuf:"bytes,5,rep,name=externalIPs,proto3" json:"externalIPs,omitempty"`
+	Ports                []*ServicePort `protobuf:"bytes,6,rep,name=ports,proto3" json:"ports,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *Service) Reset()         { *m = Service{} }
+func (m *Service) String() string { return proto.CompactTextString(m) }
+func (*Service) ProtoMessage()    {}
+func (*Service) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0b89cac74acdb034, []int{0}
+}
+
+func (m *Service) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Service.Unmarshal(m, b)
+}
+func (m *Service) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Service.Marshal(b, m, deterministic)
+}
+func (m *Service) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Service.Merge(m, src)
+}
+func (m *Service) XXX_Size() int {
+	return xxx_messageInfo_Service.Size(m)
+}
+func (m *Service) XXX_DiscardUnknown() {
+	xxx_messageInfo_Service.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Service proto.InternalMessageInfo
+
+func (m *Service) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *Service) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Service) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *Service) GetClusterIP() string {
+	if m != nil {
+		return m.ClusterIP
+	}
+	return ""
+}
+
+func (m *Service) GetExternalIPs() []string {
+	if m != nil {
+		return m.ExternalIPs
+	}
+	return nil
+}
+
+func (m *Service) GetPorts() []*ServicePort {
+	if m != nil {
+		return m.Ports
+	}
+	return nil
+}
+
+type ServicePort struct {
+	Name     string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Port     int32  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+	Protocol string `protobuf:"bytes,3,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	// targetPort is the port number or the name of the port of the backends.
+	TargetPort           string   `protobuf:"bytes,4,opt,name=targetPort,proto3" json:"targetPort,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ServicePort) Reset()         { *m = ServicePort{} }
+func (m *ServicePort) String() string { return proto.CompactTextString(m) }
+func (*ServicePort) ProtoMessage()    {}
+func (*ServicePort) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0b89cac74acdb034, []int{1}
+}
+
+func (m *ServicePort) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServicePort.Unmarshal(m, b)
+}
+func (m *ServicePort) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServicePort.Marshal(b, m, deterministic)
+}
+func (m *ServicePort) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServicePort.Merge(m, src)
+}
+func (m *ServicePort) XXX_Size() int {
+	return xxx_messageInfo_ServicePort.Size(m)
+}
+func (m *ServicePort) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServicePort.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServicePort proto.InternalMessageInfo
+
+func (m *ServicePort) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ServicePort) GetPort() int32 {
+	if m != nil {
+		return m.Port
+	}
+	return 0
+}
+
+func (m *ServicePort) GetProtocol() string {
+	if m != nil {
+		return m.Protocol
+	}
+	return ""
+}
+
+func (m *ServicePort) GetTargetPort() string {
+	if m != nil {
+		return m.TargetPort
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*Service)(nil), "nimbess.model.Service")
+	proto.RegisterType((*ServicePort)(nil), "nimbess.model.ServicePort")
+}
+
+func init() { proto.RegisterFile("pkg/model/node/service.proto", fileDescriptor_0b89cac74acdb034) }
+
+var fileDescriptor_0b89cac74acdb034 = []byte{
+	// 259 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x91, 0x31, 0x4f, 0xc3, 0x30,
+	0x10, 0x85, 0x15, 0xd2, 0x14, 0x72, 0x11, 0x8b, 0x27, 0xab, 0xaa, 0x50, 0xd4, 0x29, 0x93, 0x5d,
+	0x95, 0x91, 0x8d, 0xad, 0x5b, 0x14, 0x36, 0x36, 0xc7, 0x3d, 0x85, 0x88, 0x24, 0x36, 0xb6, 0x8b,
+	0x80, 0x7f, 0xc7, 0x3f, 0x43, 0x76, 0xa2, 0x90, 0x2c, 0xd6, 0xbb, 0xcf, 0x4f, 0x4f, 0xef, 0x6c,
+	0xd8, 0xeb, 0xf7, 0x86, 0xf7, 0xea, 0x82, 0x1d, 0x1f, 0xd4, 0x05, 0xb9, 0x45, 0xf3, 0xd9, 0x4a,
+	0x64, 0xda, 0x28, 0xa7, 0xc8, 0xfd, 0xd0, 0xf6, 0x35, 0x5a, 0xcb, 0x82, 0xe3, 0xf0, 0x1b, 0xc1,
+	0xed, 0xcb, 0x68, 0x20, 0x7b, 0x48, 0x07, 0xd1, 0xa3, 0xd5, 0x42, 0x22, 0x8d, 0xf2, 0xa8, 0x48,
+	0xab, 0x7f, 0x40, 0x08, 0x6c, 0xfc, 0x40, 0x6f, 0xc2, 0x45, 0xd0, 0x9e, 0xb9, 0x6f, 0x8d, 0x34,
+	0x1e, 0x99, 0xd7, 0x3e, 0x45, 0x76, 0x57, 0xeb, 0xd0, 0x9c, 0x4b, 0xba, 0x19, 0x53, 0x66, 0x40,
+	0x72, 0xc8, 0xf0, 0xcb, 0xa1, 0x19, 0x44, 0x77, 0x2e, 0x2d, 0x4d, 0xf2, 0xb8, 0x48, 0xab, 0x25,
+	0x22, 0x47, 0x48, 0xb4, 0x32, 0xce, 0xd2, 0x6d, 0x1e, 0x17, 0xd9, 0x69, 0xc7, 0x56, 0x85, 0xd9,
+	0x54, 0xb6, 0x54, 0xc6, 0x55, 0xa3, 0xf1, 0xf0, 0x01, 0xd9, 0x82, 0xce, 0x45, 0xa3, 0x75, 0x51,
+	0xef, 0x0d, 0xe5, 0x93, 0x2a, 0x68, 0xb2, 0x83, 0xbb, 0xf0, 0x24, 0x52, 0x75, 0xd3, 0x02, 0xf3,
+	0x4c, 0x1e, 0x00, 0x9c, 0x30, 0x0d, 0x3a, 0x9f, 0x38, 0x6d, 0xb1, 0x20, 0xcf, 0xa7, 0xd7, 0x63,
+	0xd3, 0xba, 0xb7, 0x6b, 0xcd, 0xa4, 0xea, 0xf9, 0xd4, 0x90, 0x5b, 0xef, 0x10, 0x3f, 0x68, 0xf8,
+	0xfa, 0x0b, 0x9e, 0xfc, 0x51, 0x6f, 0x43, 0xfa, 0xe3, 0xdf, 0x00, 0x90, 0x41, 0x89, 0x88, 0xa0,
+	0x01, 0x00, 0x00,
+}
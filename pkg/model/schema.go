@@ -0,0 +1,108 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SchemaVersion is the version of the key layout written by this version of stargazer.
+//
+// Layout versions:
+//  1. unversioned layout, keys are stored under the fixed LegacyRoot path.
+//  2. keys are stored under the configurable Prefix and a SchemaVersionKey is written.
+//  3. UNP values are stored as a Policy instead of the UnifiedNetworkPolicy object.
+const SchemaVersion = 3
+
+// UnversionedSchema is the version assumed when no SchemaVersionKey is found.
+const UnversionedSchema = 1
+
+// SchemaVersionKey is the key of the marker holding the layout version of the keys under the prefix.
+// The value is the version number as a bare string.
+type SchemaVersionKey struct{}
+
+func (key SchemaVersionKey) defaultDeletePath() (string, error) {
+	return key.defaultPath()
+}
+
+func (key SchemaVersionKey) defaultPath() (string, error) {
+	return fmt.Sprintf("%s/schema-version", Prefix()), nil
+}
+
+func (key SchemaVersionKey) valueType() (reflect.Type, error) {
+	return rawStringType, nil
+}
+
+func (key SchemaVersionKey) String() string {
+	return "SchemaVersion()"
+}
+
+// Migration describes how the keys of one layout version are rewritten into the next version.
+type Migration struct {
+	// From is the layout version the keys are migrated from, they are migrated to From+1.
+	From int
+	// Prefixes returns the path prefixes holding the keys to be migrated.
+	Prefixes func() []string
+	// Rewrite returns the path of a key in the next layout version. Keys that don't need to
+	// be moved are returned unchanged.
+	Rewrite func(key string) string
+	// Convert returns the value of a key in the next layout version, nil if values are unchanged.
+	Convert func(key string, value []byte) ([]byte, error)
+}
+
+// migrations are indexed by the layout version they migrate from.
+var migrations = map[int]Migration{
+	1: {
+		From: 1,
+		Prefixes: func() []string {
+			return []string{LegacyRoot + "/" + unpDir + "/", LegacyRoot + "/" + nodeDir + "/"}
+		},
+		Rewrite: func(key string) string {
+			return Prefix() + strings.TrimPrefix(key, LegacyRoot)
+		},
+	},
+	2: {
+		From: 2,
+		Prefixes: func() []string {
+			return []string{UNPListOptions{}.defaultPathRoot()}
+		},
+		Rewrite: func(key string) string {
+			return key
+		},
+		Convert: func(key string, value []byte) ([]byte, error) {
+			k := UNPListOptions{}.KeyFromDefaultPath(key)
+			if k == nil {
+				return value, nil
+			}
+			// ParseValue reads both the UnifiedNetworkPolicy and the Policy layout.
+			policy, err := ParseValue(k, value)
+			if err != nil {
+				return nil, err
+			}
+			return SerializeValue(&KVPair{Key: k, Value: policy})
+		},
+	},
+}
+
+// MigrationFrom returns the migration of the keys from the given layout version to the next one.
+func MigrationFrom(version int) (Migration, error) {
+	m, ok := migrations[version]
+	if !ok {
+		return Migration{}, fmt.Errorf("no migration from key layout version %d", version)
+	}
+	return m, nil
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"github.com/nimbess/stargazer/pkg/errors"
+	"github.com/nimbess/stargazer/pkg/model/node"
+	"reflect"
+	"strings"
+)
+
+// serviceDir is the directory holding the services under the Nimbess prefix
+const serviceDir = "service"
+
+var (
+	typeService = reflect.TypeOf(Service{})
+)
+
+// Service is the value of a ServiceKey, generated from node/service.proto.
+type Service = node.Service
+
+// ServicePort is a port of a Service.
+type ServicePort = node.ServicePort
+
+// ServiceKey is the key of the virtual IPs and ports of a service. The backends of the service
+// are held by the EndpointKey of the same namespace and name.
+type ServiceKey struct {
+	Namespace string
+	Name      string
+}
+
+func (key ServiceKey) defaultDeletePath() (string, error) {
+	return key.defaultPath()
+}
+
+func (key ServiceKey) defaultPath() (string, error) {
+	if key.Namespace == "" {
+		return "", errors.ErrorInsufficientIdentifiers{Name: "namespace"}
+	}
+	if key.Name == "" {
+		return "", errors.ErrorInsufficientIdentifiers{Name: "name"}
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", Prefix(), serviceDir, key.Namespace, key.Name), nil
+}
+
+func (key ServiceKey) valueType() (reflect.Type, error) {
+	return typeService, nil
+}
+
+func (key ServiceKey) String() string {
+	return fmt.Sprintf("Service(namespace=%s, name=%s)", key.Namespace, key.Name)
+}
+
+// ServiceListOptions lists the services, optionally restricted to a namespace.
+type ServiceListOptions struct {
+	Namespace string
+}
+
+func (options ServiceListOptions) defaultPathRoot() string {
+	root := fmt.Sprintf("%s/%s/", Prefix(), serviceDir)
+	if options.Namespace == "" {
+		return root
+	}
+	return root + options.Namespace + "/"
+}
+
+func (options ServiceListOptions) KeyFromDefaultPath(path string) Key {
+	if !strings.HasPrefix(path, options.defaultPathRoot()) {
+		return nil
+	}
+	parts := strings.Split(strings.TrimPrefix(path, fmt.Sprintf("%s/%s/", Prefix(), serviceDir)), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil
+	}
+	return ServiceKey{Namespace: parts[0], Name: parts[1]}
+}
@@ -16,15 +16,104 @@ package model
 
 import (
 	"fmt"
-	"github.com/nimbess/stargazer/pkg/errors"
+	"github.com/golang/protobuf/proto"
 	"github.com/nimbess/stargazer/pkg/crd/api/unp/v1"
+	"github.com/nimbess/stargazer/pkg/errors"
+	"github.com/nimbess/stargazer/pkg/model/node"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"path"
 	"reflect"
+	"strings"
 )
 
+// unpDir is the directory holding the UNPs under the Nimbess prefix
+const unpDir = "unp"
+
+// OriginIngress marks the policies derived from Ingress objects, the origin of the policies
+// converted from UNPs is empty.
+const OriginIngress = "ingress"
+
 var (
-	typeUNP = reflect.TypeOf(v1.UnifiedNetworkPolicy{})
+	typeUNP = reflect.TypeOf(Policy{})
 )
 
+// Policy is the value of an UNPKey, generated from node/policy.proto.
+type Policy = node.Policy
+
+// NewPolicy converts an UnifiedNetworkPolicy to the Policy stored in the datastore.
+func NewPolicy(unp *v1.UnifiedNetworkPolicy) *Policy {
+	spec := &node.PolicySpec{
+		PodSelector:      newLabelSelector(unp.Spec.PodSelector),
+		Network:          unp.Spec.Network,
+		PolicyAttributes: newPolicyAttributes(unp.Spec.Attributes),
+		EnforcementMode:  unp.Spec.EnforcementMode,
+	}
+	if spec.EnforcementMode == "" {
+		spec.EnforcementMode = v1.EnforcementModeEnforce
+	}
+	for _, l7 := range unp.Spec.L7Policies {
+		spec.L7Policies = append(spec.L7Policies, &node.L7Policy{
+			Default: &node.DefaultPolicy{Action: l7.Default.Action},
+			UrlFilter: &node.URLFilter{
+				Urls:        l7.UrlFilter.Urls,
+				Action:      l7.UrlFilter.Action,
+				PodSelector: newLabelSelector(l7.UrlFilter.PodSelector),
+				Network:     l7.UrlFilter.Network,
+			},
+		})
+	}
+	return &Policy{
+		Metadata: &node.ObjectMeta{
+			Namespace: unp.Namespace,
+			Name:      unp.Name,
+			Uid:       string(unp.UID),
+			Labels:    unp.Labels,
+		},
+		Spec: spec,
+	}
+}
+
+// IngressPolicyName returns the name of the UNPKey of the policy derived from an Ingress for one of
+// its backend Services. UNP names have no '/', so that the policies of an Ingress and an UNP of the
+// same name don't collide.
+func IngressPolicyName(namespace, name, service string) string {
+	return path.Join(namespace, OriginIngress, name, service)
+}
+
+// newPolicyAttributes converts the attributes of an UNP, nil if none is set. Bandwidths are rounded
+// up to the bit per second.
+func newPolicyAttributes(a v1.PolicyAttributes) *node.PolicyAttributes {
+	attributes := &node.PolicyAttributes{
+		QosClass: a.QoSClass,
+		LogLevel: a.LogLevel,
+		Audit:    a.Audit,
+	}
+	if a.Bandwidth != nil {
+		if a.Bandwidth.Ingress != nil {
+			attributes.IngressBandwidth = a.Bandwidth.Ingress.Value()
+		}
+		if a.Bandwidth.Egress != nil {
+			attributes.EgressBandwidth = a.Bandwidth.Egress.Value()
+		}
+	}
+	if proto.Size(attributes) == 0 {
+		return nil
+	}
+	return attributes
+}
+
+func newLabelSelector(selector metav1.LabelSelector) *node.LabelSelector {
+	s := &node.LabelSelector{MatchLabels: selector.MatchLabels}
+	for _, req := range selector.MatchExpressions {
+		s.MatchExpressions = append(s.MatchExpressions, &node.LabelSelectorRequirement{
+			Key:      req.Key,
+			Operator: string(req.Operator),
+			Values:   req.Values,
+		})
+	}
+	return s
+}
+
 type UNPKey struct {
 	Name string
 }
@@ -37,7 +126,7 @@ func (key UNPKey) defaultPath() (string, error) {
 	if key.Name == "" {
 		return "", errors.ErrorInsufficientIdentifiers{Name: "name"}
 	}
-	return fmt.Sprintf("/nimbess/unp/%s", key.Name), nil
+	return fmt.Sprintf("%s/%s/%s", Prefix(), unpDir, key.Name), nil
 }
 
 func (key UNPKey) valueType() (reflect.Type, error) {
@@ -51,3 +140,27 @@ func (key UNPKey) String() string {
 func (key UNPKey) KeyToDefaultDeletePath() (string, error) {
 	return key.defaultPath()
 }
+
+// UNPListOptions lists the UNPs, optionally restricted to a namespace.
+type UNPListOptions struct {
+	Namespace string
+}
+
+func (options UNPListOptions) defaultPathRoot() string {
+	root := fmt.Sprintf("%s/%s/", Prefix(), unpDir)
+	if options.Namespace == "" {
+		return root
+	}
+	return root + options.Namespace + "/"
+}
+
+func (options UNPListOptions) KeyFromDefaultPath(path string) Key {
+	if !strings.HasPrefix(path, options.defaultPathRoot()) {
+		return nil
+	}
+	name := strings.TrimPrefix(path, fmt.Sprintf("%s/%s/", Prefix(), unpDir))
+	if name == "" {
+		return nil
+	}
+	return UNPKey{Name: name}
+}
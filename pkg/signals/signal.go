@@ -41,3 +41,20 @@ func SetupSignalHandler() (stopCh <-chan struct{}) {
 
 	return stop
 }
+
+// SetupReloadHandler registers for SIGHUP. The returned channel is notified on each signal,
+// signals received while a notification is pending are coalesced.
+func SetupReloadHandler() <-chan struct{} {
+	reload := make(chan struct{}, 1)
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, reloadSignals...)
+	go func() {
+		for range c {
+			select {
+			case reload <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return reload
+}
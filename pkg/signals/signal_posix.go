@@ -24,3 +24,5 @@ import (
 )
 
 var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+var reloadSignals = []os.Signal{syscall.SIGHUP}
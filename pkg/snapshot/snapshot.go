@@ -0,0 +1,224 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package snapshot exports the Nimbess state of a datastore into an archive and restores it.
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/model"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ArchiveVersion is the version of the archive format written by Export.
+const ArchiveVersion = 1
+
+// lists are the kinds of keys held by a snapshot.
+var lists = []model.ListInterface{
+	model.UNPListOptions{},
+	model.NodeListOptions{},
+	model.EndpointListOptions{},
+	model.ServiceListOptions{},
+	model.NetworkListOptions{},
+	model.NetworkAttachmentListOptions{},
+	model.PodInterfaceListOptions{},
+}
+
+// Archive is a snapshot of the keys under a prefix.
+type Archive struct {
+	// Version is the version of the archive format.
+	Version int `json:"version"`
+	// SchemaVersion is the key layout version of the exported keys.
+	SchemaVersion int `json:"schemaVersion"`
+	// Root and Tenant are the prefix the keys were exported from.
+	Root    string    `json:"root"`
+	Tenant  string    `json:"tenant,omitempty"`
+	Created time.Time `json:"created"`
+	Entries []Entry   `json:"entries"`
+}
+
+// Entry is a key of the archive. The path is relative to the prefix and the value is the JSON
+// representation of the decoded value, whatever the encoding used in the datastore.
+type Entry struct {
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Export returns a snapshot of the keys under the current prefix, see model.SetPrefix.
+func Export(ctx context.Context, store etcdv3.Client, root string, tenant string) (*Archive, error) {
+	a := &Archive{
+		Version:       ArchiveVersion,
+		SchemaVersion: model.SchemaVersion,
+		Root:          root,
+		Tenant:        tenant,
+		Created:       time.Now().UTC(),
+		Entries:       []Entry{},
+	}
+	for _, l := range lists {
+		list, err := store.List(ctx, l)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range list.KVPairs {
+			path, err := model.KeyToDefaultPath(d.Key)
+			if err != nil {
+				return nil, err
+			}
+			value, err := json.Marshal(d.Value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode %s: %v", d.Key, err)
+			}
+			a.Entries = append(a.Entries, Entry{Path: strings.TrimPrefix(path, model.Prefix()+"/"), Value: value})
+		}
+	}
+	sort.Slice(a.Entries, func(i, j int) bool { return a.Entries[i].Path < a.Entries[j].Path })
+	return a, nil
+}
+
+// Write writes the archive as JSON.
+func Write(w io.Writer, a *Archive) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(a)
+}
+
+// Read reads an archive written by Write.
+func Read(r io.Reader) (*Archive, error) {
+	a := &Archive{}
+	if err := json.NewDecoder(r).Decode(a); err != nil {
+		return nil, fmt.Errorf("failed to read archive: %v", err)
+	}
+	if a.Version != ArchiveVersion {
+		return nil, fmt.Errorf("unsupported archive version %d", a.Version)
+	}
+	// The paths are unchanged since layout version 2, and ParseValue reads the values of older layouts.
+	if a.SchemaVersion < 2 || a.SchemaVersion > model.SchemaVersion {
+		return nil, fmt.Errorf("archive holds keys of layout version %d, expected %d", a.SchemaVersion, model.SchemaVersion)
+	}
+	return a, nil
+}
+
+// Actions of the changes restoring an archive.
+const (
+	// ActionCreate is a key of the archive missing in the datastore.
+	ActionCreate = "create"
+	// ActionUpdate is a key whose value in the datastore differs from the archive.
+	ActionUpdate = "update"
+	// ActionNone is a key with the same value in the archive and in the datastore.
+	ActionNone = "none"
+	// ActionExtra is a key only found in the datastore, it is kept when the archive is restored.
+	ActionExtra = "extra"
+)
+
+// Change is the difference of a key between the archive and the datastore.
+type Change struct {
+	Action string `json:"action"`
+	Key    string `json:"key"`
+	Path   string `json:"path"`
+
+	kv *model.KVPair
+}
+
+// Plan compares the archive with the keys under the current prefix, see model.SetPrefix. Restoring
+// the archive under a different prefix than it was exported from rewrites the paths of the keys.
+func Plan(ctx context.Context, store etcdv3.Client, a *Archive) ([]*Change, error) {
+	var changes []*Change
+	archived := make(map[string]bool, len(a.Entries))
+	for _, e := range a.Entries {
+		path := model.Prefix() + "/" + e.Path
+		archived[path] = true
+		k := keyFromPath(path)
+		if k == nil {
+			return nil, fmt.Errorf("unknown key %s in archive", e.Path)
+		}
+		value, err := model.ParseValue(k, e.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", e.Path, err)
+		}
+
+		c := &Change{Key: k.String(), Path: path, kv: &model.KVPair{Key: k, Value: value}}
+		current, err := store.Get(ctx, k)
+		switch {
+		case etcdv3.IsNotFound(err):
+			c.Action = ActionCreate
+		case err != nil:
+			return nil, err
+		case equal(current.Value, value):
+			c.Action = ActionNone
+		default:
+			c.Action = ActionUpdate
+			c.kv.Revision = current.Revision
+		}
+		changes = append(changes, c)
+	}
+
+	for _, l := range lists {
+		list, err := store.List(ctx, l)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range list.KVPairs {
+			path, _ := model.KeyToDefaultPath(d.Key)
+			if !archived[path] {
+				changes = append(changes, &Change{Action: ActionExtra, Key: d.Key.String(), Path: path})
+			}
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// Apply writes the keys created or updated by the changes.
+func Apply(ctx context.Context, store etcdv3.Client, changes []*Change) error {
+	for _, c := range changes {
+		var err error
+		switch c.Action {
+		case ActionCreate:
+			err = store.Create(ctx, c.kv)
+		case ActionUpdate:
+			err = store.Update(ctx, c.kv)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to %s %s: %v", c.Action, c.Key, err)
+		}
+	}
+	return nil
+}
+
+// keyFromPath returns the key of a path under the current prefix, nil if the path is not a known key.
+func keyFromPath(path string) model.Key {
+	for _, l := range lists {
+		if k := l.KeyFromDefaultPath(path); k != nil {
+			return k
+		}
+	}
+	return nil
+}
+
+func equal(a, b interface{}) bool {
+	if pa, ok := a.(proto.Message); ok {
+		if pb, ok := b.(proto.Message); ok {
+			return proto.Equal(pa, pb)
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2019 Red Hat and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot_test
+
+import (
+	"bytes"
+	"context"
+	"github.com/nimbess/stargazer/pkg/etcdv3"
+	"github.com/nimbess/stargazer/pkg/model"
+	"github.com/nimbess/stargazer/pkg/model/node"
+	"github.com/nimbess/stargazer/pkg/snapshot"
+	"testing"
+)
+
+func TestSnapshot_ExportImport(t *testing.T) {
+	defer model.SetPrefix(model.LegacyRoot, "")
+	ctx := context.Background()
+	src := etcdv3.NewMemoryClient()
+	policy := &model.Policy{Metadata: &node.ObjectMeta{Namespace: "default", Name: "p1"},
+		Spec: &node.PolicySpec{Network: "net1"}}
+	for _, d := range []*model.KVPair{
+		{Key: model.UNPKey{Name: "default/p1"}, Value: policy},
+		{Key: model.NodeKey{Hostname: "node1"}, Value: &model.Node{Name: "node1"}},
+	} {
+		if err := src.Create(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, err := snapshot.Export(ctx, src, model.LegacyRoot, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := snapshot.Write(&buf, a); err != nil {
+		t.Fatal(err)
+	}
+	a, err = snapshot.Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Restore under another prefix, next to an existing key.
+	if err := model.SetPrefix("/restored", "tenant1"); err != nil {
+		t.Fatal(err)
+	}
+	dst := etcdv3.NewMemoryClient()
+	extra := &model.KVPair{Key: model.NodeKey{Hostname: "node2"}, Value: &model.Node{Name: "node2"}}
+	if err := dst.Create(ctx, extra); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := snapshot.Plan(ctx, dst, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"/restored/tenant1/host/node1":     snapshot.ActionCreate,
+		"/restored/tenant1/host/node2":     snapshot.ActionExtra,
+		"/restored/tenant1/unp/default/p1": snapshot.ActionCreate,
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %d", len(expected), len(changes))
+	}
+	for _, c := range changes {
+		if expected[c.Path] != c.Action {
+			t.Errorf("%s: expected action %q, got %q", c.Path, expected[c.Path], c.Action)
+		}
+	}
+	if err := snapshot.Apply(ctx, dst, changes); err != nil {
+		t.Fatal(err)
+	}
+	got, err := dst.Get(ctx, model.UNPKey{Name: "default/p1"})
+	if err != nil || got.Value.(*model.Policy).Spec.Network != "net1" {
+		t.Errorf("Unexpected restored policy: %+v, %v", got, err)
+	}
+
+	// Restoring again changes nothing.
+	changes, err = snapshot.Plan(ctx, dst, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range changes {
+		if c.Action != snapshot.ActionNone && c.Action != snapshot.ActionExtra {
+			t.Errorf("%s: unexpected action %q", c.Path, c.Action)
+		}
+	}
+}